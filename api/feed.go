@@ -0,0 +1,38 @@
+package api
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/autoapev1/indexer/feed"
+	"github.com/autoapev1/indexer/webhook"
+)
+
+// initFeed polls every chain for the rows written by the syncer, for the
+// registered webhooks and websocket subscriptions.
+func (s *Server) initFeed() error {
+	if s.config.API.FeedInterval <= 0 {
+		slog.Warn("Feed interval is not set, webhooks and subscriptions will be disabled")
+		return nil
+	}
+
+	interval := time.Duration(s.config.API.FeedInterval) * time.Second
+	ctx := s.ctx
+
+	s.feed = feed.NewHub()
+
+	hooks := make(map[int64]webhook.Store)
+	for _, store := range s.stores.GetAll() {
+		hooks[store.GetChainID()] = store
+		go feed.Poll(ctx, s.feed, store, interval)
+	}
+
+	dispatcher := webhook.NewDispatcher(hooks).
+		WithMaxAttempts(s.config.API.WebhookMaxAttempts).
+		WithTimeout(time.Duration(s.config.API.WebhookTimeout) * time.Second).
+		WithAllowPrivate(s.config.API.WebhookAllowPrivate)
+
+	go dispatcher.Run(ctx, s.feed.Subscribe(64))
+
+	return nil
+}
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/autoapev1/indexer/auth"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// masterComplexity is how many times the basic key limit a master key may
+// spend on a single query.
+const masterComplexity = 10
+
+var errGraphQLDisabled = errors.New("graphql is disabled")
+
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func (s *Server) initGraphQL() error {
+	if s.config.API.GraphQLMaxComplexity <= 0 {
+		slog.Warn("GraphQL max complexity is not set, /graphql will be disabled")
+		return nil
+	}
+
+	schema, err := s.newGraphQLSchema()
+	if err != nil {
+		return err
+	}
+
+	s.graphql = &schema
+	return nil
+}
+
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) error {
+	level, ok := r.Context().Value(auth.AuthKey).(auth.AuthLevel)
+	if !ok || !auth.IsValidAuthLevel(level) {
+		return writeError(w, http.StatusInternalServerError, errInternalServer)
+	}
+
+	// same access as the idx_ methods
+	if !hasAccess(MethodIdx, level) {
+		return writeError(w, http.StatusUnauthorized, auth.ErrUnauthorized)
+	}
+
+	// a query costs 1 against the rate limit. Queries are not checked field by
+	// field, so keys scoped to methods or chains can not use /graphql
+	if c, ok := callerFromRequest(r); ok {
+		if !s.charge(c, 1) {
+			return writeError(w, http.StatusTooManyRequests, errTooMany)
+		}
+		if err := c.checkOrigin(); err != nil {
+			return writeError(w, http.StatusForbidden, err)
+		}
+		if c.scopes.Restricts() {
+			return writeError(w, http.StatusForbidden, errMethodNotAllowed)
+		}
+
+		// nor can keys of tiers limited to some methods, queries are not
+		// counted against quotas but are refused once one is used up
+		if t := s.tierOf(c); t != nil {
+			if len(t.Methods) > 0 {
+				return writeError(w, http.StatusForbidden, errTierMethod)
+			}
+			if _, err := s.quotas.check(c.key, t, 1, false); err != nil {
+				return writeError(w, http.StatusTooManyRequests, err)
+			}
+		}
+	}
+
+	if s.graphql == nil {
+		return writeError(w, http.StatusServiceUnavailable, errGraphQLDisabled)
+	}
+
+	req := &graphQLRequest{}
+	if r.Method == http.MethodGet {
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if v := r.URL.Query().Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				return writeError(w, http.StatusBadRequest, errUnmarshalRequest)
+			}
+		}
+	} else {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return writeError(w, http.StatusBadRequest, errReadingBody)
+		}
+
+		if err := json.Unmarshal(body, req); err != nil {
+			return writeError(w, http.StatusBadRequest, errUnmarshalRequest)
+		}
+	}
+
+	limit := s.config.API.GraphQLMaxComplexity
+	if level >= auth.AuthLevelMaster {
+		limit *= masterComplexity
+	}
+
+	// parse errors are reported by graphql.Do
+	if doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(req.Query)})}); err == nil {
+		cost := queryComplexity(doc, req.OperationName, req.Variables)
+		if cost > limit {
+			return writeJSON(w, http.StatusOK, &graphql.Result{
+				Errors: []gqlerrors.FormattedError{
+					gqlerrors.NewFormattedError(fmt.Sprintf("query complexity %d exceeds the limit of %d", cost, limit)),
+				},
+			})
+		}
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         *s.graphql,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        newGQLContext(r.Context()),
+	})
+
+	return writeJSON(w, http.StatusOK, result)
+}
+
+// queryComplexity counts every selected field once, the fields below a
+// connection are counted once per node it may return.
+func queryComplexity(doc *ast.Document, operation string, vars map[string]interface{}) int {
+	fragments := map[string]*ast.FragmentDefinition{}
+	var op *ast.OperationDefinition
+
+	for _, def := range doc.Definitions {
+		switch d := def.(type) {
+		case *ast.FragmentDefinition:
+			fragments[d.Name.Value] = d
+		case *ast.OperationDefinition:
+			if op == nil && (operation == "" || (d.Name != nil && d.Name.Value == operation)) {
+				op = d
+			}
+		}
+	}
+
+	if op == nil {
+		return 0
+	}
+
+	c := &complexity{fragments: fragments, vars: vars, visiting: map[string]bool{}}
+	return c.selectionSet(op.SelectionSet)
+}
+
+type complexity struct {
+	fragments map[string]*ast.FragmentDefinition
+	vars      map[string]interface{}
+	visiting  map[string]bool // fragment cycles are rejected later by validation
+}
+
+func (c *complexity) selectionSet(set *ast.SelectionSet) int {
+	if set == nil {
+		return 0
+	}
+
+	total := 0
+	for _, sel := range set.Selections {
+		switch s := sel.(type) {
+		case *ast.Field:
+			total += 1 + c.multiplier(s)*c.selectionSet(s.SelectionSet)
+
+		case *ast.InlineFragment:
+			total += c.selectionSet(s.SelectionSet)
+
+		case *ast.FragmentSpread:
+			name := s.Name.Value
+			f, ok := c.fragments[name]
+			if !ok || c.visiting[name] {
+				continue
+			}
+
+			c.visiting[name] = true
+			total += c.selectionSet(f.SelectionSet)
+			c.visiting[name] = false
+		}
+	}
+
+	return total
+}
+
+// multiplier is the first argument of a connection, 1 for other fields.
+func (c *complexity) multiplier(f *ast.Field) int {
+	switch f.Name.Value {
+	case "tokens", "pairs":
+	default:
+		return 1
+	}
+
+	for _, arg := range f.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(v.Value); err == nil && n > 0 {
+				return n
+			}
+		case *ast.Variable:
+			if n, ok := c.vars[v.Name.Value].(float64); ok && n > 0 {
+				return int(n)
+			}
+		}
+	}
+
+	return gqlDefaultFirst
+}
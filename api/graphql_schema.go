@@ -0,0 +1,791 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/autoapev1/indexer/storage"
+	"github.com/autoapev1/indexer/types"
+	"github.com/graphql-go/graphql"
+)
+
+const (
+	gqlDefaultFirst = 20
+	gqlMaxFirst     = 100
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// gqlNode is a token, pair or block timestamp with the store it was read
+// from, so relationships are resolved on the same chain.
+type gqlNode struct {
+	store storage.Store
+	value interface{}
+}
+
+// gqlConnection is a page of nodes, cursors hold the sort value and row id of
+// a node so the next page is read by keyset.
+type gqlConnection struct {
+	nodes     []*gqlNode
+	sortBy    string
+	sortOrder string
+	hasNext   bool
+}
+
+// gqlCursor is the decoded form of a cursor, it is only valid for the sort it
+// was read with.
+type gqlCursor struct {
+	Sort  string      `json:"s"`
+	Value interface{} `json:"v"`
+	ID    string      `json:"id"`
+}
+
+type gqlEdge struct {
+	cursor string
+	node   *gqlNode
+}
+
+// gqlLoader caches the tokens and blocks read by relationship fields during a
+// single request, a page of pairs often shares the same quote token.
+type gqlLoader struct {
+	lock   sync.Mutex
+	tokens map[string]*types.Token
+	blocks map[string]*types.BlockTimestamp
+}
+
+type gqlLoaderKey struct{}
+
+func newGQLContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, gqlLoaderKey{}, &gqlLoader{
+		tokens: make(map[string]*types.Token),
+		blocks: make(map[string]*types.BlockTimestamp),
+	})
+}
+
+func loaderFrom(ctx context.Context) *gqlLoader {
+	l, _ := ctx.Value(gqlLoaderKey{}).(*gqlLoader)
+	if l == nil {
+		l = &gqlLoader{
+			tokens: make(map[string]*types.Token),
+			blocks: make(map[string]*types.BlockTimestamp),
+		}
+	}
+	return l
+}
+
+func (l *gqlLoader) token(store storage.Store, address string) (*types.Token, error) {
+	key := strconv.FormatInt(store.GetChainID(), 10) + ":" + address
+
+	l.lock.Lock()
+	t, ok := l.tokens[key]
+	l.lock.Unlock()
+	if ok {
+		return t, nil
+	}
+
+	tokens, err := store.GetTokensByAddress([]string{address})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) > 0 {
+		t = tokens[0]
+	}
+
+	l.lock.Lock()
+	l.tokens[key] = t
+	l.lock.Unlock()
+
+	return t, nil
+}
+
+func (l *gqlLoader) block(store storage.Store, number int64) (*types.BlockTimestamp, error) {
+	key := strconv.FormatInt(store.GetChainID(), 10) + ":" + strconv.FormatInt(number, 10)
+
+	l.lock.Lock()
+	b, ok := l.blocks[key]
+	l.lock.Unlock()
+	if ok {
+		return b, nil
+	}
+
+	blocks, err := store.GetBlockTimestamps(number, number)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(blocks) > 0 {
+		b = blocks[0]
+	}
+
+	l.lock.Lock()
+	l.blocks[key] = b
+	l.lock.Unlock()
+
+	return b, nil
+}
+
+// newGraphQLSchema derives the object types from types.Token, types.Pair and
+// types.BlockTimestamp and the filter inputs from their filters, then adds
+// the relationships between them.
+func (s *Server) newGraphQLSchema() (graphql.Schema, error) {
+	pageInfo := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*gqlConnection).hasNext, nil
+				},
+			},
+			"endCursor": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					c := p.Source.(*gqlConnection)
+					if len(c.nodes) == 0 {
+						return nil, nil
+					}
+					return c.cursor(c.nodes[len(c.nodes)-1])
+				},
+			},
+		},
+	})
+
+	tokenFilter := inputObject("TokenFilter", reflect.TypeOf(types.TokenFilter{}))
+	pairFilter := inputObject("PairFilter", reflect.TypeOf(types.PairFilter{}))
+
+	var token, pair, block *graphql.Object
+	var tokenConn, pairConn *graphql.Object
+
+	token = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Token",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			fields := objectFields(reflect.TypeOf(types.Token{}))
+
+			fields["pairs"] = &graphql.Field{
+				Type:        graphql.NewNonNull(pairConn),
+				Description: "Pairs with the token as token0 or token1",
+				Args:        connectionArgs(pairFilter),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					n := p.Source.(*gqlNode)
+					filter := &types.PairFilter{}
+					if err := decodeArg(p.Args["filter"], filter); err != nil {
+						return nil, err
+					}
+					address := n.value.(*types.Token).Address
+					filter.TokenAddress = &address
+					return findPairs(n.store, filter, p.Args)
+				},
+			}
+
+			fields["createdBlock"] = &graphql.Field{
+				Type:        block,
+				Description: "The block the token was created in",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					n := p.Source.(*gqlNode)
+					return resolveBlock(p.Context, n.store, n.value.(*types.Token).CreatedAt)
+				},
+			}
+
+			return fields
+		}),
+	})
+
+	pair = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Pair",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			fields := objectFields(reflect.TypeOf(types.Pair{}))
+
+			fields["token0"] = &graphql.Field{
+				Type: token,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					n := p.Source.(*gqlNode)
+					return resolveToken(p.Context, n.store, n.value.(*types.Pair).Token0Address)
+				},
+			}
+
+			fields["token1"] = &graphql.Field{
+				Type: token,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					n := p.Source.(*gqlNode)
+					return resolveToken(p.Context, n.store, n.value.(*types.Pair).Token1Address)
+				},
+			}
+
+			fields["createdBlock"] = &graphql.Field{
+				Type:        block,
+				Description: "The block the pair was created in",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					n := p.Source.(*gqlNode)
+					return resolveBlock(p.Context, n.store, n.value.(*types.Pair).CreatedAt)
+				},
+			}
+
+			return fields
+		}),
+	})
+
+	block = graphql.NewObject(graphql.ObjectConfig{
+		Name: "BlockTimestamp",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			fields := objectFields(reflect.TypeOf(types.BlockTimestamp{}))
+
+			fields["tokens"] = &graphql.Field{
+				Type:        graphql.NewNonNull(tokenConn),
+				Description: "Tokens created in the block",
+				Args:        connectionArgs(nil),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					n := p.Source.(*gqlNode)
+					number := n.value.(*types.BlockTimestamp).Block
+					return findTokens(n.store, &types.TokenFilter{FromBlock: &number, ToBlock: &number}, p.Args)
+				},
+			}
+
+			fields["pairs"] = &graphql.Field{
+				Type:        graphql.NewNonNull(pairConn),
+				Description: "Pairs created in the block",
+				Args:        connectionArgs(nil),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					n := p.Source.(*gqlNode)
+					number := n.value.(*types.BlockTimestamp).Block
+					return findPairs(n.store, &types.PairFilter{FromBlock: &number, ToBlock: &number}, p.Args)
+				},
+			}
+
+			return fields
+		}),
+	})
+
+	tokenConn = connectionObject("TokenConnection", token, pageInfo)
+	pairConn = connectionObject("PairConnection", pair, pageInfo)
+
+	chainArg := &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)}
+
+	withChain := func(args graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+		args["chain_id"] = chainArg
+		return args
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"token": &graphql.Field{
+				Type: token,
+				Args: withChain(graphql.FieldConfigArgument{
+					"address": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					store, err := s.gqlStore(p.Context, p.Args)
+					if err != nil {
+						return nil, err
+					}
+					return resolveToken(p.Context, store, strings.ToLower(p.Args["address"].(string)))
+				},
+			},
+			"tokens": &graphql.Field{
+				Type: graphql.NewNonNull(tokenConn),
+				Args: withChain(connectionArgs(tokenFilter)),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					store, err := s.gqlStore(p.Context, p.Args)
+					if err != nil {
+						return nil, err
+					}
+					filter := &types.TokenFilter{}
+					if err := decodeArg(p.Args["filter"], filter); err != nil {
+						return nil, err
+					}
+					return findTokens(store, filter, p.Args)
+				},
+			},
+			"pair": &graphql.Field{
+				Type: pair,
+				Args: withChain(graphql.FieldConfigArgument{
+					"address": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					store, err := s.gqlStore(p.Context, p.Args)
+					if err != nil {
+						return nil, err
+					}
+					pairs, err := store.GetPairsByAddress([]string{strings.ToLower(p.Args["address"].(string))})
+					if err != nil {
+						return nil, errInternalServer
+					}
+					if len(pairs) == 0 {
+						return nil, nil
+					}
+					return &gqlNode{store: store, value: pairs[0]}, nil
+				},
+			},
+			"pairs": &graphql.Field{
+				Type: graphql.NewNonNull(pairConn),
+				Args: withChain(connectionArgs(pairFilter)),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					store, err := s.gqlStore(p.Context, p.Args)
+					if err != nil {
+						return nil, err
+					}
+					filter := &types.PairFilter{}
+					if err := decodeArg(p.Args["filter"], filter); err != nil {
+						return nil, err
+					}
+					return findPairs(store, filter, p.Args)
+				},
+			},
+			"block": &graphql.Field{
+				Type: block,
+				Args: withChain(graphql.FieldConfigArgument{
+					"number": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					store, err := s.gqlStore(p.Context, p.Args)
+					if err != nil {
+						return nil, err
+					}
+					return resolveBlock(p.Context, store, int64(p.Args["number"].(int)))
+				},
+			},
+			"blockAtTimestamp": &graphql.Field{
+				Type: block,
+				Args: withChain(graphql.FieldConfigArgument{
+					"timestamp": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				}),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					chainID := int64(p.Args["chain_id"].(int))
+					timestamp := int64(p.Args["timestamp"].(int))
+
+					req := &types.GetBlockAtTimestampRequest{ChainID: &chainID, Timestamp: &timestamp}
+					if err := req.Validate(); err != nil {
+						return nil, err
+					}
+
+					store, err := s.gqlStore(p.Context, p.Args)
+					if err != nil {
+						return nil, err
+					}
+
+					b, err := store.GetBlockAtTimestamp(timestamp)
+					if err != nil {
+						return nil, errInternalServer
+					}
+					return &gqlNode{store: store, value: b}, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+func (s *Server) gqlStore(ctx context.Context, args map[string]interface{}) (storage.Store, error) {
+	chainID := int64(args["chain_id"].(int))
+
+	req := &types.GetHeightsRequest{ChainID: &chainID}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	store := s.stores.GetStore(chainID)
+	if store == nil {
+		return nil, errors.New("invalid chain_id")
+	}
+
+	return store.WithContext(ctx), nil
+}
+
+func resolveToken(ctx context.Context, store storage.Store, address string) (interface{}, error) {
+	t, err := loaderFrom(ctx).token(store, address)
+	if err != nil {
+		return nil, errInternalServer
+	}
+
+	if t == nil {
+		return nil, nil
+	}
+
+	return &gqlNode{store: store, value: t}, nil
+}
+
+func resolveBlock(ctx context.Context, store storage.Store, number int64) (interface{}, error) {
+	b, err := loaderFrom(ctx).block(store, number)
+	if err != nil {
+		return nil, errInternalServer
+	}
+
+	if b == nil {
+		return nil, nil
+	}
+
+	return &gqlNode{store: store, value: b}, nil
+}
+
+// findTokens reads a page of tokens with the validation of idx_findTokens.
+func findTokens(store storage.Store, filter *types.TokenFilter, args map[string]interface{}) (*gqlConnection, error) {
+	first, after, err := pageArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	chainID := store.GetChainID()
+	req := &types.FindTokensRequest{
+		ChainID: &chainID,
+		Filter:  filter,
+		Options: &types.TokenOptions{
+			Limit:     first + 1,
+			SortBy:    types.TokenSortByCreatedAt,
+			SortOrder: types.SortASC,
+		},
+	}
+
+	if v, ok := args["sort_by"].(string); ok {
+		req.Options.SortBy = types.TokenSortBy(v)
+	}
+
+	if v, ok := args["sort_order"].(string); ok {
+		req.Options.SortOrder = types.SortOrder(v)
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	filter.Lower()
+
+	conn := &gqlConnection{sortBy: string(req.Options.SortBy), sortOrder: strings.ToLower(string(req.Options.SortOrder))}
+	if after != nil {
+		if after.Sort != conn.sort() {
+			return nil, errInvalidCursor
+		}
+		req.Options.After = &types.Keyset{Value: after.Value, ID: after.ID}
+	}
+
+	tokens, err := store.FindTokens(req)
+	if err != nil {
+		return nil, errInternalServer
+	}
+
+	for _, t := range tokens {
+		conn.nodes = append(conn.nodes, &gqlNode{store: store, value: t})
+	}
+
+	if int64(len(conn.nodes)) > first {
+		conn.nodes = conn.nodes[:first]
+		conn.hasNext = true
+	}
+
+	return conn, nil
+}
+
+// findPairs reads a page of pairs with the validation of idx_findPairs.
+func findPairs(store storage.Store, filter *types.PairFilter, args map[string]interface{}) (*gqlConnection, error) {
+	first, after, err := pageArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	chainID := store.GetChainID()
+	req := &types.FindPairsRequest{
+		ChainID: &chainID,
+		Filter:  filter,
+		Options: &types.PairOptions{
+			Limit:     first + 1,
+			SortBy:    types.PairSortByCreatedAt,
+			SortOrder: types.SortASC,
+		},
+	}
+
+	if v, ok := args["sort_by"].(string); ok {
+		req.Options.SortBy = types.PairSortBy(v)
+	}
+
+	if v, ok := args["sort_order"].(string); ok {
+		req.Options.SortOrder = types.SortOrder(v)
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	filter.Lower()
+
+	conn := &gqlConnection{sortBy: string(req.Options.SortBy), sortOrder: strings.ToLower(string(req.Options.SortOrder))}
+	if after != nil {
+		if after.Sort != conn.sort() {
+			return nil, errInvalidCursor
+		}
+		req.Options.After = &types.Keyset{Value: after.Value, ID: after.ID}
+	}
+
+	pairs, err := store.FindPairs(req)
+	if err != nil {
+		return nil, errInternalServer
+	}
+
+	for _, p := range pairs {
+		conn.nodes = append(conn.nodes, &gqlNode{store: store, value: p})
+	}
+
+	if int64(len(conn.nodes)) > first {
+		conn.nodes = conn.nodes[:first]
+		conn.hasNext = true
+	}
+
+	return conn, nil
+}
+
+// pageArgs reads first and after, the next page starts after the row of the
+// cursor.
+func pageArgs(args map[string]interface{}) (int64, *gqlCursor, error) {
+	first := int64(gqlDefaultFirst)
+	if v, ok := args["first"].(int); ok {
+		first = int64(v)
+	}
+
+	if first < 1 || first > gqlMaxFirst {
+		return 0, nil, errors.New("first must be between 1 and " + strconv.Itoa(gqlMaxFirst))
+	}
+
+	v, ok := args["after"].(string)
+	if !ok || v == "" {
+		return first, nil, nil
+	}
+
+	after, err := decodeCursor(v)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return first, after, nil
+}
+
+// cursor encodes the sort value and row id of n, the address of a token or
+// the hash of a pair.
+func (c *gqlConnection) cursor(n *gqlNode) (string, error) {
+	var id string
+	switch v := n.value.(type) {
+	case *types.Token:
+		id = v.Address
+	case *types.Pair:
+		id = v.Hash
+	}
+
+	b, err := json.Marshal(n.value)
+	if err != nil {
+		return "", errInternalServer
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return "", errInternalServer
+	}
+
+	b, err = json.Marshal(gqlCursor{Sort: c.sort(), Value: fields[c.sortBy], ID: id})
+	if err != nil {
+		return "", errInternalServer
+	}
+
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+func (c *gqlConnection) sort() string {
+	return c.sortBy + " " + c.sortOrder
+}
+
+func decodeCursor(cursor string) (*gqlCursor, error) {
+	b, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	// numbers stay exact, created_at and fees are compared as integers
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	var c gqlCursor
+	if err := d.Decode(&c); err != nil || c.ID == "" {
+		return nil, errInvalidCursor
+	}
+
+	switch v := c.Value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			c.Value = i
+		} else if f, err := v.Float64(); err == nil {
+			c.Value = f
+		} else {
+			return nil, errInvalidCursor
+		}
+	case string, bool:
+	default:
+		return nil, errInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// decodeArg converts an input object argument to its request type through
+// its json tags.
+func decodeArg(arg interface{}, v interface{}) error {
+	if arg == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(arg)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+func connectionArgs(filter *graphql.InputObject) graphql.FieldConfigArgument {
+	args := graphql.FieldConfigArgument{
+		"first":      &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: gqlDefaultFirst},
+		"after":      &graphql.ArgumentConfig{Type: graphql.String},
+		"sort_by":    &graphql.ArgumentConfig{Type: graphql.String},
+		"sort_order": &graphql.ArgumentConfig{Type: graphql.String},
+	}
+
+	if filter != nil {
+		args["filter"] = &graphql.ArgumentConfig{Type: filter}
+	}
+
+	return args
+}
+
+func connectionObject(name string, node *graphql.Object, pageInfo *graphql.Object) *graphql.Object {
+	edge := graphql.NewObject(graphql.ObjectConfig{
+		Name: strings.TrimSuffix(name, "Connection") + "Edge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*gqlEdge).cursor, nil
+				},
+			},
+			"node": &graphql.Field{
+				Type: graphql.NewNonNull(node),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*gqlEdge).node, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: name,
+		Fields: graphql.Fields{
+			"edges": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edge))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					c := p.Source.(*gqlConnection)
+					edges := make([]*gqlEdge, 0, len(c.nodes))
+					for _, n := range c.nodes {
+						cursor, err := c.cursor(n)
+						if err != nil {
+							return nil, err
+						}
+						edges = append(edges, &gqlEdge{cursor: cursor, node: n})
+					}
+					return edges, nil
+				},
+			},
+			"nodes": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(node))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*gqlConnection).nodes, nil
+				},
+			},
+			"pageInfo": &graphql.Field{
+				Type: graphql.NewNonNull(pageInfo),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+		},
+	})
+}
+
+// objectFields returns a field for every json field of t, resolved from the
+// value of a gqlNode.
+func objectFields(t reflect.Type) graphql.Fields {
+	fields := graphql.Fields{}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := jsonName(f)
+		if name == "" {
+			continue
+		}
+
+		index := i
+		fields[name] = &graphql.Field{
+			Type: graphql.NewNonNull(scalarOf(f.Type)),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				v := reflect.ValueOf(p.Source.(*gqlNode).value).Elem()
+				return v.Field(index).Interface(), nil
+			},
+		}
+	}
+
+	return fields
+}
+
+// inputObject returns an input with a nullable field for every json field of t.
+func inputObject(name string, t reflect.Type) *graphql.InputObject {
+	fields := graphql.InputObjectConfigFieldMap{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := jsonName(t.Field(i))
+		if field == "" {
+			continue
+		}
+
+		fields[field] = &graphql.InputObjectFieldConfig{Type: scalarOf(t.Field(i).Type)}
+	}
+
+	return graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:   name,
+		Fields: fields,
+	})
+}
+
+func jsonName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+
+	if name == "" {
+		return f.Name
+	}
+
+	return name
+}
+
+func scalarOf(t reflect.Type) *graphql.Scalar {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return graphql.Boolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return graphql.Int
+	case reflect.Float32, reflect.Float64:
+		return graphql.Float
+	default:
+		return graphql.String
+	}
+}
@@ -0,0 +1,777 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/autoapev1/indexer/auth"
+	"github.com/autoapev1/indexer/feed"
+	indexerv1 "github.com/autoapev1/indexer/proto/indexer/v1"
+	"github.com/autoapev1/indexer/types"
+	"github.com/autoapev1/indexer/utils"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// grpcStreamPage is the page size used to stream large result sets.
+const grpcStreamPage = 1000
+
+// streamPage is the stream page size of c, at most the max limit of its tier.
+func (s *Server) streamPage(c *caller) int64 {
+	if t := s.tierOf(c); t != nil && t.MaxLimit > 0 && t.MaxLimit < grpcStreamPage {
+		return t.MaxLimit
+	}
+	return grpcStreamPage
+}
+
+// initGRPC starts the grpc server on its own port. The rpcs are served by the
+// json-rpc handlers, so they share the validation and auth levels.
+func (s *Server) initGRPC() error {
+	if s.config.API.GRPCPort <= 0 {
+		slog.Warn("gRPC port is not set, the gRPC server will be disabled")
+		return nil
+	}
+
+	addr := utils.ToAddress(s.config.API.Host, s.config.API.GRPCPort)
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(s.grpcUnaryAuth),
+		grpc.StreamInterceptor(s.grpcStreamAuth),
+	)
+	indexerv1.RegisterIndexerServer(srv, &grpcServer{s: s})
+	reflection.Register(srv)
+
+	s.grpc = srv
+
+	fmt.Printf("gRPC Server Listening on: \t%s\n", addr)
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			slog.Error("gRPC server stopped", "err", err)
+		}
+	}()
+
+	return nil
+}
+
+// grpcAuthenticate authenticates the Authentication metadata with the auth
+// provider, calls are charged to the rate limit by the json-rpc handlers.
+func (s *Server) grpcAuthenticate(ctx context.Context) (context.Context, error) {
+	if s.auth == nil {
+		return nil, status.Error(codes.Unavailable, errInternalServer.Error())
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+	if err != nil {
+		return nil, status.Error(codes.Internal, errInternalServer.Error())
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+		if v := md.Get("authentication"); len(v) > 0 {
+			r.Header.Set("Authentication", v[0])
+		}
+		if v := md.Get("origin"); len(v) > 0 {
+			r.Header.Set("Origin", v[0])
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+	}
+
+	level, info, err := s.auth.Authenticate(r)
+	if err != nil {
+		observeAuthFailure(err)
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	ctx = context.WithValue(ctx, auth.AuthKey, level)
+	ctx = context.WithValue(ctx, auth.APIKey, auth.KeyFromRequest(r))
+	ctx = context.WithValue(ctx, auth.KeyInfoKey, info)
+
+	return ctx, nil
+}
+
+func (s *Server) grpcUnaryAuth(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.grpcAuthenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (s *Server) grpcStreamAuth(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.grpcAuthenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+}
+
+// authStream carries the authenticated context to stream handlers.
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (a *authStream) Context() context.Context {
+	return a.ctx
+}
+
+type grpcServer struct {
+	indexerv1.UnimplementedIndexerServer
+	s *Server
+}
+
+// call serves req with the json-rpc method and fills resp with the result,
+// or with the result as its field when the result is not an object.
+// Going through json keeps one implementation of each method.
+func (g *grpcServer) call(ctx context.Context, method string, req proto.Message, resp proto.Message, field string) error {
+	return g.callParams(ctx, method, messageToJSON(req.ProtoReflect()), resp, field)
+}
+
+// callParams is call with the request in its json form.
+func (g *grpcServer) callParams(ctx context.Context, method string, req map[string]interface{}, resp proto.Message, field string) error {
+	params, err := json.Marshal(req)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, errUnmarshalParams.Error())
+	}
+
+	c := grpcCaller(ctx)
+	out, err := splitResponse(g.s.handleJrpcRequest(&JRPCRequest{
+		ID:      "grpc",
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+		ctx:     ctx,
+	}, c))
+
+	// headers can only be sent once, later pages of a stream skip them
+	if h := g.s.quotaHeaders(c); h != nil {
+		_ = grpc.SetHeader(ctx, metadata.New(h))
+	}
+
+	if err != nil {
+		return status.Error(codes.Internal, errInternalServer.Error())
+	}
+
+	if out.Error != nil {
+		return status.Error(grpcCode(out.Error), out.Error.Message)
+	}
+
+	var result interface{}
+	if len(out.Result) > 0 {
+		d := json.NewDecoder(bytes.NewReader(out.Result))
+		d.UseNumber()
+		if err := d.Decode(&result); err != nil {
+			return status.Error(codes.Internal, errInternalServer.Error())
+		}
+	}
+
+	if field != "" {
+		result = map[string]interface{}{field: result}
+	}
+
+	if err := fillMessage(resp.ProtoReflect(), result); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return nil
+}
+
+func (g *grpcServer) GetBlockNumber(ctx context.Context, req *indexerv1.GetBlockNumberRequest) (*indexerv1.GetBlockNumberResponse, error) {
+	resp := &indexerv1.GetBlockNumberResponse{}
+	return resp, g.call(ctx, "idx_getBlockNumber", req, resp, "block_numbers")
+}
+
+func (g *grpcServer) GetChains(ctx context.Context, req *indexerv1.GetChainsRequest) (*indexerv1.GetChainsResponse, error) {
+	resp := &indexerv1.GetChainsResponse{}
+	return resp, g.call(ctx, "idx_getChains", req, resp, "chains")
+}
+
+func (g *grpcServer) GetHeights(ctx context.Context, req *indexerv1.GetHeightsRequest) (*indexerv1.Heights, error) {
+	resp := &indexerv1.Heights{}
+	return resp, g.call(ctx, "idx_getHeights", req, resp, "")
+}
+
+func (g *grpcServer) GetBlockTimestamps(ctx context.Context, req *indexerv1.GetBlockTimestampsRequest) (*indexerv1.GetBlockTimestampsResponse, error) {
+	resp := &indexerv1.GetBlockTimestampsResponse{}
+	return resp, g.call(ctx, "idx_getBlockTimestamps", req, resp, "block_timestamps")
+}
+
+func (g *grpcServer) GetBlockAtTimestamp(ctx context.Context, req *indexerv1.GetBlockAtTimestampRequest) (*indexerv1.BlockTimestamp, error) {
+	resp := &indexerv1.BlockTimestamp{}
+	return resp, g.call(ctx, "idx_getBlockAtTimestamp", req, resp, "")
+}
+
+func (g *grpcServer) GetBlocks(ctx context.Context, req *indexerv1.GetBlocksRequest) (*indexerv1.GetBlocksResponse, error) {
+	resp := &indexerv1.GetBlocksResponse{}
+	return resp, g.call(ctx, "idx_getBlocks", req, resp, "blocks")
+}
+
+func (g *grpcServer) FindTokens(ctx context.Context, req *indexerv1.FindTokensRequest) (*indexerv1.FindTokensResponse, error) {
+	resp := &indexerv1.FindTokensResponse{}
+	return resp, g.call(ctx, "idx_findTokens", req, resp, "tokens")
+}
+
+func (g *grpcServer) StreamTokens(req *indexerv1.FindTokensRequest, stream indexerv1.Indexer_StreamTokensServer) error {
+	page := proto.Clone(req).(*indexerv1.FindTokensRequest)
+	if page.Options == nil {
+		page.Options = &indexerv1.TokenOptions{}
+	}
+	if page.Options.SortBy == "" {
+		page.Options.SortBy = string(types.TokenSortByCreatedAt)
+	}
+	if page.Options.SortOrder == "" {
+		page.Options.SortOrder = string(types.SortASC)
+	}
+
+	params := messageToJSON(page.ProtoReflect())
+	options := messageToJSON(page.Options.ProtoReflect())
+	params["options"] = options
+
+	total := page.Options.Limit
+	for sent := int64(0); total <= 0 || sent < total; {
+		limit := g.s.streamPage(grpcCaller(stream.Context()))
+		if total > 0 && total-sent < limit {
+			limit = total - sent
+		}
+		options["limit"] = limit
+
+		resp := &indexerv1.FindTokensResponse{}
+		if err := g.callParams(stream.Context(), "idx_findTokens", params, resp, "tokens"); err != nil {
+			return err
+		}
+
+		for _, t := range resp.Tokens {
+			if err := stream.Send(t); err != nil {
+				return err
+			}
+		}
+
+		n := int64(len(resp.Tokens))
+		if n < limit {
+			return nil
+		}
+
+		// later pages start after the last token rather than at an offset
+		last := resp.Tokens[n-1]
+		delete(options, "offset")
+		options["after"] = streamKeyset(last.ProtoReflect(), page.Options.SortBy, last.Address)
+		sent += n
+	}
+
+	return nil
+}
+
+func (g *grpcServer) GetTokenCount(ctx context.Context, req *indexerv1.GetTokenCountRequest) (*indexerv1.CountResponse, error) {
+	resp := &indexerv1.CountResponse{}
+	return resp, g.call(ctx, "idx_getTokenCount", req, resp, "count")
+}
+
+func (g *grpcServer) FindPairs(ctx context.Context, req *indexerv1.FindPairsRequest) (*indexerv1.FindPairsResponse, error) {
+	resp := &indexerv1.FindPairsResponse{}
+	return resp, g.call(ctx, "idx_findPairs", req, resp, "pairs")
+}
+
+func (g *grpcServer) StreamPairs(req *indexerv1.FindPairsRequest, stream indexerv1.Indexer_StreamPairsServer) error {
+	page := proto.Clone(req).(*indexerv1.FindPairsRequest)
+	if page.Options == nil {
+		page.Options = &indexerv1.PairOptions{}
+	}
+	if page.Options.SortBy == "" {
+		page.Options.SortBy = string(types.PairSortByCreatedAt)
+	}
+	if page.Options.SortOrder == "" {
+		page.Options.SortOrder = string(types.SortASC)
+	}
+
+	params := messageToJSON(page.ProtoReflect())
+	options := messageToJSON(page.Options.ProtoReflect())
+	params["options"] = options
+
+	total := page.Options.Limit
+	for sent := int64(0); total <= 0 || sent < total; {
+		limit := g.s.streamPage(grpcCaller(stream.Context()))
+		if total > 0 && total-sent < limit {
+			limit = total - sent
+		}
+		options["limit"] = limit
+
+		resp := &indexerv1.FindPairsResponse{}
+		if err := g.callParams(stream.Context(), "idx_findPairs", params, resp, "pairs"); err != nil {
+			return err
+		}
+
+		for _, p := range resp.Pairs {
+			if err := stream.Send(p); err != nil {
+				return err
+			}
+		}
+
+		n := int64(len(resp.Pairs))
+		if n < limit {
+			return nil
+		}
+
+		last := resp.Pairs[n-1]
+		delete(options, "offset")
+		options["after"] = streamKeyset(last.ProtoReflect(), page.Options.SortBy, last.Hash)
+		sent += n
+	}
+
+	return nil
+}
+
+func (g *grpcServer) GetPairCount(ctx context.Context, req *indexerv1.GetPairCountRequest) (*indexerv1.CountResponse, error) {
+	resp := &indexerv1.CountResponse{}
+	return resp, g.call(ctx, "idx_getPairCount", req, resp, "count")
+}
+
+func (g *grpcServer) GetPairReserves(ctx context.Context, req *indexerv1.GetPairReservesRequest) (*indexerv1.GetPairReservesResponse, error) {
+	resp := &indexerv1.GetPairReservesResponse{}
+	return resp, g.call(ctx, "idx_getPairReserves", req, resp, "reserves")
+}
+
+func (g *grpcServer) GetTokenMarkets(ctx context.Context, req *indexerv1.GetTokenMarketsRequest) (*indexerv1.GetTokenMarketsResponse, error) {
+	resp := &indexerv1.GetTokenMarketsResponse{}
+	return resp, g.call(ctx, "idx_getTokenMarkets", req, resp, "groups")
+}
+
+// SubscribePairs streams the new pairs of the feed, like a newPairs
+// subscription over the websocket.
+func (g *grpcServer) SubscribePairs(req *indexerv1.SubscribePairsRequest, stream indexerv1.Indexer_SubscribePairsServer) error {
+	ctx := stream.Context()
+	c := grpcCaller(ctx)
+
+	if resp := g.s.checkRateLimit(&JRPCRequest{Method: "idx_subscribe"}, c); resp != nil {
+		return status.Error(grpcCode(resp.Error), resp.Error.Message)
+	}
+
+	if resp := checkAccess(&JRPCRequest{Method: "idx_subscribe"}, c.level); resp != nil {
+		return status.Error(grpcCode(resp.Error), resp.Error.Message)
+	}
+
+	params := messageToJSON(req.ProtoReflect())
+	params["channel"] = types.ChannelNewPairs
+
+	b, err := json.Marshal(params)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, errUnmarshalParams.Error())
+	}
+
+	if err := c.checkScopes("idx_subscribe", b); err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	if resp := g.s.checkTier(&JRPCRequest{Method: "idx_subscribe", Params: b}, c); resp != nil {
+		return status.Error(grpcCode(resp.Error), resp.Error.Message)
+	}
+
+	sub := &types.SubscribeRequest{}
+	if err := json.Unmarshal(b, sub); err != nil {
+		return status.Error(codes.InvalidArgument, errUnmarshalParams.Error())
+	}
+
+	if err := sub.Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if g.s.stores.GetStore(*sub.ChainID) == nil {
+		return status.Error(codes.InvalidArgument, "invalid chain_id")
+	}
+
+	if g.s.feed == nil {
+		return status.Error(codes.Unavailable, errFeedDisabled.Error())
+	}
+
+	events := g.s.feed.Subscribe(64)
+	defer events.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case batch, ok := <-events.C:
+			if !ok {
+				return nil
+			}
+
+			for _, e := range batch {
+				if e.ChainID != *sub.ChainID || e.Kind != feed.KindPair || !sub.PairFilter.Match(e.Pair) {
+					continue
+				}
+
+				p := &indexerv1.Pair{}
+				if err := toMessage(e.Pair, p); err != nil {
+					return status.Error(codes.Internal, err.Error())
+				}
+
+				if err := stream.Send(p); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+func (g *grpcServer) FindRoutes(ctx context.Context, req *indexerv1.FindRoutesRequest) (*indexerv1.FindRoutesResponse, error) {
+	resp := &indexerv1.FindRoutesResponse{}
+	return resp, g.call(ctx, "idx_findRoutes", req, resp, "routes")
+}
+
+func (g *grpcServer) GetLogs(ctx context.Context, req *indexerv1.GetLogsRequest) (*indexerv1.GetLogsResponse, error) {
+	resp := &indexerv1.GetLogsResponse{}
+	return resp, g.call(ctx, "idx_getLogs", req, resp, "logs")
+}
+
+func (g *grpcServer) GetWalletBalanceHistory(ctx context.Context, req *indexerv1.GetWalletHistoryRequest) (*indexerv1.GetWalletBalanceHistoryResponse, error) {
+	resp := &indexerv1.GetWalletBalanceHistoryResponse{}
+	return resp, g.call(ctx, "idx_getWalletBalanceHistory", req, resp, "balances")
+}
+
+func (g *grpcServer) GetWalletTransfers(ctx context.Context, req *indexerv1.GetWalletHistoryRequest) (*indexerv1.GetWalletTransfersResponse, error) {
+	resp := &indexerv1.GetWalletTransfersResponse{}
+	return resp, g.call(ctx, "idx_getWalletTransfers", req, resp, "transfers")
+}
+
+func (g *grpcServer) CreateWebhook(ctx context.Context, req *indexerv1.CreateWebhookRequest) (*indexerv1.Webhook, error) {
+	resp := &indexerv1.Webhook{}
+	return resp, g.call(ctx, "idx_createWebhook", req, resp, "")
+}
+
+func (g *grpcServer) DeleteWebhook(ctx context.Context, req *indexerv1.DeleteWebhookRequest) (*indexerv1.DeleteWebhookResponse, error) {
+	resp := &indexerv1.DeleteWebhookResponse{}
+	return resp, g.call(ctx, "idx_deleteWebhook", req, resp, "deleted")
+}
+
+func (g *grpcServer) ListWebhooks(ctx context.Context, req *indexerv1.ListWebhooksRequest) (*indexerv1.ListWebhooksResponse, error) {
+	resp := &indexerv1.ListWebhooksResponse{}
+	return resp, g.call(ctx, "idx_listWebhooks", req, resp, "webhooks")
+}
+
+func (g *grpcServer) GetWebhookDeliveries(ctx context.Context, req *indexerv1.GetWebhookLogRequest) (*indexerv1.GetWebhookDeliveriesResponse, error) {
+	resp := &indexerv1.GetWebhookDeliveriesResponse{}
+	return resp, g.call(ctx, "idx_getWebhookDeliveries", req, resp, "deliveries")
+}
+
+func (g *grpcServer) GetWebhookDeadLetters(ctx context.Context, req *indexerv1.GetWebhookLogRequest) (*indexerv1.GetWebhookDeadLettersResponse, error) {
+	resp := &indexerv1.GetWebhookDeadLettersResponse{}
+	return resp, g.call(ctx, "idx_getWebhookDeadLetters", req, resp, "dead_letters")
+}
+
+func (g *grpcServer) WatchWallets(ctx context.Context, req *indexerv1.WatchWalletsRequest) (*indexerv1.CountResponse, error) {
+	resp := &indexerv1.CountResponse{}
+	return resp, g.call(ctx, "admin_watchWallets", req, resp, "count")
+}
+
+func (g *grpcServer) UnwatchWallets(ctx context.Context, req *indexerv1.UnwatchWalletsRequest) (*indexerv1.CountResponse, error) {
+	resp := &indexerv1.CountResponse{}
+	return resp, g.call(ctx, "admin_unwatchWallets", req, resp, "count")
+}
+
+func (g *grpcServer) GetWatchedWallets(ctx context.Context, req *indexerv1.GetWatchedWalletsRequest) (*indexerv1.GetWatchedWalletsResponse, error) {
+	resp := &indexerv1.GetWatchedWalletsResponse{}
+	return resp, g.call(ctx, "admin_getWatchedWallets", req, resp, "wallets")
+}
+
+// grpcCaller returns the caller authenticated by the interceptors.
+func grpcCaller(ctx context.Context) *caller {
+	c := &caller{}
+	c.level, _ = ctx.Value(auth.AuthKey).(auth.AuthLevel)
+	c.key, _ = ctx.Value(auth.APIKey).(string)
+
+	if info, _ := ctx.Value(auth.KeyInfoKey).(*auth.KeyInfo); info != nil {
+		c.scopes = info.Scopes
+		c.tier = info.Tier
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("origin"); len(v) > 0 {
+			c.origin = v[0]
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		c.ip = remoteIP(p.Addr.String())
+	}
+
+	return c
+}
+
+// streamKeyset is the after option of the page following the row m, its value
+// in the sort column and its row id.
+func streamKeyset(m protoreflect.Message, sortBy string, id string) map[string]interface{} {
+	var value interface{}
+	if fd := m.Descriptor().Fields().ByName(protoreflect.Name(sortBy)); fd != nil {
+		value = m.Get(fd).Interface()
+	}
+
+	// decimals and pool_type are uint32 in the messages
+	if v, ok := value.(uint32); ok {
+		value = int64(v)
+	}
+
+	return map[string]interface{}{"value": value, "id": id}
+}
+
+// grpcCode maps a json-rpc error onto a grpc status code.
+func grpcCode(err *JRPCError) codes.Code {
+	switch err.Code {
+	case -32602:
+		if err.Message == errInternalServer.Error() {
+			return codes.Internal
+		}
+		return codes.InvalidArgument
+	case -32601:
+		return codes.Unimplemented
+	case -32800:
+		return codes.PermissionDenied
+	case -32900:
+		return codes.ResourceExhausted
+	case -32701, -32702:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+// messageToJSON returns the set fields of m by their proto names, which are
+// the json names of the request types.
+func messageToJSON(m protoreflect.Message) map[string]interface{} {
+	out := map[string]interface{}{}
+
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := string(fd.Name())
+
+		switch {
+		case fd.IsList():
+			list := v.List()
+			values := make([]interface{}, 0, list.Len())
+			for i := 0; i < list.Len(); i++ {
+				values = append(values, valueToJSON(fd, list.Get(i)))
+			}
+			out[name] = values
+
+		case fd.IsMap():
+			values := map[string]interface{}{}
+			v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				values[k.String()] = valueToJSON(fd.MapValue(), v)
+				return true
+			})
+			out[name] = values
+
+		default:
+			out[name] = valueToJSON(fd, v)
+		}
+
+		return true
+	})
+
+	return out
+}
+
+func valueToJSON(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	if fd.Kind() != protoreflect.MessageKind {
+		return v.Interface()
+	}
+
+	m := v.Message()
+
+	// topics are positional lists of alternatives, null matches any topic
+	if m.Descriptor().FullName() == "indexer.v1.LogTopic" {
+		values := messageToJSON(m)["values"]
+		if values == nil {
+			return nil
+		}
+		return values
+	}
+
+	return messageToJSON(m)
+}
+
+// toMessage fills m with the json fields of v.
+func toMessage(v interface{}, m proto.Message) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var decoded interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&decoded); err != nil {
+		return err
+	}
+
+	return fillMessage(m.ProtoReflect(), decoded)
+}
+
+// fillMessage sets the fields of m from a decoded json object, keys are
+// matched to field names ignoring case.
+func fillMessage(m protoreflect.Message, v interface{}) error {
+	if v == nil {
+		return nil
+	}
+
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s: expected an object", m.Descriptor().Name())
+	}
+
+	fields := m.Descriptor().Fields()
+	byName := make(map[string]protoreflect.FieldDescriptor, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		byName[strings.ToLower(string(fields.Get(i).Name()))] = fields.Get(i)
+	}
+
+	for k, x := range obj {
+		fd, ok := byName[strings.ToLower(k)]
+		if !ok || x == nil {
+			continue
+		}
+
+		switch {
+		case fd.IsList():
+			items, ok := x.([]interface{})
+			if !ok {
+				return fmt.Errorf("%s: expected a list", fd.Name())
+			}
+
+			list := m.Mutable(fd).List()
+			for _, item := range items {
+				if fd.Kind() == protoreflect.MessageKind {
+					el := list.NewElement()
+					if err := fillMessage(el.Message(), item); err != nil {
+						return err
+					}
+					list.Append(el)
+					continue
+				}
+
+				val, err := scalarValue(fd, item)
+				if err != nil {
+					return err
+				}
+				list.Append(val)
+			}
+
+		case fd.IsMap():
+			items, ok := x.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s: expected an object", fd.Name())
+			}
+
+			mp := m.Mutable(fd).Map()
+			for key, item := range items {
+				mk, err := scalarValue(fd.MapKey(), json.Number(key))
+				if err != nil {
+					return err
+				}
+
+				val, err := scalarValue(fd.MapValue(), item)
+				if err != nil {
+					return err
+				}
+				mp.Set(mk.MapKey(), val)
+			}
+
+		case fd.Kind() == protoreflect.MessageKind:
+			if err := fillMessage(m.Mutable(fd).Message(), x); err != nil {
+				return err
+			}
+
+		default:
+			val, err := scalarValue(fd, x)
+			if err != nil {
+				return err
+			}
+			m.Set(fd, val)
+		}
+	}
+
+	return nil
+}
+
+var errUnexpectedValue = errors.New("unexpected value")
+
+func scalarValue(fd protoreflect.FieldDescriptor, x interface{}) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		if s, ok := x.(string); ok {
+			return protoreflect.ValueOfString(s), nil
+		}
+		// raw json, like dead letter payloads
+		b, err := json.Marshal(x)
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		return protoreflect.ValueOfString(string(b)), nil
+
+	case protoreflect.BoolKind:
+		if b, ok := x.(bool); ok {
+			return protoreflect.ValueOfBool(b), nil
+		}
+
+	case protoreflect.DoubleKind, protoreflect.FloatKind:
+		if n, ok := x.(json.Number); ok {
+			f, err := n.Float64()
+			if err != nil {
+				return protoreflect.Value{}, err
+			}
+			if fd.Kind() == protoreflect.FloatKind {
+				return protoreflect.ValueOfFloat32(float32(f)), nil
+			}
+			return protoreflect.ValueOfFloat64(f), nil
+		}
+
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		if n, ok := x.(json.Number); ok {
+			i, err := strconv.ParseInt(n.String(), 10, 64)
+			if err != nil {
+				return protoreflect.Value{}, err
+			}
+			if fd.Kind() == protoreflect.Int64Kind || fd.Kind() == protoreflect.Sint64Kind || fd.Kind() == protoreflect.Sfixed64Kind {
+				return protoreflect.ValueOfInt64(i), nil
+			}
+			return protoreflect.ValueOfInt32(int32(i)), nil
+		}
+
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind, protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		if n, ok := x.(json.Number); ok {
+			u, err := strconv.ParseUint(n.String(), 10, 64)
+			if err != nil {
+				return protoreflect.Value{}, err
+			}
+			if fd.Kind() == protoreflect.Uint64Kind || fd.Kind() == protoreflect.Fixed64Kind {
+				return protoreflect.ValueOfUint64(u), nil
+			}
+			return protoreflect.ValueOfUint32(uint32(u)), nil
+		}
+	}
+
+	return protoreflect.Value{}, fmt.Errorf("%s: %w", fd.Name(), errUnexpectedValue)
+}
@@ -1,18 +1,68 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
+	"math/big"
+	"time"
 
 	"github.com/autoapev1/indexer/auth"
+	"github.com/autoapev1/indexer/storage"
 	"github.com/autoapev1/indexer/types"
+	"github.com/autoapev1/indexer/webhook"
 )
 
-func (s *Server) handleJrpcRequest(r *JRPCRequest, authlvl auth.AuthLevel) Response {
+func (s *Server) handleJrpcRequest(r *JRPCRequest, c *caller) Response {
+	start := time.Now()
+	span := startCallSpan(r)
+	resp := s.dispatch(r, c)
+	endCallSpan(span, resp)
+	observeCall(r.Method, resp, start)
 
-	methodPrefix := getMethodPrefix(r.Method)
+	return resp
+}
+
+// dispatch runs the checks of a call, then its handler.
+func (s *Server) dispatch(r *JRPCRequest, c *caller) Response {
+
+	if resp := s.checkRateLimit(r, c); resp != nil {
+		return resp
+	}
+
+	// only calls that reach their handler are metered, calls refused by the
+	// rate limit, auth level, scopes or tier do not count against quotas
+	if resp := checkAccess(r, c.level); resp != nil {
+		return resp
+	}
+
+	if resp := checkScopes(r, c); resp != nil {
+		return resp
+	}
 
-	if methodPrefix == MethodInvalid {
+	if resp := s.checkTier(r, c); resp != nil {
+		return resp
+	}
+
+	r.scopes = c.scopes
+
+	var resp Response
+	if h, ok := c.local[r.Method]; ok {
+		resp = h(r)
+	} else {
+		resp = methodsByName[r.Method].Handler(s, r, c.key)
+	}
+	s.recordUsage(c, r, resp)
+
+	return resp
+}
+
+// checkAccess returns an error response if the method does not exist or needs
+// a higher auth level, nil otherwise.
+func checkAccess(r *JRPCRequest, authlvl auth.AuthLevel) *JRPCResponse {
+	method, ok := methodsByName[r.Method]
+	if !ok {
 		return &JRPCResponse{
 			ID:      r.ID,
 			JSONRPC: "2.0",
@@ -23,7 +73,7 @@ func (s *Server) handleJrpcRequest(r *JRPCRequest, authlvl auth.AuthLevel) Respo
 		}
 	}
 
-	if !hasAccess(methodPrefix, authlvl) {
+	if authlvl < method.Level {
 		return &JRPCResponse{
 			ID:      r.ID,
 			JSONRPC: "2.0",
@@ -34,64 +84,7 @@ func (s *Server) handleJrpcRequest(r *JRPCRequest, authlvl auth.AuthLevel) Respo
 		}
 	}
 
-	switch r.Method {
-	// global
-	case "idx_getBlockNumber":
-		return s.getBlockNumber(r)
-	case "idx_getChains":
-		return s.getChains(r)
-	case "idx_getHeights":
-		return s.getHeights(r)
-
-	// block timestamps
-	case "idx_getBlockTimestamps":
-		return s.getBlockTimestamps(r)
-	case "idx_getBlockAtTimestamp":
-		return s.getBlockAtTimestamp(r)
-
-	// tokens
-	case "idx_findTokens":
-		return s.findTokens(r)
-	case "idx_getTokenCount":
-		return s.getTokenCount(r)
-
-	// pairs
-	case "idx_findPairs":
-		return s.findPairs(r)
-	case "idx_getPairCount":
-		return s.getPairCount(r)
-
-	// holdings
-	case "idx_getWalletBalances":
-		return notImplemented(r)
-	case "idx_getTokenHolders":
-		return notImplemented(r)
-
-	// charts
-	case "idx_getOHLCVT":
-		return notImplemented(r)
-
-	case "auth_generateKey":
-		return notImplemented(r)
-	case "auth_deleteKey":
-		return notImplemented(r)
-	case "auth_getKeyStats":
-		return notImplemented(r)
-	case "auth_getAuthMethod":
-		return notImplemented(r)
-	case "auth_getKeyType":
-		return notImplemented(r)
-
-	default:
-		return &JRPCResponse{
-			ID:      r.ID,
-			JSONRPC: "2.0",
-			Error: &JRPCError{
-				Code:    -32601,
-				Message: "Method not found",
-			},
-		}
-	}
+	return nil
 }
 
 func notImplemented(r *JRPCRequest) *JRPCResponse {
@@ -123,6 +116,11 @@ func (s *Server) getBlockNumber(r *JRPCRequest) *types.GetBlockNumberResponse {
 
 	blockNumbers := map[int64]int64{}
 	for _, store := range stores {
+		// chain scoped keys only see their chains
+		if !r.scopes.AllowsChain(store.GetChainID()) {
+			continue
+		}
+
 		block, err := store.GetHight()
 		if err != nil {
 			if s.debug {
@@ -150,6 +148,10 @@ func (s *Server) getBlockNumber(r *JRPCRequest) *types.GetBlockNumberResponse {
 func (s *Server) getChains(r *JRPCRequest) *types.GetChainsResponse {
 	chains := []types.Chain{}
 	for _, c := range s.config.Chains {
+		if !r.scopes.AllowsChain(int64(c.ChainID)) {
+			continue
+		}
+
 		tc := types.Chain{
 			ChainID:     c.ChainID,
 			Name:        c.Name,
@@ -204,7 +206,7 @@ func (s *Server) getHeights(r *JRPCRequest) *types.GetHeightsResponse {
 		}
 	}
 
-	store := s.stores.GetStore(*req.ChainID)
+	store := s.store(r, *req.ChainID)
 	if store == nil {
 		return &types.GetHeightsResponse{
 			ID:     r.ID,
@@ -280,7 +282,7 @@ func (s *Server) getBlockTimestamps(r *JRPCRequest) *types.GetBlockTimestampsRes
 		}
 	}
 
-	store := s.stores.GetStore(*req.ChainID)
+	store := s.store(r, *req.ChainID)
 	if store == nil {
 		return &types.GetBlockTimestampsResponse{
 			ID:     r.ID,
@@ -352,7 +354,7 @@ func (s *Server) getBlockAtTimestamp(r *JRPCRequest) *types.GetBlockAtTimestampR
 		}
 	}
 
-	store := s.stores.GetStore(*req.ChainID)
+	store := s.store(r, *req.ChainID)
 	if store == nil {
 		return &types.GetBlockAtTimestampResponse{
 			ID:     r.ID,
@@ -423,7 +425,7 @@ func (s *Server) findTokens(r *JRPCRequest) *types.FindTokensResponse {
 		}
 	}
 
-	store := s.stores.GetStore(*req.ChainID)
+	store := s.store(r, *req.ChainID)
 	if store == nil {
 		return &types.FindTokensResponse{
 			ID:     r.ID,
@@ -496,7 +498,7 @@ func (s *Server) getTokenCount(r *JRPCRequest) *types.GetTokenCountResponse {
 		}
 	}
 
-	store := s.stores.GetStore(*req.ChainID)
+	store := s.store(r, *req.ChainID)
 	if store == nil {
 		return &types.GetTokenCountResponse{
 			ID:     r.ID,
@@ -568,7 +570,7 @@ func (s *Server) findPairs(r *JRPCRequest) *types.FindPairsResponse {
 		}
 	}
 
-	store := s.stores.GetStore(*req.ChainID)
+	store := s.store(r, *req.ChainID)
 	if store == nil {
 		return &types.FindPairsResponse{
 			ID:     r.ID,
@@ -641,7 +643,7 @@ func (s *Server) getPairCount(r *JRPCRequest) *types.GetPairCountResponse {
 		}
 	}
 
-	store := s.stores.GetStore(*req.ChainID)
+	store := s.store(r, *req.ChainID)
 	if store == nil {
 		return &types.GetPairCountResponse{
 			ID:     r.ID,
@@ -674,3 +676,1965 @@ func (s *Server) getPairCount(r *JRPCRequest) *types.GetPairCountResponse {
 		Result: count,
 	}
 }
+
+func (s *Server) getPairReserves(r *JRPCRequest) *types.GetPairReservesResponse {
+	req := &types.GetPairReservesRequest{}
+
+	if r.Params == nil {
+		return &types.GetPairReservesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.GetPairReservesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.GetPairReservesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	store := s.store(r, *req.ChainID)
+	if store == nil {
+		return &types.GetPairReservesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: "invalid chain_id",
+			},
+		}
+	}
+
+	reserves, err := store.GetPairReserves(*req.PoolAddress, *req.ToBlock, *req.FromBlock)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to get pair reserves", "err", err)
+		}
+		return &types.GetPairReservesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &types.GetPairReservesResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: reserves,
+	}
+}
+
+func (s *Server) getTokenMarkets(r *JRPCRequest) *types.GetTokenMarketsResponse {
+	req := &types.GetTokenMarketsRequest{}
+
+	if r.Params == nil {
+		return &types.GetTokenMarketsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.GetTokenMarketsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.GetTokenMarketsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	var stores []storage.Store
+	if req.ChainID != nil {
+		store := s.store(r, *req.ChainID)
+		if store == nil {
+			return &types.GetTokenMarketsResponse{
+				ID:     r.ID,
+				Method: r.Method,
+				Error: &types.JRPCError{
+					Code:    -32602,
+					Message: "invalid chain_id",
+				},
+			}
+		}
+		stores = append(stores, store)
+	} else {
+		stores = s.stores.GetAll()
+	}
+
+	var minLiquidity float64
+	if req.MinLiquidity != nil {
+		minLiquidity = *req.MinLiquidity
+	}
+
+	markets := make([]*types.TokenMarketGroup, 0)
+	for _, store := range stores {
+		pairs, err := store.GetPairsForToken(*req.Address, minLiquidity, int(req.Limit))
+		if err != nil {
+			if s.debug {
+				slog.Error("failed to get pairs for token", "err", err)
+			}
+			return &types.GetTokenMarketsResponse{
+				ID:     r.ID,
+				Method: r.Method,
+				Error: &types.JRPCError{
+					Code:    -32602,
+					Message: errInternalServer.Error(),
+				},
+			}
+		}
+
+		quotes := make([]string, 0, len(pairs))
+		for _, p := range pairs {
+			if m := p.Market(*req.Address); m != nil {
+				quotes = append(quotes, m.QuoteToken)
+			}
+		}
+
+		tokens, err := store.GetTokensByAddress(quotes)
+		if err != nil {
+			if s.debug {
+				slog.Error("failed to get quote tokens", "err", err)
+			}
+			return &types.GetTokenMarketsResponse{
+				ID:     r.ID,
+				Method: r.Method,
+				Error: &types.JRPCError{
+					Code:    -32602,
+					Message: errInternalServer.Error(),
+				},
+			}
+		}
+
+		markets = append(markets, types.GroupMarkets(*req.Address, pairs, tokens)...)
+	}
+
+	if len(stores) > 1 {
+		types.SortMarketGroups(markets)
+	}
+
+	return &types.GetTokenMarketsResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: markets,
+	}
+}
+
+func (s *Server) findRoutes(r *JRPCRequest) *types.FindRoutesResponse {
+	req := &types.FindRoutesRequest{}
+
+	if r.Params == nil {
+		return &types.FindRoutesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.FindRoutesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.FindRoutesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	if s.routes == nil {
+		return &types.FindRoutesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32701,
+				Message: errRoutesDisabled.Error(),
+			},
+		}
+	}
+
+	graph, ok := s.routes[*req.ChainID]
+	if !ok {
+		return &types.FindRoutesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: "invalid chain_id",
+			},
+		}
+	}
+
+	var amountIn *big.Int
+	if req.AmountIn != nil {
+		amountIn, _ = new(big.Int).SetString(*req.AmountIn, 10)
+	}
+
+	routes := graph.FindRoutes(*req.TokenIn, *req.TokenOut, req.MaxHops, req.Limit, amountIn)
+
+	return &types.FindRoutesResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: routes,
+	}
+}
+
+func (s *Server) getBlocks(r *JRPCRequest) *types.GetBlocksResponse {
+	req := &types.GetBlocksRequest{}
+
+	if r.Params == nil {
+		return &types.GetBlocksResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.GetBlocksResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.GetBlocksResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	store := s.store(r, *req.ChainID)
+	if store == nil {
+		return &types.GetBlocksResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: "invalid chain_id",
+			},
+		}
+	}
+
+	blocks, err := store.GetBlocks(*req.ToBlock, *req.FromBlock)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to get blocks", "err", err)
+		}
+		return &types.GetBlocksResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &types.GetBlocksResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: blocks,
+	}
+}
+
+// maxLogs is the most logs idx_getLogs returns, larger results are an error
+// like eth_getLogs on most providers.
+const maxLogs = 10000
+
+func (s *Server) getLogs(r *JRPCRequest) *types.GetLogsResponse {
+	req := &types.GetLogsRequest{}
+
+	if r.Params == nil {
+		return &types.GetLogsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.GetLogsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.GetLogsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	store := s.store(r, *req.ChainID)
+	if store == nil {
+		return &types.GetLogsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: "invalid chain_id",
+			},
+		}
+	}
+
+	if !s.logsEnabled(*req.ChainID) {
+		return &types.GetLogsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32702,
+				Message: errLogsDisabled.Error(),
+			},
+		}
+	}
+
+	logs, err := store.GetLogs(req, maxLogs+1)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to get logs", "err", err)
+		}
+		return &types.GetLogsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	if len(logs) > maxLogs {
+		return &types.GetLogsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32005,
+				Message: errTooManyLogs.Error(),
+			},
+		}
+	}
+
+	return &types.GetLogsResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: logs,
+	}
+}
+
+func (s *Server) logsEnabled(chainID int64) bool {
+	for _, c := range s.config.Chains {
+		if int64(c.ChainID) == chainID {
+			return c.Logs.Active()
+		}
+	}
+
+	return false
+}
+
+func (s *Server) watchWallets(r *JRPCRequest) *types.WatchWalletsResponse {
+	req := &types.WatchWalletsRequest{}
+
+	if r.Params == nil {
+		return &types.WatchWalletsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.WatchWalletsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.WatchWalletsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	store := s.store(r, *req.ChainID)
+	if store == nil {
+		return &types.WatchWalletsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: "invalid chain_id",
+			},
+		}
+	}
+
+	now := time.Now().Unix()
+	for _, w := range req.Wallets {
+		w.AddedAt = now
+	}
+
+	count, err := store.InsertWatchedWallets(req.Wallets)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to watch wallets", "err", err)
+		}
+		return &types.WatchWalletsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &types.WatchWalletsResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: count,
+	}
+}
+
+func (s *Server) unwatchWallets(r *JRPCRequest) *types.UnwatchWalletsResponse {
+	req := &types.UnwatchWalletsRequest{}
+
+	if r.Params == nil {
+		return &types.UnwatchWalletsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.UnwatchWalletsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.UnwatchWalletsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	store := s.store(r, *req.ChainID)
+	if store == nil {
+		return &types.UnwatchWalletsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: "invalid chain_id",
+			},
+		}
+	}
+
+	count, err := store.DeleteWatchedWallets(req.Addresses)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to unwatch wallets", "err", err)
+		}
+		return &types.UnwatchWalletsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &types.UnwatchWalletsResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: count,
+	}
+}
+
+func (s *Server) getWatchedWallets(r *JRPCRequest) *types.GetWatchedWalletsResponse {
+	req := &types.GetWatchedWalletsRequest{}
+
+	if r.Params == nil {
+		return &types.GetWatchedWalletsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.GetWatchedWalletsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.GetWatchedWalletsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	store := s.store(r, *req.ChainID)
+	if store == nil {
+		return &types.GetWatchedWalletsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: "invalid chain_id",
+			},
+		}
+	}
+
+	wallets, err := store.GetWatchedWallets()
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to get watched wallets", "err", err)
+		}
+		return &types.GetWatchedWalletsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &types.GetWatchedWalletsResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: wallets,
+	}
+}
+
+func (s *Server) getWalletBalanceHistory(r *JRPCRequest) *types.GetWalletBalanceHistoryResponse {
+	req := &types.GetWalletHistoryRequest{}
+
+	if r.Params == nil {
+		return &types.GetWalletBalanceHistoryResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.GetWalletBalanceHistoryResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.GetWalletBalanceHistoryResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	store := s.store(r, *req.ChainID)
+	if store == nil {
+		return &types.GetWalletBalanceHistoryResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: "invalid chain_id",
+			},
+		}
+	}
+
+	balances, err := store.GetWalletBalances(*req.Address, *req.ToBlock, *req.FromBlock)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to get wallet balances", "err", err)
+		}
+		return &types.GetWalletBalanceHistoryResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &types.GetWalletBalanceHistoryResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: balances,
+	}
+}
+
+func (s *Server) getWalletTransfers(r *JRPCRequest) *types.GetWalletTransfersResponse {
+	req := &types.GetWalletHistoryRequest{}
+
+	if r.Params == nil {
+		return &types.GetWalletTransfersResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.GetWalletTransfersResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.GetWalletTransfersResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	store := s.store(r, *req.ChainID)
+	if store == nil {
+		return &types.GetWalletTransfersResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: "invalid chain_id",
+			},
+		}
+	}
+
+	transfers, err := store.GetWalletTransfers(*req.Address, *req.ToBlock, *req.FromBlock)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to get wallet transfers", "err", err)
+		}
+		return &types.GetWalletTransfersResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &types.GetWalletTransfersResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: transfers,
+	}
+}
+
+// maxWebhooks is the most webhooks an api key can register per chain.
+const maxWebhooks = 20
+
+func (s *Server) createWebhook(r *JRPCRequest, key string) *types.CreateWebhookResponse {
+	req := &types.CreateWebhookRequest{}
+
+	if r.Params == nil {
+		return &types.CreateWebhookResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.CreateWebhookResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.CreateWebhookResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	store := s.store(r, *req.ChainID)
+	if store == nil {
+		return &types.CreateWebhookResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: "invalid chain_id",
+			},
+		}
+	}
+
+	// refused again when deliveries dial, in case the host is rebound
+	if !s.config.API.WebhookAllowPrivate {
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		err := webhook.CheckURL(ctx, *req.URL)
+		cancel()
+		if err != nil {
+			message := errWebhookURL.Error()
+			if errors.Is(err, webhook.ErrForbiddenTarget) {
+				message = err.Error()
+			}
+			return &types.CreateWebhookResponse{
+				ID:     r.ID,
+				Method: r.Method,
+				Error: &types.JRPCError{
+					Code:    -32602,
+					Message: message,
+				},
+			}
+		}
+	}
+
+	if s.config.API.FeedInterval <= 0 {
+		return &types.CreateWebhookResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32701,
+				Message: errWebhooksDisabled.Error(),
+			},
+		}
+	}
+
+	hooks, err := store.GetWebhooks(key)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to get webhooks", "err", err)
+		}
+		return &types.CreateWebhookResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	if len(hooks) >= maxWebhooks {
+		return &types.CreateWebhookResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errTooManyWebhooks.Error(),
+			},
+		}
+	}
+
+	secret, err := auth.GenerateKey(auth.KeyTypeHex64)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to generate webhook secret", "err", err)
+		}
+		return &types.CreateWebhookResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	hook := &types.Webhook{
+		Owner:       key,
+		URL:         *req.URL,
+		Type:        *req.Type,
+		PairFilter:  req.PairFilter,
+		TokenFilter: req.TokenFilter,
+		Secret:      secret,
+		CreatedAt:   time.Now().Unix(),
+	}
+
+	err = store.InsertWebhook(hook)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to create webhook", "err", err)
+		}
+		return &types.CreateWebhookResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &types.CreateWebhookResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: hook,
+	}
+}
+
+func (s *Server) deleteWebhook(r *JRPCRequest, key string) *types.DeleteWebhookResponse {
+	req := &types.DeleteWebhookRequest{}
+
+	if r.Params == nil {
+		return &types.DeleteWebhookResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.DeleteWebhookResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.DeleteWebhookResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	store := s.store(r, *req.ChainID)
+	if store == nil {
+		return &types.DeleteWebhookResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: "invalid chain_id",
+			},
+		}
+	}
+
+	deleted, err := store.DeleteWebhook(key, *req.ID)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to delete webhook", "err", err)
+		}
+		return &types.DeleteWebhookResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &types.DeleteWebhookResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: deleted,
+	}
+}
+
+func (s *Server) listWebhooks(r *JRPCRequest, key string) *types.ListWebhooksResponse {
+	req := &types.ListWebhooksRequest{}
+
+	if r.Params == nil {
+		return &types.ListWebhooksResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.ListWebhooksResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.ListWebhooksResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	store := s.store(r, *req.ChainID)
+	if store == nil {
+		return &types.ListWebhooksResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: "invalid chain_id",
+			},
+		}
+	}
+
+	hooks, err := store.GetWebhooks(key)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to get webhooks", "err", err)
+		}
+		return &types.ListWebhooksResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	// the secret is only shown on create
+	for _, h := range hooks {
+		h.Secret = ""
+	}
+
+	return &types.ListWebhooksResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: hooks,
+	}
+}
+
+func (s *Server) getWebhookDeliveries(r *JRPCRequest, key string) *types.GetWebhookDeliveriesResponse {
+	req := &types.GetWebhookLogRequest{}
+
+	if r.Params == nil {
+		return &types.GetWebhookDeliveriesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.GetWebhookDeliveriesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.GetWebhookDeliveriesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	store := s.store(r, *req.ChainID)
+	if store == nil {
+		return &types.GetWebhookDeliveriesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: "invalid chain_id",
+			},
+		}
+	}
+
+	err = s.ownsWebhook(store, key, *req.ID)
+	if err != nil {
+		return &types.GetWebhookDeliveriesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	deliveries, err := store.GetWebhookDeliveries(*req.ID, req.Limit)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to get webhook deliveries", "err", err)
+		}
+		return &types.GetWebhookDeliveriesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &types.GetWebhookDeliveriesResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: deliveries,
+	}
+}
+
+func (s *Server) getWebhookDeadLetters(r *JRPCRequest, key string) *types.GetWebhookDeadLettersResponse {
+	req := &types.GetWebhookLogRequest{}
+
+	if r.Params == nil {
+		return &types.GetWebhookDeadLettersResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.GetWebhookDeadLettersResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.GetWebhookDeadLettersResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	store := s.store(r, *req.ChainID)
+	if store == nil {
+		return &types.GetWebhookDeadLettersResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: "invalid chain_id",
+			},
+		}
+	}
+
+	err = s.ownsWebhook(store, key, *req.ID)
+	if err != nil {
+		return &types.GetWebhookDeadLettersResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	letters, err := store.GetWebhookDeadLetters(*req.ID, req.Limit)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to get webhook dead letters", "err", err)
+		}
+		return &types.GetWebhookDeadLettersResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &types.GetWebhookDeadLettersResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: letters,
+	}
+}
+
+// ownsWebhook returns errWebhookNotFound unless the webhook belongs to key,
+// store errors are replaced by errInternalServer.
+func (s *Server) ownsWebhook(store storage.Store, key string, id int64) error {
+	hooks, err := store.GetWebhooks(key)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to get webhooks", "err", err)
+		}
+		return errInternalServer
+	}
+
+	for _, h := range hooks {
+		if h.ID == id {
+			return nil
+		}
+	}
+
+	return errWebhookNotFound
+}
+
+func (s *Server) generateKey(r *JRPCRequest) *types.GenerateKeyResponse {
+	req := &types.GenerateKeyRequest{}
+
+	// params are optional
+	if r.Params != nil {
+		err := json.Unmarshal(r.Params, req)
+		if err != nil {
+			return &types.GenerateKeyResponse{
+				ID:     r.ID,
+				Method: r.Method,
+				Error: &types.JRPCError{
+					Code:    -32602,
+					Message: errUnmarshalParams.Error(),
+				},
+			}
+		}
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return &types.GenerateKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	if req.Tier != "" && s.config.API.Tier(req.Tier) == nil {
+		return &types.GenerateKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnknownTier.Error(),
+			},
+		}
+	}
+
+	key, err := s.auth.Register(auth.KeyOptions{
+		Exp:    req.Exp,
+		Owner:  req.Owner,
+		Notes:  req.Notes,
+		Scopes: req.Scopes,
+		Tier:   req.Tier,
+	})
+	if err != nil {
+		if errors.Is(err, auth.ErrCannotMint) || errors.Is(err, auth.ErrKeyLifetime) {
+			return &types.GenerateKeyResponse{
+				ID:     r.ID,
+				Method: r.Method,
+				Error: &types.JRPCError{
+					Code:    -32602,
+					Message: err.Error(),
+				},
+			}
+		}
+		if s.debug {
+			slog.Error("failed to generate key", "err", err)
+		}
+		return &types.GenerateKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	if key == "" {
+		return &types.GenerateKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errNoAuthKeys.Error(),
+			},
+		}
+	}
+
+	return &types.GenerateKeyResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: key,
+	}
+}
+
+func (s *Server) deleteKey(r *JRPCRequest) *types.DeleteKeyResponse {
+	req := &types.APIKeyRequest{}
+
+	if r.Params == nil {
+		return &types.DeleteKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.DeleteKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.DeleteKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	deleted, err := s.auth.DeleteKey(*req.Key)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to delete key", "err", err)
+		}
+		return &types.DeleteKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &types.DeleteKeyResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: deleted,
+	}
+}
+
+func (s *Server) revokeKey(r *JRPCRequest) *types.RevokeKeyResponse {
+	req := &types.APIKeyRequest{}
+
+	if r.Params == nil {
+		return &types.RevokeKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.RevokeKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.RevokeKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	revoked, err := s.auth.RevokeKey(*req.Key)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to revoke key", "err", err)
+		}
+		return &types.RevokeKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &types.RevokeKeyResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: revoked,
+	}
+}
+
+func (s *Server) getKeyStats(r *JRPCRequest) *types.GetKeyStatsResponse {
+	req := &types.GetKeyStatsRequest{}
+
+	if r.Params == nil {
+		return &types.GetKeyStatsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.GetKeyStatsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.GetKeyStatsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	stats, err := s.auth.GetKeyStats(*req.Key)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidKey) {
+			return &types.GetKeyStatsResponse{
+				ID:     r.ID,
+				Method: r.Method,
+				Error: &types.JRPCError{
+					Code:    -32602,
+					Message: err.Error(),
+				},
+			}
+		}
+		if s.debug {
+			slog.Error("failed to get key stats", "err", err)
+		}
+		return &types.GetKeyStatsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	from := time.Now().UTC().AddDate(0, 0, 1-req.Days).Format(auth.DayFormat)
+	stats.Daily, err = s.auth.GetDailyUsage(*req.Key, from)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to get daily key usage", "err", err)
+		}
+		return &types.GetKeyStatsResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &types.GetKeyStatsResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: stats,
+	}
+}
+
+func (s *Server) listKeys(r *JRPCRequest) *types.ListKeysResponse {
+	keys, err := s.auth.ListKeys()
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to list keys", "err", err)
+		}
+		return &types.ListKeysResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &types.ListKeysResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: keys,
+	}
+}
+
+func (s *Server) getAuthMethod(r *JRPCRequest) *types.GetAuthMethodResponse {
+	return &types.GetAuthMethodResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: string(auth.ToProvider(s.config.API.AuthProvider)),
+	}
+}
+
+func (s *Server) getKeyType(r *JRPCRequest) *types.GetKeyTypeResponse {
+	return &types.GetKeyTypeResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: auth.ToKeyType(s.config.API.AuthKeyType).String(),
+	}
+}
+
+func (s *Server) extendKey(r *JRPCRequest) *types.ExtendKeyResponse {
+	req := &types.ExtendKeyRequest{}
+
+	if r.Params == nil {
+		return &types.ExtendKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.ExtendKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.ExtendKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	extended, err := s.auth.ExtendKey(*req.Key, *req.Exp)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to extend key", "err", err)
+		}
+		return &types.ExtendKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &types.ExtendKeyResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: extended,
+	}
+}
+
+func (s *Server) setKeyScopes(r *JRPCRequest) *types.SetKeyScopesResponse {
+	req := &types.SetKeyScopesRequest{}
+
+	if r.Params == nil {
+		return &types.SetKeyScopesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.SetKeyScopesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.SetKeyScopesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	updated, err := s.auth.SetKeyScopes(*req.Key, req.Scopes)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to set key scopes", "err", err)
+		}
+		return &types.SetKeyScopesResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &types.SetKeyScopesResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: updated,
+	}
+}
+
+func (s *Server) setKeyTier(r *JRPCRequest) *types.SetKeyTierResponse {
+	req := &types.SetKeyTierRequest{}
+
+	if r.Params == nil {
+		return &types.SetKeyTierResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.SetKeyTierResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.SetKeyTierResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	if req.Tier != "" && s.config.API.Tier(req.Tier) == nil {
+		return &types.SetKeyTierResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnknownTier.Error(),
+			},
+		}
+	}
+
+	updated, err := s.auth.SetKeyTier(*req.Key, req.Tier)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to set key tier", "err", err)
+		}
+		return &types.SetKeyTierResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &types.SetKeyTierResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: updated,
+	}
+}
+
+func (s *Server) rotateKey(r *JRPCRequest) *types.RotateKeyResponse {
+	req := &types.APIKeyRequest{}
+
+	if r.Params == nil {
+		return &types.RotateKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.RotateKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.RotateKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	// the meter moves the usage it has not flushed yet to the rotated key
+	rotate := s.auth.RotateKey
+	if s.usage != nil {
+		rotate = s.usage.RotateKey
+	}
+
+	rotated, err := rotate(*req.Key)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidKey) || errors.Is(err, auth.ErrCannotMint) {
+			return &types.RotateKeyResponse{
+				ID:     r.ID,
+				Method: r.Method,
+				Error: &types.JRPCError{
+					Code:    -32602,
+					Message: err.Error(),
+				},
+			}
+		}
+		if s.debug {
+			slog.Error("failed to rotate key", "err", err)
+		}
+		return &types.RotateKeyResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	// webhooks belong to the key
+	for _, store := range s.stores.GetAll() {
+		if _, err := store.TransferWebhooks(*req.Key, rotated); err != nil {
+			slog.Error("failed to transfer webhooks of rotated key", "chain_id", store.GetChainID(), "err", err)
+		}
+	}
+
+	return &types.RotateKeyResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: rotated,
+	}
+}
+
+func (s *Server) getExpiringKeys(r *JRPCRequest) *types.GetExpiringKeysResponse {
+	req := &types.GetExpiringKeysRequest{}
+
+	// params are optional
+	if r.Params != nil {
+		err := json.Unmarshal(r.Params, req)
+		if err != nil {
+			return &types.GetExpiringKeysResponse{
+				ID:     r.ID,
+				Method: r.Method,
+				Error: &types.JRPCError{
+					Code:    -32602,
+					Message: errUnmarshalParams.Error(),
+				},
+			}
+		}
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return &types.GetExpiringKeysResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	keys, err := s.auth.ListExpiringKeys(time.Now().Unix() + req.Within)
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to list expiring keys", "err", err)
+		}
+		return &types.GetExpiringKeysResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &types.GetExpiringKeysResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: keys,
+	}
+}
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/autoapev1/indexer/config"
+	"github.com/autoapev1/indexer/eth"
+	"github.com/autoapev1/indexer/storage"
+	"github.com/autoapev1/indexer/types"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// readyCacheTTL is how long a readiness report is reused, so frequent
+	// probes do not query every database and rpc endpoint.
+	readyCacheTTL = 2 * time.Second
+	// rpcCheckTimeout bounds the chain head call of a readiness check.
+	rpcCheckTimeout = 5 * time.Second
+)
+
+// readiness is the report of /readyz, the server is ready if every chain is.
+type readiness struct {
+	Status string            `json:"status"` // ok | unavailable
+	Chains []*chainReadiness `json:"chains"`
+}
+
+type chainReadiness struct {
+	ChainID int64             `json:"chain_id"`
+	Ready   bool              `json:"ready"`
+	DB      string            `json:"db"`            // ok | unavailable
+	RPC     string            `json:"rpc,omitempty"` // ok | unavailable, empty without an rpc url
+	Head    int64             `json:"head,omitempty"`
+	Stages  []*stageReadiness `json:"stages,omitempty"`
+}
+
+// stageReadiness is the height of a sync stage, only checked stages can make
+// a chain unready.
+type stageReadiness struct {
+	Stage   string `json:"stage"`
+	Height  int64  `json:"height"`
+	Lag     int64  `json:"lag"`
+	Checked bool   `json:"checked"`
+	Ready   bool   `json:"ready"`
+}
+
+type readyCache struct {
+	lock   sync.Mutex
+	report *readiness
+	at     time.Time
+
+	// concurrent probes wait for a single check, run without lock
+	checks singleflight.Group
+}
+
+// initHealth dials the rpc endpoint of every chain with an rpc url, the chain
+// head is read from it by readiness checks.
+func (s *Server) initHealth() error {
+	s.networks = make(map[int64]*eth.Network)
+
+	for _, c := range s.config.Chains {
+		if c.RPCURL == "" {
+			continue
+		}
+
+		n := eth.NewNetwork(types.Chain{
+			ChainID:   c.ChainID,
+			Name:      c.Name,
+			ShortName: c.ShortName,
+			Http:      c.RPCURL,
+		}, s.config)
+
+		// the check of the chain reports the endpoint as unavailable
+		if err := n.Init(); err != nil {
+			slog.Warn("failed to initialize rpc client, readiness checks will fail", "chainID", c.ChainID, "err", err)
+			n = nil
+		}
+
+		s.networks[int64(c.ChainID)] = n
+	}
+
+	return nil
+}
+
+// handleReady is the readiness probe, 503 if a database or rpc endpoint is
+// unavailable or a stage is more than api.healthMaxLag blocks behind the chain
+// head.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) error {
+	report := s.readiness()
+	if report.Status != "ok" {
+		return writeJSON(w, http.StatusServiceUnavailable, report)
+	}
+
+	return writeJSON(w, http.StatusOK, report)
+}
+
+// readiness checks every chain, or returns the last report if it is recent.
+func (s *Server) readiness() *readiness {
+	s.ready.lock.Lock()
+	report, at := s.ready.report, s.ready.at
+	s.ready.lock.Unlock()
+
+	if report != nil && time.Since(at) < readyCacheTTL {
+		return report
+	}
+
+	v, _, _ := s.ready.checks.Do("ready", func() (interface{}, error) {
+		report := s.checkReadiness()
+
+		s.ready.lock.Lock()
+		s.ready.report = report
+		s.ready.at = time.Now()
+		s.ready.lock.Unlock()
+
+		return report, nil
+	})
+
+	return v.(*readiness)
+}
+
+// checkReadiness checks every chain concurrently.
+func (s *Server) checkReadiness() *readiness {
+	stores := s.stores.GetAll()
+	sort.Slice(stores, func(i, j int) bool {
+		return stores[i].GetChainID() < stores[j].GetChainID()
+	})
+
+	results := make([]*chainReadiness, len(stores))
+	var wg sync.WaitGroup
+	for i, store := range stores {
+		wg.Add(1)
+		go func(i int, store storage.Store) {
+			defer wg.Done()
+			results[i] = s.checkChain(store)
+		}(i, store)
+	}
+	wg.Wait()
+
+	report := &readiness{Status: "ok", Chains: results}
+	for _, c := range results {
+		if !c.Ready {
+			report.Status = "unavailable"
+		}
+	}
+
+	return report
+}
+
+// checkChain checks the database and rpc endpoint of a chain, and the lag of
+// its stages behind the chain head.
+func (s *Server) checkChain(store storage.Store) *chainReadiness {
+	chainID := store.GetChainID()
+	c := &chainReadiness{ChainID: chainID, Ready: true, DB: "ok"}
+
+	if err := store.Ping(); err != nil {
+		slog.Error("readiness: database unavailable", "chainID", chainID, "err", err)
+		c.DB = "unavailable"
+		c.Ready = false
+	}
+
+	if n, ok := s.networks[chainID]; ok {
+		c.RPC = "ok"
+		if n == nil {
+			c.RPC = "unavailable"
+			c.Ready = false
+		} else {
+			ctx, cancel := context.WithTimeout(context.Background(), rpcCheckTimeout)
+			head, err := n.GetBlockNumber(ctx)
+			cancel()
+			if err != nil {
+				slog.Error("readiness: rpc unavailable", "chainID", chainID, "err", err)
+				c.RPC = "unavailable"
+				c.Ready = false
+			}
+			c.Head = head
+		}
+	}
+
+	if c.DB != "ok" {
+		return c
+	}
+
+	heights, err := store.GetHeights()
+	if err != nil {
+		slog.Error("readiness: failed to get heights", "chainID", chainID, "err", err)
+		c.DB = "unavailable"
+		c.Ready = false
+		return c
+	}
+
+	c.Stages = s.checkStages(chainID, heights, c.Head)
+	for _, st := range c.Stages {
+		if !st.Ready {
+			c.Ready = false
+		}
+	}
+
+	return c
+}
+
+// checkStages compares the heights of a chain with its head, when it is
+// known. Pairs and tokens are the block of their latest row rather than a sync
+// cursor, a chain without new pools for a while would look behind, so they are
+// reported but not checked. Reserves is checked once its cursor is set, it
+// stays 0 until the first pool is synced. Logs are checked when the syncer
+// keeps them. The sync height metrics are left to the syncer.
+func (s *Server) checkStages(chainID int64, heights *types.Heights, head int64) []*stageReadiness {
+	chainConf := s.chainConfig(chainID)
+
+	stages := []*stageReadiness{
+		{Stage: "blocks", Height: heights.Blocks, Checked: true},
+		{Stage: "headers", Height: heights.Headers, Checked: true},
+		{Stage: "pairs", Height: heights.Pairs},
+		{Stage: "tokens", Height: heights.Tokens},
+		{Stage: "reserves", Height: heights.Reserves, Checked: heights.Reserves > 0},
+	}
+
+	if chainConf.Logs.Active() {
+		stages = append(stages, &stageReadiness{Stage: "logs", Height: heights.Logs, Checked: true})
+	}
+
+	maxLag := s.config.API.HealthMaxLag
+	for _, st := range stages {
+		st.Ready = true
+
+		if head <= 0 {
+			continue
+		}
+
+		st.Lag = head - st.Height
+		if st.Checked && maxLag > 0 && st.Lag > maxLag {
+			st.Ready = false
+		}
+	}
+
+	return stages
+}
+
+// chainConfig returns the config of a chain, or an empty config if the chain
+// is not listed.
+func (s *Server) chainConfig(chainID int64) config.ChainConfig {
+	for _, c := range s.config.Chains {
+		if int64(c.ChainID) == chainID {
+			return c
+		}
+	}
+
+	return config.ChainConfig{}
+}
@@ -1,11 +1,15 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"log/slog"
-	"strings"
+	"net"
+	"net/http"
+	"time"
 
 	"github.com/autoapev1/indexer/auth"
+	"github.com/autoapev1/indexer/types"
 )
 
 type JRPCRequest struct {
@@ -13,6 +17,26 @@ type JRPCRequest struct {
 	JSONRPC string          `json:"jsonrpc"`
 	Method  string          `json:"method"`
 	Params  json.RawMessage `json:"params"`
+
+	// ctx carries the trace of the transport the call came in on
+	ctx context.Context
+
+	// scopes of the caller, set by dispatch for the methods that list every
+	// chain
+	scopes *types.KeyScopes
+
+	// reserved is when checkTier counted the call against the quotas of the
+	// key, zero if it did not
+	reserved time.Time
+}
+
+// Context returns the context of the call, the dispatch span once it is
+// handled.
+func (r *JRPCRequest) Context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
 }
 
 type JRPCResponse struct {
@@ -35,32 +59,65 @@ const (
 	MethodInvalid MethodPrefix = ""
 	MethodIdx     MethodPrefix = "idx_"
 	MethodAuth    MethodPrefix = "auth_"
+	MethodAdmin   MethodPrefix = "admin_"
 )
 
-func getMethodPrefix(s string) MethodPrefix {
-	if !strings.Contains(s, "_") {
-		return MethodInvalid
-	}
-	m := strings.Split(s, "_")[0]
-
-	switch m {
-	case "idx":
-		return MethodIdx
-	case "auth":
-		return MethodAuth
-	default:
-		return MethodInvalid
-	}
-}
-
 func hasAccess(methodPrefix MethodPrefix, authlvl auth.AuthLevel) bool {
 	switch methodPrefix {
 	case MethodIdx:
 		return authlvl >= auth.AuthLevelBasic
-	case MethodAuth:
+	case MethodAuth, MethodAdmin:
 		return authlvl >= auth.AuthLevelMaster
 	default:
 		slog.Warn("invalid method prefix", "method_prefix", methodPrefix, "auth_level", authlvl)
 		return false
 	}
 }
+
+// caller is the sender of a request, shared by the elements of a batch.
+type caller struct {
+	level  auth.AuthLevel
+	key    string // empty without auth
+	scopes *types.KeyScopes
+	tier   string // empty for the default tier
+	ip     string
+	origin string // Origin header, empty outside browsers
+
+	// methods served by the transport rather than the registry, like
+	// idx_subscribe on websockets
+	local map[string]func(r *JRPCRequest) Response
+}
+
+// callerFromRequest returns the caller authenticated by the auth middleware.
+func callerFromRequest(r *http.Request) (*caller, bool) {
+	level, ok := r.Context().Value(auth.AuthKey).(auth.AuthLevel)
+	if !ok || !auth.IsValidAuthLevel(level) {
+		return nil, false
+	}
+
+	// empty without auth
+	key, _ := r.Context().Value(auth.APIKey).(string)
+
+	c := &caller{
+		level:  level,
+		key:    key,
+		ip:     remoteIP(r.RemoteAddr),
+		origin: r.Header.Get("Origin"),
+	}
+
+	if info, _ := r.Context().Value(auth.KeyInfoKey).(*auth.KeyInfo); info != nil {
+		c.scopes = info.Scopes
+		c.tier = info.Tier
+	}
+
+	return c, true
+}
+
+// remoteIP strips the port of a remote address, realIPMiddleware sets it
+// without one.
+func remoteIP(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
@@ -0,0 +1,429 @@
+package api
+
+import (
+	"github.com/autoapev1/indexer/auth"
+	"github.com/autoapev1/indexer/types"
+)
+
+// rpcMethod declares a json-rpc method. The registry drives dispatch, the
+// access checks and the OpenRPC document served by rpc.discover.
+type rpcMethod struct {
+	Name    string
+	Summary string
+	Params  interface{} // zero value of the params type, nil if it takes none
+	Result  interface{} // zero value of the response type, its Result field is documented
+	Level   auth.AuthLevel
+	Cost    int // rate limit cost of a call, 1 if 0
+	Handler func(s *Server, r *JRPCRequest, key string) Response
+}
+
+var methodRegistry = []*rpcMethod{
+	// discovery
+	{
+		Name:    "rpc.discover",
+		Summary: "Get the OpenRPC document of the API",
+		Level:   auth.AuthLevelBasic,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.discover(r) },
+	},
+
+	// global
+	{
+		Name:    "idx_getBlockNumber",
+		Summary: "Get the current block number of every chain",
+		Result:  types.GetBlockNumberResponse{},
+		Level:   auth.AuthLevelBasic,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.getBlockNumber(r) },
+	},
+	{
+		Name:    "idx_getChains",
+		Summary: "Get the chain IDs for the supported chains",
+		Result:  types.GetChainsResponse{},
+		Level:   auth.AuthLevelBasic,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.getChains(r) },
+	},
+	{
+		Name:    "idx_getHeights",
+		Summary: "Get the sync height of each dataset of a chain",
+		Params:  types.GetHeightsRequest{},
+		Result:  types.GetHeightsResponse{},
+		Level:   auth.AuthLevelBasic,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.getHeights(r) },
+	},
+
+	// block timestamps
+	{
+		Name:    "idx_getBlockTimestamps",
+		Summary: "Get the timestamp for a range of block numbers",
+		Params:  types.GetBlockTimestampsRequest{},
+		Result:  types.GetBlockTimestampsResponse{},
+		Level:   auth.AuthLevelBasic,
+		Cost:    10,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.getBlockTimestamps(r) },
+	},
+	{
+		Name:    "idx_getBlockAtTimestamp",
+		Summary: "Get the block number at a timestamp",
+		Params:  types.GetBlockAtTimestampRequest{},
+		Result:  types.GetBlockAtTimestampResponse{},
+		Level:   auth.AuthLevelBasic,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.getBlockAtTimestamp(r) },
+	},
+
+	// blocks
+	{
+		Name:    "idx_getBlocks",
+		Summary: "Get the block headers for a range of block numbers",
+		Params:  types.GetBlocksRequest{},
+		Result:  types.GetBlocksResponse{},
+		Level:   auth.AuthLevelBasic,
+		Cost:    5,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.getBlocks(r) },
+	},
+
+	// tokens
+	{
+		Name:    "idx_findTokens",
+		Summary: "Find tokens by using find params",
+		Params:  types.FindTokensRequest{},
+		Result:  types.FindTokensResponse{},
+		Level:   auth.AuthLevelBasic,
+		Cost:    5,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.findTokens(r) },
+	},
+	{
+		Name:    "idx_getTokenCount",
+		Summary: "Get the total number of tokens",
+		Params:  types.GetTokenCountRequest{},
+		Result:  types.GetTokenCountResponse{},
+		Level:   auth.AuthLevelBasic,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.getTokenCount(r) },
+	},
+
+	// pairs
+	{
+		Name:    "idx_findPairs",
+		Summary: "Find pairs by using find params",
+		Params:  types.FindPairsRequest{},
+		Result:  types.FindPairsResponse{},
+		Level:   auth.AuthLevelBasic,
+		Cost:    5,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.findPairs(r) },
+	},
+	{
+		Name:    "idx_getPairCount",
+		Summary: "Get the total number of pairs",
+		Params:  types.GetPairCountRequest{},
+		Result:  types.GetPairCountResponse{},
+		Level:   auth.AuthLevelBasic,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.getPairCount(r) },
+	},
+	{
+		Name:    "idx_getPairReserves",
+		Summary: "Get the reserve history of a pair",
+		Params:  types.GetPairReservesRequest{},
+		Result:  types.GetPairReservesResponse{},
+		Level:   auth.AuthLevelBasic,
+		Cost:    5,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.getPairReserves(r) },
+	},
+	{
+		Name:    "idx_getTokenMarkets",
+		Summary: "Get every pool for a token, grouped by quote token",
+		Params:  types.GetTokenMarketsRequest{},
+		Result:  types.GetTokenMarketsResponse{},
+		Level:   auth.AuthLevelBasic,
+		Cost:    5,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.getTokenMarkets(r) },
+	},
+
+	// routes
+	{
+		Name:    "idx_findRoutes",
+		Summary: "Find swap paths between two tokens",
+		Params:  types.FindRoutesRequest{},
+		Result:  types.FindRoutesResponse{},
+		Level:   auth.AuthLevelBasic,
+		Cost:    10,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.findRoutes(r) },
+	},
+
+	// logs
+	{
+		Name:    "idx_getLogs",
+		Summary: "Get stored raw logs with eth_getLogs filter semantics (opt-in per chain)",
+		Params:  types.GetLogsRequest{},
+		Result:  types.GetLogsResponse{},
+		Level:   auth.AuthLevelBasic,
+		Cost:    10,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.getLogs(r) },
+	},
+
+	// watched wallets
+	{
+		Name:    "idx_getWalletBalanceHistory",
+		Summary: "Get the native balance changes of a watched wallet",
+		Params:  types.GetWalletHistoryRequest{},
+		Result:  types.GetWalletBalanceHistoryResponse{},
+		Level:   auth.AuthLevelBasic,
+		Cost:    5,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.getWalletBalanceHistory(r) },
+	},
+	{
+		Name:    "idx_getWalletTransfers",
+		Summary: "Get the large token transfers of a watched wallet",
+		Params:  types.GetWalletHistoryRequest{},
+		Result:  types.GetWalletTransfersResponse{},
+		Level:   auth.AuthLevelBasic,
+		Cost:    5,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.getWalletTransfers(r) },
+	},
+
+	// subscriptions, served by the websocket connection
+	{
+		Name:    "idx_subscribe",
+		Summary: "Stream new pairs, tokens, blocks or sync heights (websocket only)",
+		Params:  types.SubscribeRequest{},
+		Result:  types.SubscribeResponse{},
+		Level:   auth.AuthLevelBasic,
+		Handler: websocketOnly,
+	},
+	{
+		Name:    "idx_unsubscribe",
+		Summary: "Cancel a subscription (websocket only)",
+		Params:  types.UnsubscribeRequest{},
+		Result:  types.UnsubscribeResponse{},
+		Level:   auth.AuthLevelBasic,
+		Handler: websocketOnly,
+	},
+
+	// webhooks
+	{
+		Name:    "idx_createWebhook",
+		Summary: "Get new pairs or tokens matching a filter posted to a url",
+		Params:  types.CreateWebhookRequest{},
+		Result:  types.CreateWebhookResponse{},
+		Level:   auth.AuthLevelBasic,
+		Handler: keyOwned(func(s *Server, r *JRPCRequest, key string) Response { return s.createWebhook(r, key) }),
+	},
+	{
+		Name:    "idx_deleteWebhook",
+		Summary: "Delete a webhook",
+		Params:  types.DeleteWebhookRequest{},
+		Result:  types.DeleteWebhookResponse{},
+		Level:   auth.AuthLevelBasic,
+		Handler: keyOwned(func(s *Server, r *JRPCRequest, key string) Response { return s.deleteWebhook(r, key) }),
+	},
+	{
+		Name:    "idx_listWebhooks",
+		Summary: "List the webhooks of the api key",
+		Params:  types.ListWebhooksRequest{},
+		Result:  types.ListWebhooksResponse{},
+		Level:   auth.AuthLevelBasic,
+		Handler: keyOwned(func(s *Server, r *JRPCRequest, key string) Response { return s.listWebhooks(r, key) }),
+	},
+	{
+		Name:    "idx_getWebhookDeliveries",
+		Summary: "Get the delivery log of a webhook",
+		Params:  types.GetWebhookLogRequest{},
+		Result:  types.GetWebhookDeliveriesResponse{},
+		Level:   auth.AuthLevelBasic,
+		Handler: keyOwned(func(s *Server, r *JRPCRequest, key string) Response { return s.getWebhookDeliveries(r, key) }),
+	},
+	{
+		Name:    "idx_getWebhookDeadLetters",
+		Summary: "Get the payloads of a webhook that failed every attempt",
+		Params:  types.GetWebhookLogRequest{},
+		Result:  types.GetWebhookDeadLettersResponse{},
+		Level:   auth.AuthLevelBasic,
+		Handler: keyOwned(func(s *Server, r *JRPCRequest, key string) Response { return s.getWebhookDeadLetters(r, key) }),
+	},
+
+	// holdings
+	{
+		Name:    "idx_getWalletBalances",
+		Summary: "Get wallet balances for a pair (WIP)",
+		Level:   auth.AuthLevelBasic,
+		Handler: notImplementedMethod,
+	},
+	{
+		Name:    "idx_getTokenHolders",
+		Summary: "Get token holders for a token (WIP)",
+		Level:   auth.AuthLevelBasic,
+		Cost:    5,
+		Handler: notImplementedMethod,
+	},
+
+	// charts
+	{
+		Name:    "idx_getOHLCVT",
+		Summary: "Get OHLCV chart data for a pair (WIP)",
+		Level:   auth.AuthLevelBasic,
+		Cost:    5,
+		Handler: notImplementedMethod,
+	},
+
+	// auth
+	{
+		Name:    "auth_generateKey",
+		Summary: "Generate a new API key, with an optional expiry, owner and notes",
+		Params:  types.GenerateKeyRequest{},
+		Result:  types.GenerateKeyResponse{},
+		Level:   auth.AuthLevelMaster,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.generateKey(r) },
+	},
+	{
+		Name:    "auth_deleteKey",
+		Summary: "Delete an API key and its usage",
+		Params:  types.APIKeyRequest{},
+		Result:  types.DeleteKeyResponse{},
+		Level:   auth.AuthLevelMaster,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.deleteKey(r) },
+	},
+	{
+		Name:    "auth_revokeKey",
+		Summary: "Stop an API key from authenticating, keeping its usage",
+		Params:  types.APIKeyRequest{},
+		Result:  types.RevokeKeyResponse{},
+		Level:   auth.AuthLevelMaster,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.revokeKey(r) },
+	},
+	{
+		Name:    "auth_extendKey",
+		Summary: "Set the expiry of an API key",
+		Params:  types.ExtendKeyRequest{},
+		Result:  types.ExtendKeyResponse{},
+		Level:   auth.AuthLevelMaster,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.extendKey(r) },
+	},
+	{
+		Name:    "auth_setKeyScopes",
+		Summary: "Restrict the methods, chains, origins and ips of an API key",
+		Params:  types.SetKeyScopesRequest{},
+		Result:  types.SetKeyScopesResponse{},
+		Level:   auth.AuthLevelMaster,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.setKeyScopes(r) },
+	},
+	{
+		Name:    "auth_setKeyTier",
+		Summary: "Assign an API key to a subscription tier",
+		Params:  types.SetKeyTierRequest{},
+		Result:  types.SetKeyTierResponse{},
+		Level:   auth.AuthLevelMaster,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.setKeyTier(r) },
+	},
+	{
+		Name:    "auth_rotateKey",
+		Summary: "Replace the secret of an API key, keeping its usage, metadata and webhooks",
+		Params:  types.APIKeyRequest{},
+		Result:  types.RotateKeyResponse{},
+		Level:   auth.AuthLevelMaster,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.rotateKey(r) },
+	},
+	{
+		Name:    "auth_getKeyStats",
+		Summary: "Get usage information for an API key, with its daily usage",
+		Params:  types.GetKeyStatsRequest{},
+		Result:  types.GetKeyStatsResponse{},
+		Level:   auth.AuthLevelMaster,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.getKeyStats(r) },
+	},
+	{
+		Name:    "auth_listKeys",
+		Summary: "List the API keys and their usage",
+		Result:  types.ListKeysResponse{},
+		Level:   auth.AuthLevelMaster,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.listKeys(r) },
+	},
+	{
+		Name:    "auth_getExpiringKeys",
+		Summary: "List the API keys that expire soon",
+		Params:  types.GetExpiringKeysRequest{},
+		Result:  types.GetExpiringKeysResponse{},
+		Level:   auth.AuthLevelMaster,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.getExpiringKeys(r) },
+	},
+	{
+		Name:    "auth_getAuthMethod",
+		Summary: "Get the current auth method",
+		Result:  types.GetAuthMethodResponse{},
+		Level:   auth.AuthLevelMaster,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.getAuthMethod(r) },
+	},
+	{
+		Name:    "auth_getKeyType",
+		Summary: "Get the type of API keys used for auth (uuid, hex32, hex64 ...etc)",
+		Result:  types.GetKeyTypeResponse{},
+		Level:   auth.AuthLevelMaster,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.getKeyType(r) },
+	},
+
+	// watchlist
+	{
+		Name:    "admin_watchWallets",
+		Summary: "Add wallets to the watchlist, or update their label and threshold",
+		Params:  types.WatchWalletsRequest{},
+		Result:  types.WatchWalletsResponse{},
+		Level:   auth.AuthLevelMaster,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.watchWallets(r) },
+	},
+	{
+		Name:    "admin_unwatchWallets",
+		Summary: "Remove wallets from the watchlist",
+		Params:  types.UnwatchWalletsRequest{},
+		Result:  types.UnwatchWalletsResponse{},
+		Level:   auth.AuthLevelMaster,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.unwatchWallets(r) },
+	},
+	{
+		Name:    "admin_getWatchedWallets",
+		Summary: "List the watched wallets of a chain",
+		Params:  types.GetWatchedWalletsRequest{},
+		Result:  types.GetWatchedWalletsResponse{},
+		Level:   auth.AuthLevelMaster,
+		Handler: func(s *Server, r *JRPCRequest, _ string) Response { return s.getWatchedWallets(r) },
+	},
+}
+
+var methodsByName = indexMethods(methodRegistry)
+
+func indexMethods(methods []*rpcMethod) map[string]*rpcMethod {
+	index := make(map[string]*rpcMethod, len(methods))
+	for _, m := range methods {
+		index[m.Name] = m
+	}
+	return index
+}
+
+func notImplementedMethod(s *Server, r *JRPCRequest, _ string) Response {
+	return notImplemented(r)
+}
+
+// keyOwned guards the methods of resources owned by the api key of the call.
+// Without auth every caller shares the same owner, so they are refused.
+func keyOwned(h func(s *Server, r *JRPCRequest, key string) Response) func(s *Server, r *JRPCRequest, key string) Response {
+	return func(s *Server, r *JRPCRequest, key string) Response {
+		if _, noauth := s.auth.(*auth.NoAuthProvider); noauth || s.auth == nil || key == "" {
+			return &JRPCResponse{
+				ID:      r.ID,
+				JSONRPC: "2.0",
+				Error: &JRPCError{
+					Code:    -32800,
+					Message: errKeyRequired.Error(),
+				},
+			}
+		}
+
+		return h(s, r, key)
+	}
+}
+
+func websocketOnly(s *Server, r *JRPCRequest, _ string) Response {
+	return &JRPCResponse{
+		ID:      r.ID,
+		JSONRPC: "2.0",
+		Error: &JRPCError{
+			Code:    -32601,
+			Message: errWebsocketOnly.Error(),
+		},
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/autoapev1/indexer/auth"
+	"github.com/autoapev1/indexer/metrics"
+)
+
+// observeCall records a json-rpc call that took since start. Unknown methods
+// are recorded as "unknown" so clients can not add label values.
+func observeCall(method string, resp Response, start time.Time) {
+	if _, ok := methodsByName[method]; !ok {
+		method = "unknown"
+	}
+
+	status := callStatus(resp)
+	metrics.JRPCRequests.WithLabelValues(method, status).Inc()
+	metrics.JRPCDuration.WithLabelValues(method, status).Observe(time.Since(start).Seconds())
+}
+
+// callStatus is the error code of a response, "ok" without an error.
+func callStatus(resp Response) string {
+	v := reflect.ValueOf(resp)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "ok"
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return "ok"
+	}
+
+	e := v.FieldByName("Error")
+	if !e.IsValid() || e.Kind() != reflect.Ptr || e.IsNil() {
+		return "ok"
+	}
+
+	code := e.Elem().FieldByName("Code")
+	if !code.IsValid() || code.Kind() != reflect.Int64 {
+		return "error"
+	}
+
+	return strconv.FormatInt(code.Int(), 10)
+}
+
+// observeAuthFailure records a request refused by the auth provider.
+func observeAuthFailure(err error) {
+	reason := "error"
+	switch {
+	case errors.Is(err, auth.ErrUnauthorized), errors.Is(err, auth.ErrInvalidKey):
+		reason = "unauthorized"
+	case errors.Is(err, auth.ErrExpiredKey):
+		reason = "expired"
+	}
+
+	metrics.AuthFailures.WithLabelValues(reason).Inc()
+}
+
+// observeQuotaRejection records a call refused by the quotas of a tier.
+func observeQuotaRejection(err error) {
+	limit := "tier"
+	switch err {
+	case errDailyQuota:
+		limit = "daily"
+	case errMonthQuota:
+		limit = "monthly"
+	}
+
+	metrics.RateLimited.WithLabelValues(limit).Inc()
+}
@@ -3,9 +3,20 @@ package api
 import (
 	"context"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/autoapev1/indexer/auth"
+	"github.com/autoapev1/indexer/tracing"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func authMiddleware(a auth.Provider) func(next http.Handler) http.Handler {
@@ -16,17 +27,195 @@ func authMiddleware(a auth.Provider) func(next http.Handler) http.Handler {
 				return
 			}
 
-			level, err := a.Authenticate(r)
+			level, info, err := a.Authenticate(r)
 			if err != nil {
+				observeAuthFailure(err)
 				writeError(w, http.StatusUnauthorized, err)
 				return
 			}
 
 			ctx := r.Context()
 			ctx = context.WithValue(ctx, auth.AuthKey, level)
+			ctx = context.WithValue(ctx, auth.APIKey, auth.KeyFromRequest(r))
+			ctx = context.WithValue(ctx, auth.KeyInfoKey, info)
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		}
 		return http.HandlerFunc(fn)
 	}
 }
+
+// corsMiddleware lets browsers call the api from the api.corsOrigins, keys
+// with origin scopes are only accepted from their origins. Preflights carry
+// no key, so they are answered before auth.
+func (s *Server) corsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		if !s.allowedOrigin(r) {
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			// same origin requests need no headers, browsers refuse the others
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Authentication, Content-Type, Last-Event-ID, traceparent, tracestate")
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tracingMiddleware starts a server span for every request, continuing the
+// trace of the W3C traceparent header when there is one. The span is named
+// after the route once it is matched, so paths with parameters share a name.
+func tracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Tracer().Start(ctx, "HTTP "+r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", r.Method),
+				attribute.String("http.target", r.URL.Path),
+				attribute.String("net.peer.addr", r.RemoteAddr),
+			),
+		)
+		defer span.End()
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			span.SetName("HTTP " + r.Method + " " + rctx.RoutePattern())
+			span.SetAttributes(attribute.String("http.route", rctx.RoutePattern()))
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(attribute.Int("http.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	})
+}
+
+// allowedOrigin reports whether browsers on the Origin of r may call the api,
+// requests without an Origin and from the api host are always allowed.
+func (s *Server) allowedOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	for _, o := range s.config.API.CORSOrigins {
+		if o == "*" || o == origin {
+			return true
+		}
+	}
+
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// realIPMiddleware sets the remote address of requests relayed by one of the
+// trusted proxies to the client address they forwarded. Forwarding headers of
+// any other peer are ignored, as anyone can set them, so ip scopes and the ip
+// rate limit see the address that connected.
+func (s *Server) realIPMiddleware(next http.Handler) http.Handler {
+	trusted := parseTrustedProxies(s.config.API.TrustedProxies)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(trusted) > 0 && isTrusted(trusted, remoteIP(r.RemoteAddr)) {
+			if ip := forwardedIP(r, trusted); ip != "" {
+				r.RemoteAddr = ip
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// forwardedIP is the first address of X-Forwarded-For, from the right, that
+// is not a trusted proxy, or X-Real-IP when there is no X-Forwarded-For.
+func forwardedIP(r *http.Request, trusted []*net.IPNet) string {
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+
+		ip := ""
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+
+			ip = hop
+			if !isTrusted(trusted, hop) {
+				break
+			}
+		}
+		return ip
+	}
+
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(ip) != nil {
+		return ip
+	}
+
+	return ""
+}
+
+func isTrusted(trusted []*net.IPNet, ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+
+	for _, n := range trusted {
+		if n.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseTrustedProxies takes ips and cidrs, invalid entries are skipped.
+func parseTrustedProxies(proxies []string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, p := range proxies {
+		if !strings.Contains(p, "/") {
+			if ip := net.ParseIP(p); ip != nil {
+				bits := 8 * net.IPv6len
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 8*net.IPv4len
+				}
+				nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+		}
+
+		_, n, err := net.ParseCIDR(p)
+		if err != nil {
+			slog.Warn("invalid trusted proxy, skipping it", "proxy", p)
+			continue
+		}
+		nets = append(nets, n)
+	}
+
+	return nets
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/autoapev1/indexer/version"
+)
+
+// openAPI builds the OpenAPI 3 document of the REST gateway from restRoutes,
+// the schemas are read from the json tags of the result types.
+func openAPI() map[string]interface{} {
+	schemas := map[string]interface{}{}
+	paths := map[string]interface{}{}
+
+	for _, route := range restRoutes {
+		params := make([]interface{}, 0, len(route.Params))
+		for _, p := range route.Params {
+			params = append(params, map[string]interface{}{
+				"name":        p.Name,
+				"in":          p.In,
+				"required":    p.Required || p.In == "path",
+				"description": p.Description,
+				"schema":      map[string]interface{}{"type": p.Type},
+			})
+		}
+
+		responses := map[string]interface{}{
+			"200": map[string]interface{}{
+				"description": "OK",
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": schemaOf(route.Result, schemas),
+					},
+				},
+			},
+			"400": errorResponse("Invalid parameters"),
+			"401": errorResponse("Missing or invalid api key"),
+		}
+
+		if route.Single {
+			responses["404"] = errorResponse("Not found")
+		}
+
+		paths[route.Path] = map[string]interface{}{
+			"get": map[string]interface{}{
+				"operationId": route.OperationID,
+				"summary":     route.Summary,
+				"description": "Served by " + route.Method + ".",
+				"parameters":  params,
+				"responses":   responses,
+				"security":    []interface{}{map[string]interface{}{"apiKey": []interface{}{}}},
+			},
+		}
+	}
+
+	schemas["Error"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"error": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"code":    map[string]interface{}{"type": "integer"},
+					"message": map[string]interface{}{"type": "string"},
+				},
+			},
+		},
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Indexer REST API",
+			"version": version.Version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"apiKey": map[string]interface{}{
+					"type":         "http",
+					"scheme":       "bearer",
+					"description":  "The api key in the Authentication header",
+					"bearerFormat": "api key",
+				},
+			},
+		},
+	}
+}
+
+var jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+func errorResponse(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": map[string]interface{}{"$ref": "#/components/schemas/Error"},
+			},
+		},
+	}
+}
+
+// schemaOf returns the schema of t, named structs are added to schemas and
+// referenced.
+func schemaOf(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// custom encodings like raw json or big ints can be any value
+	if reflect.PointerTo(t).Implements(jsonMarshaler) {
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaOf(t.Elem(), schemas)}
+	case reflect.Struct:
+	default:
+		return map[string]interface{}{}
+	}
+
+	ref := map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	if _, ok := schemas[t.Name()]; ok {
+		return ref
+	}
+
+	// set before the fields so recursive types terminate
+	properties := map[string]interface{}{}
+	schemas[t.Name()] = map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name := f.Name
+		if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag != "" {
+			name = tag
+		}
+
+		if name == "-" {
+			continue
+		}
+
+		properties[name] = schemaOf(f.Type, schemas)
+	}
+
+	return ref
+}
+
+func handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, openAPI())
+}
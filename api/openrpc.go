@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/autoapev1/indexer/auth"
+	"github.com/autoapev1/indexer/version"
+)
+
+// openRPC builds the OpenRPC document of the json-rpc api from the method
+// registry, params are documented by name from the json tags of the request
+// types.
+func openRPC() map[string]interface{} {
+	schemas := map[string]interface{}{}
+	methods := make([]interface{}, 0, len(methodRegistry))
+
+	for _, m := range methodRegistry {
+		params := []interface{}{}
+		if m.Params != nil {
+			t := reflect.TypeOf(m.Params)
+			for i := 0; i < t.NumField(); i++ {
+				f := t.Field(i)
+				name := strings.Split(f.Tag.Get("json"), ",")[0]
+				if !f.IsExported() || name == "-" {
+					continue
+				}
+				if name == "" {
+					name = f.Name
+				}
+
+				params = append(params, map[string]interface{}{
+					"name":   name,
+					"schema": schemaOf(f.Type, schemas),
+				})
+			}
+		}
+
+		result := map[string]interface{}{}
+		if m.Result != nil {
+			if f, ok := reflect.TypeOf(m.Result).FieldByName("Result"); ok {
+				result = schemaOf(f.Type, schemas)
+			}
+		}
+
+		methods = append(methods, map[string]interface{}{
+			"name":           m.Name,
+			"summary":        m.Summary,
+			"paramStructure": "by-name",
+			"params":         params,
+			"result": map[string]interface{}{
+				"name":   "result",
+				"schema": result,
+			},
+			"x-auth-level": levelName(m.Level),
+			"x-cost":       cost(m),
+		})
+	}
+
+	return map[string]interface{}{
+		"openrpc": "1.2.6",
+		"info": map[string]interface{}{
+			"title":   "Indexer JSON-RPC API",
+			"version": version.Version,
+		},
+		"methods": methods,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+		},
+	}
+}
+
+func cost(m *rpcMethod) int {
+	if m.Cost > 0 {
+		return m.Cost
+	}
+	return 1
+}
+
+func levelName(lvl auth.AuthLevel) string {
+	switch lvl {
+	case auth.AuthLevelBasic:
+		return "basic"
+	case auth.AuthLevelMaster:
+		return "master"
+	default:
+		return "none"
+	}
+}
+
+func (s *Server) discover(r *JRPCRequest) *JRPCResponse {
+	result, err := json.Marshal(s.openrpc)
+	if err != nil {
+		return &JRPCResponse{
+			ID:      r.ID,
+			JSONRPC: "2.0",
+			Error: &JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+
+	return &JRPCResponse{
+		ID:      r.ID,
+		JSONRPC: "2.0",
+		Result:  result,
+	}
+}
@@ -4,64 +4,10 @@ import (
 	"log/slog"
 	"net/http"
 	"strconv"
-	"strings"
-	"sync"
-	"time"
-)
-
-type limiter struct {
-	count int
-	iat   int64
-}
-
-type rateLimiter struct {
-	lock     sync.Mutex
-	limit    int
-	lifetime time.Duration
-	limits   map[string]*limiter
-}
-
-func NewRateLimiter(limit int, lifetime time.Duration) *rateLimiter {
-	return &rateLimiter{
-		limits:   make(map[string]*limiter),
-		limit:    limit,
-		lifetime: lifetime,
-	}
-}
-
-func (r *rateLimiter) checkLimit(key string) (int, int64) {
-	r.lock.Lock()
-	defer r.lock.Unlock()
-
-	lim, ok := r.limits[key]
-	if !ok {
-		// create new limiter
-		lim = &limiter{
-			count: 0,
-			iat:   time.Now().Unix(),
-		}
-		r.limits[key] = lim
-		return r.limit, lim.iat + int64(r.lifetime.Seconds())
-	}
-
-	reset := lim.iat + int64(r.lifetime.Seconds())
 
-	// if the limiter is expired, reset it
-	if time.Unix(lim.iat, 0).Add(r.lifetime).Before(time.Now()) {
-		lim.count = 0
-		lim.iat = time.Now().Unix()
-		return r.limit, reset
-	}
-
-	// if rate limit is reached, return false
-	if lim.count >= r.limit {
-		return 0, reset
-	}
-
-	// incr the counter
-	lim.count++
-	return r.limit - lim.count, reset
-}
+	"github.com/autoapev1/indexer/auth"
+	"github.com/autoapev1/indexer/metrics"
+)
 
 type RateLimitStrategy string
 
@@ -85,10 +31,13 @@ func ToRateLimitStrategy(s string) RateLimitStrategy {
 	}
 }
 
+// rateLimitMiddleware refuses requests without a key when limiting by key.
+// Requests are charged per call with the cost of the method, by
+// handleJrpcRequest, so each element of a batch is charged separately.
 func (s *Server) rateLimitMiddleware(limit int, stratrgy string) func(next http.Handler) http.Handler {
 	strat := ToRateLimitStrategy(stratrgy)
 
-	if strat == RateLimitStrategyNone || limit <= 0 || s.rateLimit == nil {
+	if strat != RateLimitStrategyKey || limit <= 0 || s.rateLimit == nil {
 		return func(next http.Handler) http.Handler {
 			return next
 		}
@@ -96,40 +45,88 @@ func (s *Server) rateLimitMiddleware(limit int, stratrgy string) func(next http.
 
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
-
-			var k string
-
-			switch strat {
-			case RateLimitStrategyIP:
-				k = r.RemoteAddr
-
-			case RateLimitStrategyKey:
-				authHeader := r.Header.Get("Authentication")
-				authHeader = strings.TrimPrefix(authHeader, "Bearer ")
-				if authHeader == "" {
-					writeError(w, http.StatusUnauthorized, errMissingAuth)
-					return
-				}
-				k = authHeader
-			}
-
-			remaining, resetTime := s.rateLimit.checkLimit(k)
-			if remaining <= 0 {
-				writeJSON(w, http.StatusTooManyRequests, &JRPCResponse{
-					JSONRPC: "2.0",
-					Error: &JRPCError{
-						Code:    -32900,
-						Message: "Too Many Requests",
-					},
-				})
+			if auth.KeyFromRequest(r) == "" {
+				writeError(w, http.StatusUnauthorized, errMissingAuth)
 				return
 			}
-			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit))
-			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
-			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(resetTime, 10))
 			next.ServeHTTP(w, r)
-
 		}
 		return http.HandlerFunc(fn)
 	}
 }
+
+// limitKey returns the key the calls of c are charged to, or an empty key
+// when rate limiting is disabled.
+func (s *Server) limitKey(c *caller) string {
+	if s.rateLimit == nil {
+		return ""
+	}
+
+	switch ToRateLimitStrategy(s.config.API.RateLimitStrategy) {
+	case RateLimitStrategyIP:
+		return c.ip
+	case RateLimitStrategyKey:
+		return c.key
+	default:
+		return ""
+	}
+}
+
+// methodCost is the rate limit cost of a call of method, api.methodCosts
+// overrides the cost of the method registry.
+func (s *Server) methodCost(method string) int {
+	if cost, ok := s.config.API.MethodCosts[method]; ok && cost > 0 {
+		return cost
+	}
+
+	if m, ok := methodsByName[method]; ok && m.Cost > 0 {
+		return m.Cost
+	}
+
+	return 1
+}
+
+// charge charges cost to the rate limit of c, false if it is used up.
+func (s *Server) charge(c *caller, cost int) bool {
+	key := s.limitKey(c)
+	if key == "" {
+		return true
+	}
+
+	if !s.rateLimit.Allow(key, cost).Allowed {
+		metrics.RateLimited.WithLabelValues("rate").Inc()
+		return false
+	}
+
+	return true
+}
+
+// checkRateLimit charges a call to the rate limit of c, it returns an error
+// response if the limit is used up.
+func (s *Server) checkRateLimit(r *JRPCRequest, c *caller) *JRPCResponse {
+	if s.charge(c, s.methodCost(r.Method)) {
+		return nil
+	}
+
+	return &JRPCResponse{
+		ID:      r.ID,
+		JSONRPC: "2.0",
+		Error: &JRPCError{
+			Code:    -32900,
+			Message: errTooMany.Error(),
+		},
+	}
+}
+
+// writeRateLimitHeaders reports the rate limit of c after its calls.
+func (s *Server) writeRateLimitHeaders(w http.ResponseWriter, c *caller) {
+	key := s.limitKey(c)
+	if key == "" {
+		return
+	}
+
+	res := s.rateLimit.Peek(key)
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(res.Reset, 10))
+}
@@ -0,0 +1,371 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/autoapev1/indexer/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+var errNotFound = errors.New("not found")
+
+// restParam maps a path or query parameter onto a field of the json-rpc
+// params, field is a dotted path like filter.token0_address.
+type restParam struct {
+	Name        string
+	In          string // path | query
+	Type        string // string | integer | number | boolean
+	Field       string
+	Required    bool
+	Description string
+}
+
+// restRoute is a GET route of the REST gateway. It is served by the json-rpc
+// handler of Method, so the params are validated by the same types.*Request,
+// and the result is written without the json-rpc envelope.
+type restRoute struct {
+	Path        string
+	Method      string
+	OperationID string
+	Summary     string
+	Seed        string // initial params before the parameters are set
+	Params      []restParam
+	Result      reflect.Type
+	Single      bool // the result is the first row, 404 if there is none
+	MaxAge      int  // seconds a successful response may be cached
+}
+
+var (
+	chainParam = restParam{Name: "chainID", In: "path", Type: "integer", Field: "chain_id", Required: true, Description: "The blockchain network ID"}
+
+	offsetParam    = restParam{Name: "offset", In: "query", Type: "integer", Field: "options.offset", Description: "Rows to skip"}
+	limitParam     = restParam{Name: "limit", In: "query", Type: "integer", Field: "options.limit", Description: "Rows to return"}
+	sortByParam    = restParam{Name: "sort_by", In: "query", Type: "string", Field: "options.sort_by", Description: "Column to sort by"}
+	sortOrderParam = restParam{Name: "sort_order", In: "query", Type: "string", Field: "options.sort_order", Description: "asc or desc"}
+)
+
+var restRoutes = []*restRoute{
+	{
+		Path:        "/v1/chains",
+		Method:      "idx_getChains",
+		OperationID: "getChains",
+		Summary:     "List the indexed chains",
+		Result:      reflect.TypeOf([]types.Chain{}),
+		MaxAge:      300,
+	},
+	{
+		Path:        "/v1/chains/{chainID}/heights",
+		Method:      "idx_getHeights",
+		OperationID: "getHeights",
+		Summary:     "Get the sync heights of a chain",
+		Params:      []restParam{chainParam},
+		Result:      reflect.TypeOf(&types.Heights{}),
+		MaxAge:      3,
+	},
+	{
+		Path:        "/v1/chains/{chainID}/tokens",
+		Method:      "idx_findTokens",
+		OperationID: "findTokens",
+		Summary:     "Find tokens",
+		Seed:        `{"filter":{},"options":{"sort_by":"created_at","sort_order":"asc"}}`,
+		Params: []restParam{
+			chainParam,
+			{Name: "creator", In: "query", Type: "string", Field: "filter.creator", Description: "Deployer address"},
+			{Name: "name", In: "query", Type: "string", Field: "filter.name", Description: "Token name"},
+			{Name: "symbol", In: "query", Type: "string", Field: "filter.symbol", Description: "Token symbol"},
+			{Name: "decimals", In: "query", Type: "integer", Field: "filter.decimals", Description: "Token decimals"},
+			{Name: "from_block", In: "query", Type: "integer", Field: "filter.from_block", Description: "Created at or after this block"},
+			{Name: "to_block", In: "query", Type: "integer", Field: "filter.to_block", Description: "Created at or before this block"},
+			{Name: "fuzzy", In: "query", Type: "boolean", Field: "filter.fuzzy", Description: "Match substrings of name and symbol"},
+			offsetParam, limitParam, sortByParam, sortOrderParam,
+		},
+		Result: reflect.TypeOf([]*types.Token{}),
+		MaxAge: 30,
+	},
+	{
+		Path:        "/v1/chains/{chainID}/tokens/{address}",
+		Method:      "idx_findTokens",
+		OperationID: "getToken",
+		Summary:     "Get a token by address",
+		Params: []restParam{
+			chainParam,
+			{Name: "address", In: "path", Type: "string", Field: "filter.address", Required: true, Description: "Token address"},
+		},
+		Result: reflect.TypeOf(&types.Token{}),
+		Single: true,
+		MaxAge: 300,
+	},
+	{
+		Path:        "/v1/chains/{chainID}/tokens/{address}/markets",
+		Method:      "idx_getTokenMarkets",
+		OperationID: "getTokenMarkets",
+		Summary:     "Get the markets of a token grouped by quote token",
+		Params: []restParam{
+			chainParam,
+			{Name: "address", In: "path", Type: "string", Field: "address", Required: true, Description: "Token address"},
+			{Name: "min_liquidity_usd", In: "query", Type: "number", Field: "min_liquidity_usd", Description: "Minimum pool liquidity"},
+			{Name: "limit", In: "query", Type: "integer", Field: "limit", Description: "Markets to return per chain"},
+		},
+		Result: reflect.TypeOf([]*types.TokenMarketGroup{}),
+		MaxAge: 30,
+	},
+	{
+		Path:        "/v1/chains/{chainID}/pairs",
+		Method:      "idx_findPairs",
+		OperationID: "findPairs",
+		Summary:     "Find pairs",
+		Seed:        `{"options":{"sort_by":"created_at","sort_order":"asc"}}`,
+		Params: []restParam{
+			chainParam,
+			{Name: "token", In: "query", Type: "string", Field: "filter.token_address", Description: "Token0 or token1 address"},
+			{Name: "token0", In: "query", Type: "string", Field: "filter.token0_address", Description: "Token0 address"},
+			{Name: "token1", In: "query", Type: "string", Field: "filter.token1_address", Description: "Token1 address"},
+			{Name: "pool", In: "query", Type: "string", Field: "filter.pool_address", Description: "Pool address"},
+			{Name: "pool_type", In: "query", Type: "integer", Field: "filter.pool_type", Description: "Pool type"},
+			{Name: "fee", In: "query", Type: "integer", Field: "filter.fee", Description: "Pool fee"},
+			{Name: "tick_spacing", In: "query", Type: "integer", Field: "filter.tick_spacing", Description: "Pool tick spacing"},
+			{Name: "stable", In: "query", Type: "boolean", Field: "filter.stable", Description: "Stable pools only"},
+			{Name: "min_liquidity_usd", In: "query", Type: "number", Field: "filter.min_liquidity_usd", Description: "Minimum pool liquidity"},
+			{Name: "from_block", In: "query", Type: "integer", Field: "filter.from_block", Description: "Created at or after this block"},
+			{Name: "to_block", In: "query", Type: "integer", Field: "filter.to_block", Description: "Created at or before this block"},
+			offsetParam, limitParam, sortByParam, sortOrderParam,
+		},
+		Result: reflect.TypeOf([]*types.Pair{}),
+		MaxAge: 30,
+	},
+	{
+		Path:        "/v1/chains/{chainID}/pairs/{address}/reserves",
+		Method:      "idx_getPairReserves",
+		OperationID: "getPairReserves",
+		Summary:     "Get the reserves of a pool over a block range",
+		Params: []restParam{
+			chainParam,
+			{Name: "address", In: "path", Type: "string", Field: "pool_address", Required: true, Description: "Pool address"},
+			{Name: "from_block", In: "query", Type: "integer", Field: "from_block", Required: true, Description: "First block"},
+			{Name: "to_block", In: "query", Type: "integer", Field: "to_block", Required: true, Description: "Last block"},
+		},
+		Result: reflect.TypeOf([]*types.PairReserve{}),
+		MaxAge: 30,
+	},
+	{
+		Path:        "/v1/chains/{chainID}/blocks",
+		Method:      "idx_getBlocks",
+		OperationID: "getBlocks",
+		Summary:     "Get the block headers of a block range",
+		Params: []restParam{
+			chainParam,
+			{Name: "from_block", In: "query", Type: "integer", Field: "from_block", Required: true, Description: "First block"},
+			{Name: "to_block", In: "query", Type: "integer", Field: "to_block", Required: true, Description: "Last block"},
+		},
+		Result: reflect.TypeOf([]*types.Block{}),
+		MaxAge: 60,
+	},
+	{
+		Path:        "/v1/chains/{chainID}/blocks/at/{timestamp}",
+		Method:      "idx_getBlockAtTimestamp",
+		OperationID: "getBlockAtTimestamp",
+		Summary:     "Get the block closest to a unix timestamp",
+		Params: []restParam{
+			chainParam,
+			{Name: "timestamp", In: "path", Type: "integer", Field: "timestamp", Required: true, Description: "Unix timestamp"},
+		},
+		Result: reflect.TypeOf(&types.BlockTimestamp{}),
+		Single: true,
+		MaxAge: 60,
+	},
+}
+
+func (s *Server) initRestRoutes(r chi.Router) {
+	for _, route := range restRoutes {
+		r.Get(route.Path, makeAPIHandler(s.restHandler(route)))
+	}
+}
+
+func (s *Server) restHandler(route *restRoute) apiHandler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		c, ok := callerFromRequest(r)
+		if !ok {
+			return writeError(w, http.StatusInternalServerError, errInternalServer)
+		}
+
+		params, err := route.params(r)
+		if err != nil {
+			return writeJSON(w, http.StatusBadRequest, &JRPCResponse{
+				Error: &JRPCError{
+					Code:    -32602,
+					Message: err.Error(),
+				},
+			})
+		}
+
+		resp := s.handleJrpcRequest(&JRPCRequest{
+			ID:      middleware.GetReqID(r.Context()),
+			JSONRPC: "2.0",
+			Method:  route.Method,
+			Params:  params,
+			ctx:     r.Context(),
+		}, c)
+
+		s.writeQuotaHeaders(w, c)
+		s.writeRateLimitHeaders(w, c)
+
+		out, err := splitResponse(resp)
+		if err != nil {
+			return err
+		}
+
+		if out.Error != nil {
+			return writeJSON(w, restStatus(out.Error), &JRPCResponse{Error: out.Error})
+		}
+
+		result, found := route.result(out.Result)
+		if !found {
+			return writeJSON(w, http.StatusNotFound, &JRPCResponse{
+				Error: &JRPCError{
+					Code:    -32602,
+					Message: errNotFound.Error(),
+				},
+			})
+		}
+
+		// private, the response carries the rate limit and quota headers of
+		// the caller so shared caches must not serve it to anyone else
+		if route.MaxAge > 0 {
+			w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", route.MaxAge))
+			w.Header().Add("Vary", "Authentication")
+		}
+
+		return writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// splitResponse reads the result and error of a typed handler response, every
+// response type has the fields of a json-rpc response.
+func splitResponse(resp Response) (*JRPCResponse, error) {
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &JRPCResponse{}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// params builds the json-rpc params of the route from the request.
+func (route *restRoute) params(r *http.Request) (json.RawMessage, error) {
+	params := map[string]interface{}{}
+	if route.Seed != "" {
+		if err := json.Unmarshal([]byte(route.Seed), &params); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, p := range route.Params {
+		var raw string
+		if p.In == "path" {
+			raw = chi.URLParam(r, p.Name)
+		} else {
+			raw = r.URL.Query().Get(p.Name)
+		}
+
+		if raw == "" {
+			// the request validation reports missing required parameters
+			continue
+		}
+
+		v, err := p.parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter: %s", p.Name)
+		}
+
+		setField(params, p.Field, v)
+	}
+
+	return json.Marshal(params)
+}
+
+func (p restParam) parse(raw string) (interface{}, error) {
+	switch p.Type {
+	case "integer":
+		return strconv.ParseInt(raw, 10, 64)
+	case "number":
+		return strconv.ParseFloat(raw, 64)
+	case "boolean":
+		return strconv.ParseBool(raw)
+	default:
+		return raw, nil
+	}
+}
+
+// setField sets a dotted path of nested objects in m.
+func setField(m map[string]interface{}, field string, v interface{}) {
+	parts := strings.Split(field, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := m[part].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[part] = next
+		}
+		m = next
+	}
+
+	m[parts[len(parts)-1]] = v
+}
+
+// result returns the body of a successful response, empty results are
+// omitted by the json-rpc responses.
+func (route *restRoute) result(raw json.RawMessage) (json.RawMessage, bool) {
+	if !route.Single {
+		if len(raw) == 0 && route.Result.Kind() == reflect.Slice {
+			return json.RawMessage("[]"), true
+		}
+		return raw, true
+	}
+
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, false
+	}
+
+	if raw[0] != '[' {
+		return raw, true
+	}
+
+	var rows []json.RawMessage
+	if err := json.Unmarshal(raw, &rows); err != nil || len(rows) == 0 {
+		return nil, false
+	}
+
+	return rows[0], true
+}
+
+// restStatus maps a json-rpc error onto a http status.
+func restStatus(err *JRPCError) int {
+	switch err.Code {
+	case -32602:
+		if err.Message == errInternalServer.Error() {
+			return http.StatusInternalServerError
+		}
+		return http.StatusBadRequest
+	case -32601:
+		return http.StatusNotFound
+	case -32800:
+		return http.StatusUnauthorized
+	case -32900:
+		return http.StatusTooManyRequests
+	case -32701, -32702:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/autoapev1/indexer/pathfinder"
+	"github.com/autoapev1/indexer/storage"
+)
+
+const routesPageSize = 50000
+
+// initRoutes builds a route graph for every chain and keeps it up to date with
+// the pairs and reserves written by the syncer.
+func (s *Server) initRoutes() error {
+	if s.config.API.RoutesRefresh <= 0 {
+		slog.Warn("Routes refresh is not set, idx_findRoutes will be disabled")
+		return nil
+	}
+
+	interval := time.Duration(s.config.API.RoutesRefresh) * time.Second
+
+	s.routes = make(map[int64]*pathfinder.Graph)
+	for _, store := range s.stores.GetAll() {
+		g := pathfinder.NewGraph(store.GetChainID())
+		s.routes[store.GetChainID()] = g
+
+		go s.syncRoutes(s.ctx, store, g, interval)
+	}
+
+	return nil
+}
+
+// syncRoutes loads the graph of a chain, then refreshes it every interval
+// until ctx is done.
+func (s *Server) syncRoutes(ctx context.Context, store storage.Store, g *pathfinder.Graph, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		st := time.Now()
+		if err := s.loadRoutes(store, g); err != nil {
+			slog.Error("failed to load route graph", "chain_id", g.ChainID(), "err", err)
+		} else if s.debug {
+			tokens, pools := g.Size()
+			slog.Debug("route graph loaded", "chain_id", g.ChainID(), "tokens", tokens, "pools", pools, "took", time.Since(st))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// loadRoutes adds the pairs changed since the graph height, the last block is
+// read again as it may have been partially synced.
+func (s *Server) loadRoutes(store storage.Store, g *pathfinder.Graph) error {
+	block := g.Height() - 1
+
+	var (
+		afterCreatedAt int64
+		afterHash      string
+	)
+
+	for {
+		pairs, err := store.GetPairsSince(block, s.config.API.RoutesMinLiquidity, afterCreatedAt, afterHash, routesPageSize)
+		if err != nil {
+			return err
+		}
+
+		g.AddPairs(pairs)
+
+		if len(pairs) < routesPageSize {
+			return nil
+		}
+
+		last := pairs[len(pairs)-1]
+		afterCreatedAt, afterHash = last.CreatedAt, last.Hash
+	}
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+var (
+	errMethodNotAllowed = errors.New("method not allowed for this key")
+	errChainNotAllowed  = errors.New("chain_id not allowed for this key")
+	errOriginNotAllowed = errors.New("origin not allowed for this key")
+	errIPNotAllowed     = errors.New("ip not allowed for this key")
+)
+
+// checkScopes returns an error response if the key of the caller is not
+// scoped for the method, its chain_id, the origin or the ip, nil otherwise.
+func checkScopes(r *JRPCRequest, c *caller) *JRPCResponse {
+	if err := c.checkScopes(r.Method, r.Params); err != nil {
+		return &JRPCResponse{
+			ID:      r.ID,
+			JSONRPC: "2.0",
+			Error: &JRPCError{
+				Code:    -32800,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	return nil
+}
+
+// checkScopes checks a call of method with params against the scopes of the
+// key. A chain scoped key has to name an allowed chain_id on every method
+// that takes one.
+func (c *caller) checkScopes(method string, params json.RawMessage) error {
+	if err := c.checkOrigin(); err != nil {
+		return err
+	}
+
+	scopes := c.scopes
+	if !scopes.AllowsMethod(method) {
+		return errMethodNotAllowed
+	}
+
+	if scopes == nil || len(scopes.Chains) == 0 || !takesChainID(method) {
+		return nil
+	}
+
+	p := struct {
+		ChainID *int64 `json:"chain_id"`
+	}{}
+
+	if params == nil || json.Unmarshal(params, &p) != nil || p.ChainID == nil {
+		return errChainNotAllowed
+	}
+
+	if !scopes.AllowsChain(*p.ChainID) {
+		return errChainNotAllowed
+	}
+
+	return nil
+}
+
+// checkOrigin checks the ip and origin of the caller, the scopes that apply
+// to every endpoint.
+func (c *caller) checkOrigin() error {
+	if !c.scopes.AllowsIP(c.ip) {
+		return errIPNotAllowed
+	}
+
+	if !c.scopes.AllowsOrigin(c.origin) {
+		return errOriginNotAllowed
+	}
+
+	return nil
+}
+
+// takesChainID reports whether the params of a registered method have a
+// chain_id field.
+func takesChainID(method string) bool {
+	m, ok := methodsByName[method]
+	if !ok || m.Params == nil {
+		return false
+	}
+
+	t := reflect.TypeOf(m.Params)
+	for i := 0; i < t.NumField(); i++ {
+		if strings.Split(t.Field(i).Tag.Get("json"), ",")[0] == "chain_id" {
+			return true
+		}
+	}
+
+	return false
+}
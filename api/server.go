@@ -1,18 +1,30 @@
 package api
 
 import (
+	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/autoapev1/indexer/auth"
 	"github.com/autoapev1/indexer/config"
+	"github.com/autoapev1/indexer/eth"
+	"github.com/autoapev1/indexer/feed"
+	"github.com/autoapev1/indexer/metrics"
+	"github.com/autoapev1/indexer/pathfinder"
+	"github.com/autoapev1/indexer/ratelimit"
 	"github.com/autoapev1/indexer/storage"
+	"github.com/autoapev1/indexer/utils"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/graphql-go/graphql"
+	"google.golang.org/grpc"
 )
 
 type Server struct {
@@ -20,23 +32,53 @@ type Server struct {
 	config    config.Config
 	stores    *storage.StoreMap
 	auth      auth.Provider
-	rateLimit *rateLimiter
+	rateLimit ratelimit.Limiter
+	routes    map[int64]*pathfinder.Graph
+	feed      *feed.Hub
+	graphql   *graphql.Schema
+	openrpc   map[string]interface{}
+	usage     *auth.UsageMeter
+	quotas    *quotaTracker
+	networks  map[int64]*eth.Network // rpc clients of readiness checks, nil if one failed to dial
+	ready     readyCache
 	debug     bool
+
+	http    *http.Server
+	metrics *http.Server // nil unless metrics have their own port
+	grpc    *grpc.Server // nil if the grpc port is not set
+
+	// done when the server shuts down, stops the background loops
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // NewServer returns a new server given a Store interface.
 func NewServer(conf config.Config, stores *storage.StoreMap) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+
 	return &Server{
-		config: conf,
-		stores: stores,
-		debug:  true,
+		config:  conf,
+		stores:  stores,
+		openrpc: openRPC(),
+		debug:   true,
+		ctx:     ctx,
+		cancel:  cancel,
 	}
 }
 
 func (s *Server) initAuthProvider() error {
 	conf := config.Get()
 	authProviderType := auth.ToProvider(conf.API.AuthProvider)
+	keyType := auth.ToKeyType(conf.API.AuthKeyType)
+	expiry := time.Duration(conf.API.AuthDefaultExpirary) * time.Second
 	var authProvider auth.Provider
+
+	// jwt keys can only be verified by the jwt provider
+	if keyType == auth.KeyTypeJWT && authProviderType != auth.AuthProviderJWT {
+		slog.Warn("jwt keys need the jwt auth provider, using it", "provider", authProviderType)
+		authProviderType = auth.AuthProviderJWT
+	}
+
 	switch authProviderType {
 
 	case auth.AuthProviderSql:
@@ -47,11 +89,35 @@ func (s *Server) initAuthProvider() error {
 			conf.Storage.Postgres.Name,
 			conf.Storage.Postgres.SSLMode)
 		db := auth.NewSqlDB(uri)
-		authProvider = auth.NewSqlAuthProvider(db)
+		authProvider = auth.NewSqlAuthProvider(db).
+			WithKeyType(keyType).
+			WithDefaultExpiry(expiry)
 
 	case auth.AuthProviderMemory:
 
-		authProvider = auth.NewMemoryProvider()
+		authProvider = auth.NewMemoryProvider().
+			WithKeyType(keyType).
+			WithDefaultExpiry(expiry)
+
+	case auth.AuthProviderJWT:
+
+		jwtProvider, err := auth.NewJWTProvider(auth.JWTOptions{
+			Algorithm: conf.API.AuthJWTAlgorithm,
+			Secret:    conf.API.AuthJWTSecret,
+			KeyFile:   conf.API.AuthJWTKeyFile,
+			KeyID:     conf.API.AuthJWTKeyID,
+			JWKSFile:  conf.API.AuthJWKSFile,
+			Issuer:    conf.API.AuthJWTIssuer,
+
+			MaxLifetime: time.Duration(conf.API.AuthJWTMaxLifetime) * time.Second,
+		})
+		if err != nil {
+			return err
+		}
+		authProvider = jwtProvider.WithDefaultExpiry(expiry)
+
+		s.config.API.AuthProvider = string(auth.AuthProviderJWT)
+		s.config.API.AuthKeyType = auth.KeyTypeJWTString
 
 	case auth.AuthProviderNoAuth:
 
@@ -70,11 +136,22 @@ func (s *Server) initRateLimiter() error {
 
 	if conf.API.RateLimitRequests <= 0 {
 		slog.Warn("Rate limit requests is not set, rate limiting will be disabled")
+		return nil
+	}
+
+	if ToRateLimitStrategy(conf.API.RateLimitStrategy) == RateLimitStrategyNone {
+		return nil
+	}
+
+	limiter, err := ratelimit.New(ratelimit.Algorithm(conf.API.RateLimitAlgorithm), conf.API.RateLimitRequests, time.Minute)
+	if err != nil {
+		return err
 	}
 
-	lifetime := time.Minute
-	rLimiter := NewRateLimiter(conf.API.RateLimitRequests, lifetime)
-	s.rateLimit = rLimiter
+	// idle keys are dropped so the limiter does not grow with every ip seen
+	go ratelimit.RunEviction(s.ctx, limiter, time.Minute)
+
+	s.rateLimit = limiter
 	return nil
 }
 
@@ -85,14 +162,97 @@ func (s *Server) Listen(addr string) error {
 		return err
 	}
 
+	if err := s.initUsage(); err != nil {
+		return err
+	}
+
+	if err := s.initTiers(); err != nil {
+		return err
+	}
+
 	if err := s.initRateLimiter(); err != nil {
 		return err
 	}
 
+	if err := s.initRoutes(); err != nil {
+		return err
+	}
+
+	if err := s.initFeed(); err != nil {
+		return err
+	}
+
+	if err := s.initGraphQL(); err != nil {
+		return err
+	}
+
+	if err := s.initGRPC(); err != nil {
+		return err
+	}
+
+	if err := s.initHealth(); err != nil {
+		return err
+	}
+
+	if err := s.initMetrics(); err != nil {
+		return err
+	}
+
 	s.initRouter()
 
+	s.http = &http.Server{
+		Addr:    addr,
+		Handler: s.router,
+	}
+
 	fmt.Printf("API Server Listening on: \t%s\n", addr)
-	return http.ListenAndServe(addr, s.router)
+	if err := s.http.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// Shutdown stops accepting connections and waits for the calls in flight
+// over http and grpc until ctx is done, then stops the background loops of
+// the server, the route graphs, the feed and the rate limiter eviction, and
+// writes the buffered key usage.
+// Long-lived streams are cut when ctx is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	var err error
+
+	if s.grpc != nil {
+		stopped := make(chan struct{})
+		go func() {
+			s.grpc.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			s.grpc.Stop()
+		}
+	}
+
+	if s.http != nil {
+		if err = s.http.Shutdown(ctx); err != nil {
+			_ = s.http.Close()
+		}
+	}
+
+	if s.metrics != nil {
+		_ = s.metrics.Shutdown(ctx)
+	}
+
+	s.cancel()
+
+	// usage seeds the quotas of the next start
+	if s.usage != nil {
+		s.usage.Flush()
+	}
+
+	return err
 }
 
 func (s *Server) initRouter() {
@@ -100,8 +260,11 @@ func (s *Server) initRouter() {
 
 	// middleware
 	s.router.Use(middleware.RequestID)
+	s.router.Use(sseKeyMiddleware)
 	s.router.Use(middleware.Logger)
-	s.router.Use(middleware.RealIP)
+	s.router.Use(s.realIPMiddleware)
+	s.router.Use(tracingMiddleware)
+	s.router.Use(s.corsMiddleware)
 
 	// auth middleware and routes
 	s.router.Group(func(r chi.Router) {
@@ -109,13 +272,78 @@ func (s *Server) initRouter() {
 		r.Use(s.rateLimitMiddleware(s.config.API.RateLimitRequests, s.config.API.RateLimitStrategy))
 
 		r.Post("/", makeAPIHandler(s.handlePost))
+		r.Get("/ws", makeAPIHandler(s.handleWS))
+
+		r.Get("/graphql", makeAPIHandler(s.handleGraphQL))
+		r.Post("/graphql", makeAPIHandler(s.handleGraphQL))
+
+		// rest gateway
+		s.initRestRoutes(r)
+	})
+
+	// server-sent events, the key can also be passed in the query
+	s.router.Group(func(r chi.Router) {
+		r.Use(authMiddleware(s.auth))
+		r.Use(s.rateLimitMiddleware(s.config.API.RateLimitRequests, s.config.API.RateLimitStrategy))
+
+		r.Get(sseEventsPath, makeAPIHandler(s.handlePairEvents))
 	})
 
+	// liveness and readiness probes
 	s.router.Get("/status", handleStatus)
+	s.router.Get("/healthz", handleStatus)
+	s.router.Get("/readyz", makeAPIHandler(s.handleReady))
+	s.router.Get("/openapi.json", handleOpenAPI)
+
+	// metrics name chains, methods and keys' tiers, without their own port
+	// only the master key can read them
+	if s.config.API.Metrics && s.config.API.MetricsPort <= 0 {
+		s.router.With(s.masterKeyOnly).Handle("/metrics", metrics.Handler())
+	}
 	s.router.Get("/", makeAPIHandler(s.handleGet))
 
 }
 
+// initMetrics serves /metrics on its own port when one is set, so it can be
+// kept off the public network.
+func (s *Server) initMetrics() error {
+	if !s.config.API.Metrics || s.config.API.MetricsPort <= 0 {
+		return nil
+	}
+
+	addr := utils.ToAddress(s.config.API.Host, s.config.API.MetricsPort)
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	s.metrics = &http.Server{Handler: mux}
+
+	fmt.Printf("Metrics Listening on: \t\t%s\n", addr)
+	go func() {
+		if err := s.metrics.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics server stopped", "err", err)
+		}
+	}()
+
+	return nil
+}
+
+// masterKeyOnly refuses requests without the master key.
+func (s *Server) masterKeyOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		master := s.config.API.AuthMasterKey
+		key := auth.KeyFromRequest(r)
+		if master == "" || subtle.ConstantTimeCompare([]byte(key), []byte(master)) != 1 {
+			http.Error(w, auth.ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func handleStatus(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -126,33 +354,19 @@ func handleStatus(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handlePost(w http.ResponseWriter, r *http.Request) error {
-	isBatch := true
 	// read body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		return writeError(w, http.StatusBadRequest, errReadingBody)
 	}
 
-	// check if body is empty
-	if len(body) == 0 {
-		return writeError(w, http.StatusBadRequest, errMissingBody)
-	}
-
-	if !(body[0] == '[' && body[len(body)-1] == ']') {
-		body = append([]byte("["), body...)
-		body = append(body, ']')
-		isBatch = false
-	}
-
-	// unmarshal the request
-	var reqs []*JRPCRequest
-	if err := json.Unmarshal(body, &reqs); err != nil {
-		slog.Error("failed to unmarshal request", "err", err)
-		return writeError(w, http.StatusBadRequest, errUnmarshalRequest)
+	reqs, isBatch, err := parseRequests(body)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, err)
 	}
 
-	authLevel := r.Context().Value(auth.AuthKey).(auth.AuthLevel)
-	if !auth.IsValidAuthLevel(authLevel) {
+	c, ok := callerFromRequest(r)
+	if !ok {
 		return writeJSON(w, http.StatusInternalServerError, &JRPCResponse{
 			Error: &JRPCError{
 				Code:    -32600,
@@ -161,20 +375,65 @@ func (s *Server) handlePost(w http.ResponseWriter, r *http.Request) error {
 		})
 	}
 
+	if err := s.checkBatch(c, len(reqs)); err != nil {
+		return writeJSON(w, http.StatusBadRequest, &JRPCResponse{
+			JSONRPC: "2.0",
+			Error: &JRPCError{
+				Code:    -32600,
+				Message: err.Error(),
+			},
+		})
+	}
+
+	ctx := r.Context()
+
 	var resp []Response
 	// range over the requests and handle them
 	for _, r := range reqs {
-		response := s.handleJrpcRequest(r, authLevel)
+		r.ctx = ctx
+		response := s.handleJrpcRequest(r, c)
 		resp = append(resp, response)
 	}
 
+	s.writeQuotaHeaders(w, c)
+	s.writeRateLimitHeaders(w, c)
+
 	if len(resp) == 1 && !isBatch {
+		if out, err := splitResponse(resp[0]); err == nil && out.Error != nil && out.Error.Code == -32900 {
+			return writeJSON(w, http.StatusTooManyRequests, resp[0])
+		}
 		return writeJSON(w, http.StatusOK, resp[0])
 	}
 
 	return writeJSON(w, http.StatusOK, resp)
 }
 
+// parseRequests decodes a single request or a batch, isBatch tells how the
+// responses should be written.
+func parseRequests(body []byte) ([]*JRPCRequest, bool, error) {
+	isBatch := true
+
+	// check if body is empty
+	if len(body) == 0 {
+		return nil, false, errMissingBody
+	}
+
+	if !(body[0] == '[' && body[len(body)-1] == ']') {
+		body = append([]byte("["), body...)
+		body = append(body, ']')
+		isBatch = false
+	}
+
+	// unmarshal the request
+	var reqs []*JRPCRequest
+	if err := json.Unmarshal(body, &reqs); err != nil {
+		slog.Error("failed to unmarshal request", "err", err)
+		return nil, false, errUnmarshalRequest
+	}
+
+	return reqs, isBatch, nil
+}
+
 func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) error {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
@@ -0,0 +1,255 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/autoapev1/indexer/auth"
+	"github.com/autoapev1/indexer/feed"
+	"github.com/autoapev1/indexer/storage"
+	"github.com/autoapev1/indexer/types"
+)
+
+const (
+	// sseMaxReplay is the most missed pairs sent on a Last-Event-ID resume
+	// or after the subscription dropped a batch.
+	sseMaxReplay  = 10000
+	sseReplayPage = 1000
+	ssePing       = 15 * time.Second
+
+	sseEventsPath = "/events/pairs"
+)
+
+// sseKeyMiddleware lets EventSource clients, which can not set headers, pass
+// their api key as the key query parameter. It runs before the logger and
+// removes the key from the url so it is not written to the access log.
+func sseKeyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == sseEventsPath {
+			q := r.URL.Query()
+			if q.Has("key") {
+				if key := q.Get("key"); key != "" && r.Header.Get("Authentication") == "" {
+					r.Header.Set("Authentication", "Bearer "+key)
+				}
+
+				q.Del("key")
+				r.URL.RawQuery = q.Encode()
+				r.RequestURI = r.URL.RequestURI()
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handlePairEvents streams new pairs as server-sent events, the event id is
+// the pair seq so a reconnecting client resumes after the last pair it got.
+func (s *Server) handlePairEvents(w http.ResponseWriter, r *http.Request) error {
+	// same access as the idx_ methods
+	level, _ := r.Context().Value(auth.AuthKey).(auth.AuthLevel)
+	if !hasAccess(MethodIdx, level) {
+		return writeError(w, http.StatusUnauthorized, auth.ErrUnauthorized)
+	}
+
+	req, err := parsePairEventsRequest(r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return writeError(w, http.StatusBadRequest, err)
+	}
+
+	// same scopes as a newPairs subscription
+	if c, ok := callerFromRequest(r); ok {
+		if !s.charge(c, s.methodCost("idx_subscribe")) {
+			return writeError(w, http.StatusTooManyRequests, errTooMany)
+		}
+		params, _ := json.Marshal(map[string]int64{"chain_id": *req.ChainID})
+		if err := c.checkScopes("idx_subscribe", params); err != nil {
+			return writeError(w, http.StatusForbidden, err)
+		}
+		if resp := s.checkTier(&JRPCRequest{Method: "idx_subscribe", Params: params}, c); resp != nil {
+			return writeError(w, http.StatusForbidden, errors.New(resp.Error.Message))
+		}
+	}
+
+	store := s.stores.GetStore(*req.ChainID)
+	if store == nil {
+		return writeError(w, http.StatusBadRequest, errors.New("invalid chain_id"))
+	}
+
+	if s.feed == nil {
+		return writeError(w, http.StatusServiceUnavailable, errFeedDisabled)
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return errors.New("response writer does not support flushing")
+	}
+
+	// subscribe before the replay so no pair is missed in between
+	sub := s.feed.Subscribe(64)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	last := req.LastEventID
+	if last > 0 {
+		if last, err = s.replayPairs(w, store, req, last); err != nil {
+			return nil
+		}
+		flusher.Flush()
+	}
+
+	ping := time.NewTicker(ssePing)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+
+		case <-ping.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return nil
+			}
+			flusher.Flush()
+
+		case events, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+
+			// pairs of the dropped batches are read from the store, the
+			// ones also in events are skipped by their seq
+			if sub.Missed() {
+				if last, err = s.replayPairs(w, store, req, last); err != nil {
+					return nil
+				}
+			}
+
+			for _, e := range events {
+				if e.ChainID != *req.ChainID || e.Kind != feed.KindPair || e.Seq <= last {
+					continue
+				}
+
+				last = e.Seq
+				if !req.Match(e.Pair) {
+					continue
+				}
+
+				if err := writeEvent(w, e.Pair); err != nil {
+					return nil
+				}
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// replayPairs writes the pairs after last from the store, up to
+// sseMaxReplay. If more were missed a resync event tells the client to reload
+// what it needs, the stream then continues with the latest pairs. It returns
+// the seq of the last pair sent or skipped.
+func (s *Server) replayPairs(w http.ResponseWriter, store storage.Store, req *types.PairEventsRequest, last int64) (int64, error) {
+	for replayed := 0; replayed < sseMaxReplay; replayed += sseReplayPage {
+		pairs, err := store.GetPairsAfterSeq(last, sseReplayPage)
+		if err != nil {
+			if s.debug {
+				slog.Error("failed to replay pairs", "err", err)
+			}
+			return last, err
+		}
+
+		for _, p := range pairs {
+			last = p.Seq
+			if req.Match(p) {
+				if err := writeEvent(w, p); err != nil {
+					return last, err
+				}
+			}
+		}
+
+		if len(pairs) < sseReplayPage {
+			return last, nil
+		}
+	}
+
+	latest, _, err := store.GetLatestSeqs()
+	if err != nil {
+		if s.debug {
+			slog.Error("failed to get latest pair seq", "err", err)
+		}
+		return last, err
+	}
+
+	if latest <= last {
+		return last, nil
+	}
+
+	// the id moves past the gap so a reconnect does not replay it again
+	_, err = fmt.Fprintf(w, "id: %d\nevent: resync\ndata: {\"last_event_id\":%d,\"latest_event_id\":%d}\n\n", latest, last, latest)
+	return latest, err
+}
+
+func writeEvent(w http.ResponseWriter, p *types.Pair) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: pair\ndata: %s\n\n", p.Seq, data)
+	return err
+}
+
+func parsePairEventsRequest(r *http.Request) (*types.PairEventsRequest, error) {
+	q := r.URL.Query()
+	req := &types.PairEventsRequest{}
+
+	if v := q.Get("chain_id"); v != "" {
+		chainID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, errors.New("invalid parameter: chain_id")
+		}
+		req.ChainID = &chainID
+	}
+
+	if v := q.Get("token"); v != "" {
+		req.Token = &v
+	}
+
+	if v := q.Get("pool_type"); v != "" {
+		poolType, err := strconv.ParseUint(v, 10, 8)
+		if err != nil {
+			return nil, errors.New("invalid parameter: pool_type")
+		}
+		pt := uint8(poolType)
+		req.PoolType = &pt
+	}
+
+	// browsers send the header on reconnect, the query allows a manual resume
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = q.Get("last_event_id")
+	}
+
+	if lastID != "" {
+		seq, err := strconv.ParseInt(lastID, 10, 64)
+		if err != nil {
+			return nil, errors.New("invalid Last-Event-ID")
+		}
+		req.LastEventID = seq
+	}
+
+	return req, nil
+}
@@ -0,0 +1,510 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/autoapev1/indexer/auth"
+	"github.com/autoapev1/indexer/config"
+	"github.com/autoapev1/indexer/ratelimit"
+	"github.com/autoapev1/indexer/types"
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	errTierMethod = errors.New("method not allowed for this key's tier")
+	errDailyQuota = errors.New("daily quota exceeded")
+	errMonthQuota = errors.New("monthly quota exceeded")
+	errTooMany    = errors.New("Too Many Requests")
+)
+
+// monthFormat is the layout of the quota months, in UTC.
+const monthFormat = "2006-01"
+
+// quotaTracker counts the calls of each key today and this month. Counts are
+// seeded from the metered daily usage of the auth provider the first time a
+// key is seen, so quotas survive restarts up to the usage flushed before.
+// Shutdown flushes the meter, a killed server loses the calls of the last
+// api.usageFlushInterval.
+type quotaTracker struct {
+	provider auth.Provider
+
+	lock   sync.Mutex
+	keys   map[string]*quota
+	minute map[string]ratelimit.Limiter // by tier
+
+	// loads of the usage of a key from the provider, one per key at a time
+	// and outside of lock
+	loads singleflight.Group
+}
+
+type quota struct {
+	month      string // monthFormat
+	day        string // auth.DayFormat
+	monthCount int64
+	dayCount   int64
+}
+
+func newQuotaTracker(ctx context.Context, provider auth.Provider, tiers []config.TierConfig, algorithm ratelimit.Algorithm) (*quotaTracker, error) {
+	q := &quotaTracker{
+		provider: provider,
+		keys:     make(map[string]*quota),
+		minute:   make(map[string]ratelimit.Limiter),
+	}
+
+	for _, t := range tiers {
+		if t.RequestsPerMinute <= 0 {
+			continue
+		}
+
+		limiter, err := ratelimit.New(algorithm, t.RequestsPerMinute, time.Minute)
+		if err != nil {
+			return nil, err
+		}
+
+		go ratelimit.RunEviction(ctx, limiter, time.Minute)
+		q.minute[t.Name] = limiter
+	}
+
+	return q, nil
+}
+
+// initTiers checks the tiers of the config and starts counting quotas.
+func (s *Server) initTiers() error {
+	api := s.config.API
+	if len(api.Tiers) == 0 {
+		return nil
+	}
+
+	names := make(map[string]bool, len(api.Tiers))
+	for _, t := range api.Tiers {
+		if t.Name == "" || names[t.Name] {
+			return fmt.Errorf("invalid tier name: %q", t.Name)
+		}
+		names[t.Name] = true
+	}
+
+	if api.DefaultTier != "" && !names[api.DefaultTier] {
+		return fmt.Errorf("default tier %q is not defined", api.DefaultTier)
+	}
+
+	if s.usage == nil {
+		slog.Warn("Key usage is not metered, tier quotas will reset on restart")
+	}
+
+	quotas, err := newQuotaTracker(s.ctx, s.auth, api.Tiers, ratelimit.Algorithm(api.RateLimitAlgorithm))
+	if err != nil {
+		return err
+	}
+
+	s.quotas = quotas
+	return nil
+}
+
+// tierOf returns the tier of the key of c, nil if it has none. Keys with a
+// tier that is no longer configured fall back to the default tier.
+func (s *Server) tierOf(c *caller) *config.TierConfig {
+	if s.quotas == nil || c.key == "" || c.level >= auth.AuthLevelMaster {
+		return nil
+	}
+
+	if c.tier != "" {
+		if t := s.config.API.Tier(c.tier); t != nil {
+			return t
+		}
+	}
+
+	return s.config.API.Tier(s.config.API.DefaultTier)
+}
+
+// checkTier returns an error response if the tier of the key of c does not
+// allow the call or its quota is used up, nil otherwise. Limits above the max
+// limit of the tier are refused, and omitted limits are lowered to it.
+func (s *Server) checkTier(r *JRPCRequest, c *caller) *JRPCResponse {
+	t := s.tierOf(c)
+	if t == nil {
+		return nil
+	}
+
+	if !(&types.KeyScopes{Methods: t.Methods}).AllowsMethod(r.Method) {
+		return tierError(r, -32800, errTierMethod)
+	}
+
+	at, err := s.quotas.check(c.key, t, s.methodCost(r.Method), true)
+	if err != nil {
+		observeQuotaRejection(err)
+		return tierError(r, -32900, err)
+	}
+
+	if err := applyMaxLimit(r, t.MaxLimit); err != nil {
+		s.quotas.refund(c.key, at)
+		return tierError(r, -32602, err)
+	}
+
+	r.reserved = at
+	return nil
+}
+
+// checkBatch returns an error if a batch of n calls is larger than the tier
+// of the key of c allows.
+func (s *Server) checkBatch(c *caller, n int) error {
+	t := s.tierOf(c)
+	if t == nil || t.MaxBatchSize <= 0 || n <= t.MaxBatchSize {
+		return nil
+	}
+
+	return fmt.Errorf("batch size must be less than or equal to %d for this key's tier", t.MaxBatchSize)
+}
+
+func tierError(r *JRPCRequest, code int64, err error) *JRPCResponse {
+	return &JRPCResponse{
+		ID:      r.ID,
+		JSONRPC: "2.0",
+		Error: &JRPCError{
+			Code:    code,
+			Message: err.Error(),
+		},
+	}
+}
+
+// writeQuotaHeaders reports the monthly quota of the key of c.
+func (s *Server) writeQuotaHeaders(w http.ResponseWriter, c *caller) {
+	for k, v := range s.quotaHeaders(c) {
+		w.Header().Set(k, v)
+	}
+}
+
+func (s *Server) quotaHeaders(c *caller) map[string]string {
+	t := s.tierOf(c)
+	if t == nil || t.RequestsPerMonth <= 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	used := s.quotas.used(c.key, now)
+
+	remaining := t.RequestsPerMonth - used
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	reset := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+
+	return map[string]string{
+		"X-Quota-Limit":     strconv.FormatInt(t.RequestsPerMonth, 10),
+		"X-Quota-Remaining": strconv.FormatInt(remaining, 10),
+		"X-Quota-Reset":     strconv.FormatInt(reset.Unix(), 10),
+	}
+}
+
+// check returns an error if the key is over a quota of its tier, cost is
+// charged to the per minute limit. With reserve the call is counted under the
+// same lock as the quotas are read, so concurrent calls can not overshoot
+// them, and the time it was counted at is returned to refund it.
+func (q *quotaTracker) check(key string, t *config.TierConfig, cost int, reserve bool) (time.Time, error) {
+	now := time.Now().UTC()
+	q.ensure(key, now)
+
+	q.lock.Lock()
+	u := q.load(key, now)
+
+	if t.RequestsPerDay > 0 && u.dayCount >= t.RequestsPerDay {
+		q.lock.Unlock()
+		return time.Time{}, errDailyQuota
+	}
+
+	if t.RequestsPerMonth > 0 && u.monthCount >= t.RequestsPerMonth {
+		q.lock.Unlock()
+		return time.Time{}, errMonthQuota
+	}
+
+	if reserve {
+		u.dayCount++
+		u.monthCount++
+	}
+	q.lock.Unlock()
+
+	if lim, ok := q.minute[t.Name]; ok {
+		if !lim.Allow(key, cost).Allowed {
+			if reserve {
+				q.refund(key, now)
+			}
+			return time.Time{}, errTooMany
+		}
+	}
+
+	if !reserve {
+		return time.Time{}, nil
+	}
+
+	return now, nil
+}
+
+// refund takes back a call of key counted by check at at. Calls counted on a
+// previous day or month are already out of the counts.
+func (q *quotaTracker) refund(key string, at time.Time) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	u, ok := q.keys[key]
+	if !ok {
+		return
+	}
+
+	if u.day == at.Format(auth.DayFormat) && u.dayCount > 0 {
+		u.dayCount--
+	}
+
+	if u.month == at.Format(monthFormat) && u.monthCount > 0 {
+		u.monthCount--
+	}
+}
+
+// add counts a metered call of key.
+func (q *quotaTracker) add(key string, at time.Time) {
+	q.ensure(key, at.UTC())
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	u := q.load(key, at.UTC())
+	u.dayCount++
+	u.monthCount++
+}
+
+// used returns the calls of key this month.
+func (q *quotaTracker) used(key string, now time.Time) int64 {
+	q.ensure(key, now)
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.load(key, now).monthCount
+}
+
+// ensure reads the usage of key this month from the provider the first time
+// the key is seen. Concurrent calls for a key wait for a single read, which
+// runs without q.lock so other keys are not held up by the provider.
+func (q *quotaTracker) ensure(key string, now time.Time) {
+	q.lock.Lock()
+	_, ok := q.keys[key]
+	q.lock.Unlock()
+
+	if ok {
+		return
+	}
+
+	_, _, _ = q.loads.Do(key, func() (interface{}, error) {
+		u := q.fetch(key, now)
+
+		q.lock.Lock()
+		if _, ok := q.keys[key]; !ok {
+			q.keys[key] = u
+		}
+		q.lock.Unlock()
+
+		return nil, nil
+	})
+}
+
+// fetch reads the counts of key at now from the metered daily usage.
+func (q *quotaTracker) fetch(key string, now time.Time) *quota {
+	u := &quota{month: now.Format(monthFormat), day: now.Format(auth.DayFormat)}
+
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	days, err := q.provider.GetDailyUsage(key, start.Format(auth.DayFormat))
+	if err != nil && err != auth.ErrInvalidKey {
+		slog.Error("failed to load key usage", "err", err)
+	}
+
+	for _, d := range days {
+		u.monthCount += d.Requests
+		if d.Day == u.day {
+			u.dayCount += d.Requests
+		}
+	}
+
+	return u
+}
+
+// load returns the counts of key at now, starting over on a new day or
+// month. ensure must have been called for key and q.lock must be held.
+func (q *quotaTracker) load(key string, now time.Time) *quota {
+	month := now.Format(monthFormat)
+	day := now.Format(auth.DayFormat)
+
+	u, ok := q.keys[key]
+	if !ok {
+		u = &quota{month: month, day: day}
+		q.keys[key] = u
+		return u
+	}
+
+	if u.month != month {
+		u.month = month
+		u.monthCount = 0
+	}
+
+	if u.day != day {
+		u.day = day
+		u.dayCount = 0
+	}
+
+	return u
+}
+
+// applyMaxLimit refuses a limit above max in the params of r and lowers an
+// omitted limit whose default is above max, for methods that take a limit.
+func applyMaxLimit(r *JRPCRequest, max int64) error {
+	m, ok := methodsByName[r.Method]
+	if max <= 0 || !ok || m.Params == nil {
+		return nil
+	}
+
+	path := limitPath(reflect.TypeOf(m.Params))
+	if path == nil {
+		return nil
+	}
+
+	// the limit after defaults, invalid params are left to the handler
+	params := r.Params
+	if params == nil {
+		params = json.RawMessage("{}")
+	}
+
+	req := reflect.New(reflect.TypeOf(m.Params))
+	if err := json.Unmarshal(params, req.Interface()); err != nil {
+		return nil
+	}
+
+	explicit := limitOf(req.Elem(), path) != 0
+
+	if v, ok := req.Interface().(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return nil
+		}
+	}
+
+	if limitOf(req.Elem(), path) <= max {
+		return nil
+	}
+
+	if explicit {
+		return fmt.Errorf("limit must be less than or equal to %d for this key's tier", max)
+	}
+
+	rewritten, err := setLimit(params, path, max)
+	if err != nil {
+		return nil
+	}
+
+	r.Params = rewritten
+	return nil
+}
+
+// limitPath returns the json names leading to the limit of a params type,
+// either limit or options.limit, nil if it has none.
+func limitPath(t reflect.Type) []string {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+
+		switch {
+		case name == "limit" && isInt(f.Type):
+			return []string{"limit"}
+
+		case name == "options":
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if sub := limitPath(ft); len(sub) == 1 {
+					return []string{"options", "limit"}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func limitOf(v reflect.Value, path []string) int64 {
+	for _, name := range path {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return 0
+			}
+			v = v.Elem()
+		}
+
+		field, ok := fieldByJSON(v.Type(), name)
+		if !ok {
+			return 0
+		}
+		v = v.FieldByIndex(field.Index)
+	}
+
+	if !isInt(v.Type()) {
+		return 0
+	}
+
+	return v.Int()
+}
+
+func fieldByJSON(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if strings.Split(t.Field(i).Tag.Get("json"), ",")[0] == name {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func isInt(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	default:
+		return false
+	}
+}
+
+// setLimit sets the limit at path in raw params.
+func setLimit(params json.RawMessage, path []string, limit int64) (json.RawMessage, error) {
+	obj := map[string]json.RawMessage{}
+	if err := json.Unmarshal(params, &obj); err != nil {
+		return nil, err
+	}
+
+	value, err := json.Marshal(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(path) > 1 {
+		value, err = setLimit(nonNull(obj[path[0]]), path[1:], limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	obj[path[0]] = value
+	return json.Marshal(obj)
+}
+
+func nonNull(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 || string(raw) == "null" {
+		return json.RawMessage("{}")
+	}
+	return raw
+}
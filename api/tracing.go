@@ -0,0 +1,75 @@
+package api
+
+import (
+	"github.com/autoapev1/indexer/storage"
+	"github.com/autoapev1/indexer/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// startCallSpan starts the span of a json-rpc call under the context of its
+// transport, the handler runs under it. Unknown methods share a span name.
+// The request id is chosen by the client and can hold anything, so it is not
+// recorded.
+func startCallSpan(r *JRPCRequest) trace.Span {
+	method := r.Method
+	if _, ok := methodsByName[method]; !ok {
+		method = "unknown"
+	}
+
+	ctx, span := tracing.Tracer().Start(r.Context(), "jrpc "+method,
+		trace.WithAttributes(
+			attribute.String("rpc.system", "jsonrpc"),
+			attribute.String("rpc.method", method),
+		),
+	)
+	r.ctx = ctx
+
+	return span
+}
+
+// endCallSpan records the outcome of a call on its span and ends it.
+func endCallSpan(span trace.Span, resp Response) {
+	status := callStatus(resp)
+	span.SetAttributes(attribute.String("rpc.jsonrpc.status", status))
+	if status != "ok" {
+		span.SetStatus(codes.Error, status)
+	}
+
+	span.End()
+}
+
+// store returns the store of a chain with its queries traced under the call,
+// nil if the chain has no store.
+func (s *Server) store(r *JRPCRequest, chainID int64) storage.Store {
+	store := s.stores.GetStore(chainID)
+	if store == nil {
+		return nil
+	}
+
+	return store.WithContext(r.Context())
+}
+
+// metadataCarrier reads W3C trace context from grpc metadata.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key string, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
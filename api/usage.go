@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/autoapev1/indexer/auth"
+)
+
+// initUsage meters the calls of every key, the usage is flushed to the auth
+// provider every usageFlushInterval.
+func (s *Server) initUsage() error {
+	if s.config.API.UsageFlushInterval <= 0 {
+		slog.Warn("Usage flush interval is not set, key usage will not be recorded")
+		return nil
+	}
+
+	if s.auth == nil {
+		return nil
+	}
+
+	interval := time.Duration(s.config.API.UsageFlushInterval) * time.Second
+
+	s.usage = auth.NewUsageMeter(s.auth)
+	go s.usage.Run(context.Background(), interval)
+
+	return nil
+}
+
+// recordUsage meters a call of a registered method and counts it against the
+// quotas of the key unless checkTier already did, calls without a key and
+// calls with the master key are not metered and get their reserved quota
+// unit back.
+func (s *Server) recordUsage(c *caller, r *JRPCRequest, resp Response) {
+	_, registered := methodsByName[r.Method]
+	if c.key == "" || c.key == s.config.API.AuthMasterKey || !registered {
+		if !r.reserved.IsZero() {
+			s.quotas.refund(c.key, r.reserved)
+		}
+		return
+	}
+
+	now := time.Now()
+
+	if s.quotas != nil && r.reserved.IsZero() {
+		s.quotas.add(c.key, now)
+	}
+
+	if s.usage != nil {
+		s.usage.Record(c.key, r.Method, c.ip, now, resultRows(resp))
+	}
+}
+
+var rawMessage = reflect.TypeOf(json.RawMessage{})
+
+// resultRows is the length of a list or map result, 1 for other results and
+// 0 for errors.
+func resultRows(resp Response) int64 {
+	v := reflect.ValueOf(resp)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return 0
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return 0
+	}
+
+	if e := v.FieldByName("Error"); e.IsValid() && !e.IsNil() {
+		return 0
+	}
+
+	result := v.FieldByName("Result")
+	if !result.IsValid() {
+		return 0
+	}
+
+	switch {
+	case result.Type() == rawMessage:
+		if result.Len() == 0 {
+			return 0
+		}
+		return 1
+	case result.Kind() == reflect.Slice, result.Kind() == reflect.Map:
+		return int64(result.Len())
+	case result.Kind() == reflect.Ptr:
+		if result.IsNil() {
+			return 0
+		}
+		return 1
+	default:
+		return 1
+	}
+}
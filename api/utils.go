@@ -15,6 +15,19 @@ var (
 	errMissingAuth      = errors.New("missing Authentication header")
 	errUnmarshalParams  = errors.New("failed to unmarshal params")
 	errMissingParams    = errors.New("missing params")
+	errRoutesDisabled   = errors.New("route finder is disabled")
+	errLogsDisabled     = errors.New("logs are not indexed for this chain")
+	errTooManyLogs      = errors.New("query returned more than 10000 results")
+	errWebhooksDisabled = errors.New("webhooks are disabled")
+	errTooManyWebhooks  = errors.New("webhook limit reached")
+	errWebhookNotFound  = errors.New("webhook not found")
+	errWebhookURL       = errors.New("webhook url host can not be resolved")
+	errKeyRequired      = errors.New("method requires an api key, auth is disabled")
+	errWebsocketOnly    = errors.New("subscriptions are only available over websocket at /ws")
+	errFeedDisabled     = errors.New("subscriptions are disabled")
+	errTooManySubs      = errors.New("subscription limit reached")
+	errNoAuthKeys       = errors.New("keys are not used with the noauth provider")
+	errUnknownTier      = errors.New("invalid parameter: tier is not defined")
 )
 
 type apiHandler func(w http.ResponseWriter, r *http.Request) error
@@ -0,0 +1,453 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/autoapev1/indexer/auth"
+	"github.com/autoapev1/indexer/feed"
+	"github.com/autoapev1/indexer/types"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsMaxSubscriptions = 32
+	wsSendBuffer       = 256
+	wsMaxMessageSize   = 1 << 20
+	wsWriteWait        = 10 * time.Second
+	wsPongWait         = 60 * time.Second
+	wsPingInterval     = 30 * time.Second
+	// idle connections are re-authenticated this often, calls re-authenticate
+	// on every message
+	wsAuthInterval = time.Minute
+)
+
+type wsSubscription struct {
+	id          string
+	channel     string
+	chainID     int64
+	pairFilter  *types.PairFilter
+	tokenFilter *types.TokenFilter
+}
+
+// wsConn serves JSON-RPC over a websocket with the auth level and rate limit
+// of the key that opened it. Notifications of all its subscriptions come
+// from a single feed subscription.
+type wsConn struct {
+	s      *Server
+	conn   *websocket.Conn
+	caller *caller
+
+	// copy of the upgrade request, the key is authenticated again with it so
+	// revoked, rotated or expired keys are disconnected
+	authReq *http.Request
+
+	send chan interface{}
+	done chan struct{}
+	once sync.Once
+
+	lock sync.Mutex
+	subs map[string]*wsSubscription
+	feed *feed.Subscription
+}
+
+func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) error {
+	caller, ok := callerFromRequest(r)
+	if !ok {
+		return writeError(w, http.StatusInternalServerError, errInternalServer)
+	}
+
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  4096,
+		WriteBufferSize: 4096,
+		CheckOrigin:     s.allowedOrigin,
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has already replied
+		slog.Debug("websocket upgrade failed", "err", err)
+		return nil
+	}
+
+	c := &wsConn{
+		s:       s,
+		conn:    conn,
+		caller:  caller,
+		authReq: r.Clone(context.Background()),
+		send:    make(chan interface{}, wsSendBuffer),
+		done:    make(chan struct{}),
+		subs:    make(map[string]*wsSubscription),
+	}
+
+	caller.local = map[string]func(r *JRPCRequest) Response{
+		"idx_subscribe":   func(r *JRPCRequest) Response { return c.subscribe(r) },
+		"idx_unsubscribe": func(r *JRPCRequest) Response { return c.unsubscribe(r) },
+	}
+
+	go c.writeLoop()
+	c.readLoop()
+
+	return nil
+}
+
+func (c *wsConn) close() {
+	c.once.Do(func() {
+		close(c.done)
+
+		c.lock.Lock()
+		if c.feed != nil {
+			c.feed.Close()
+		}
+		c.lock.Unlock()
+
+		c.conn.Close()
+	})
+}
+
+// closeWith sends a close frame with code and reason, then closes.
+func (c *wsConn) closeWith(code int, reason string) {
+	_ = c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteWait))
+	c.close()
+}
+
+// authenticate checks the key of the connection again, the connection is
+// closed with a policy violation if it is no longer valid.
+func (c *wsConn) authenticate() (auth.AuthLevel, *auth.KeyInfo, bool) {
+	level, info, err := c.s.auth.Authenticate(c.authReq)
+	if err != nil {
+		observeAuthFailure(err)
+		c.closeWith(websocket.ClosePolicyViolation, err.Error())
+		return 0, nil, false
+	}
+
+	return level, info, true
+}
+
+func (c *wsConn) readLoop() {
+	defer c.close()
+
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, msg, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		// the level, scopes and tier of the key may have changed too
+		level, info, ok := c.authenticate()
+		if !ok {
+			return
+		}
+		c.caller.level = level
+		if info != nil {
+			c.caller.scopes = info.Scopes
+			c.caller.tier = info.Tier
+		}
+
+		if resp := c.handleMessage(msg); resp != nil && !c.push(resp) {
+			return
+		}
+	}
+}
+
+func (c *wsConn) writeLoop() {
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+	authTicker := time.NewTicker(wsAuthInterval)
+	defer authTicker.Stop()
+	defer c.close()
+
+	for {
+		select {
+		case <-c.done:
+			return
+
+		case v := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteJSON(v); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+
+		case <-authTicker.C:
+			if _, _, ok := c.authenticate(); !ok {
+				return
+			}
+		}
+	}
+}
+
+// push queues a message, a client too slow to keep up is disconnected.
+func (c *wsConn) push(v interface{}) bool {
+	select {
+	case <-c.done:
+		return false
+	case c.send <- v:
+		return true
+	default:
+		slog.Warn("websocket client is too slow, closing connection")
+		c.close()
+		return false
+	}
+}
+
+// handleMessage answers a single request or a batch like a POST to /, each
+// call is charged to the rate limit.
+func (c *wsConn) handleMessage(msg []byte) interface{} {
+	reqs, isBatch, err := parseRequests(msg)
+	if err != nil {
+		return &JRPCResponse{
+			JSONRPC: "2.0",
+			Error: &JRPCError{
+				Code:    -32700,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	if err := c.s.checkBatch(c.caller, len(reqs)); err != nil {
+		return &JRPCResponse{
+			JSONRPC: "2.0",
+			Error: &JRPCError{
+				Code:    -32600,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	resp := make([]Response, 0, len(reqs))
+	for _, r := range reqs {
+		resp = append(resp, c.s.handleJrpcRequest(r, c.caller))
+	}
+
+	if len(resp) == 1 && !isBatch {
+		return resp[0]
+	}
+
+	return resp
+}
+
+func (c *wsConn) subscribe(r *JRPCRequest) *types.SubscribeResponse {
+	req := &types.SubscribeRequest{}
+
+	if r.Params == nil {
+		return &types.SubscribeResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.SubscribeResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.SubscribeResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	if c.s.stores.GetStore(*req.ChainID) == nil {
+		return &types.SubscribeResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: "invalid chain_id",
+			},
+		}
+	}
+
+	if c.s.feed == nil {
+		return &types.SubscribeResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32701,
+				Message: errFeedDisabled.Error(),
+			},
+		}
+	}
+
+	id, err := auth.GenerateKey(auth.KeyTypeHex32)
+	if err != nil {
+		if c.s.debug {
+			slog.Error("failed to generate subscription id", "err", err)
+		}
+		return &types.SubscribeResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errInternalServer.Error(),
+			},
+		}
+	}
+	id = "0x" + id
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if len(c.subs) >= wsMaxSubscriptions {
+		return &types.SubscribeResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errTooManySubs.Error(),
+			},
+		}
+	}
+
+	c.subs[id] = &wsSubscription{
+		id:          id,
+		channel:     *req.Channel,
+		chainID:     *req.ChainID,
+		pairFilter:  req.PairFilter,
+		tokenFilter: req.TokenFilter,
+	}
+
+	if c.feed == nil {
+		c.feed = c.s.feed.Subscribe(64)
+		go c.feedLoop(c.feed)
+	}
+
+	return &types.SubscribeResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: id,
+	}
+}
+
+func (c *wsConn) unsubscribe(r *JRPCRequest) *types.UnsubscribeResponse {
+	req := &types.UnsubscribeRequest{}
+
+	if r.Params == nil {
+		return &types.UnsubscribeResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errMissingParams.Error(),
+			},
+		}
+	}
+
+	err := json.Unmarshal(r.Params, req)
+	if err != nil {
+		return &types.UnsubscribeResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: errUnmarshalParams.Error(),
+			},
+		}
+	}
+
+	err = req.Validate()
+	if err != nil {
+		return &types.UnsubscribeResponse{
+			ID:     r.ID,
+			Method: r.Method,
+			Error: &types.JRPCError{
+				Code:    -32602,
+				Message: err.Error(),
+			},
+		}
+	}
+
+	c.lock.Lock()
+	_, ok := c.subs[*req.Subscription]
+	delete(c.subs, *req.Subscription)
+	c.lock.Unlock()
+
+	return &types.UnsubscribeResponse{
+		ID:     r.ID,
+		Method: r.Method,
+		Result: ok,
+	}
+}
+
+func (c *wsConn) feedLoop(sub *feed.Subscription) {
+	for events := range sub.C {
+		c.lock.Lock()
+		subs := make([]*wsSubscription, 0, len(c.subs))
+		for _, s := range c.subs {
+			subs = append(subs, s)
+		}
+		c.lock.Unlock()
+
+		for _, e := range events {
+			for _, s := range subs {
+				result, ok := s.match(e)
+				if !ok {
+					continue
+				}
+
+				if !c.push(&types.SubscriptionNotification{
+					JSONRPC: "2.0",
+					Method:  "idx_subscription",
+					Params: types.SubscriptionResult{
+						Subscription: s.id,
+						Result:       result,
+					},
+				}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// match returns the notification result of e if it belongs to the subscription.
+func (s *wsSubscription) match(e *feed.Event) (interface{}, bool) {
+	if e.ChainID != s.chainID {
+		return nil, false
+	}
+
+	switch {
+	case s.channel == types.ChannelNewPairs && e.Kind == feed.KindPair:
+		return e.Pair, s.pairFilter.Match(e.Pair)
+	case s.channel == types.ChannelNewTokens && e.Kind == feed.KindToken:
+		return e.Token, s.tokenFilter.Match(e.Token)
+	case s.channel == types.ChannelNewBlocks && e.Kind == feed.KindBlock:
+		return e.Block, true
+	case s.channel == types.ChannelSyncStatus && e.Kind == feed.KindStatus:
+		return &types.SyncStatus{ChainID: e.ChainID, Heights: e.Heights}, true
+	default:
+		return nil, false
+	}
+}
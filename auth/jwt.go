@@ -0,0 +1,454 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/autoapev1/indexer/config"
+	"github.com/autoapev1/indexer/types"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// signing algorithms of jwt keys
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmES256 = "ES256"
+)
+
+// jwksCheckInterval is how often the jwks file is checked for changes, it is
+// also checked when a token has an unknown kid.
+const jwksCheckInterval = 10 * time.Second
+
+var (
+	ErrCannotMint  = errors.New("no signing key to mint jwt keys")
+	ErrKeyLifetime = errors.New("jwt keys must expire, within the max lifetime when one is set")
+)
+
+// JWTOptions configure the keys of a JWTProvider.
+type JWTOptions struct {
+	Algorithm string // HS256 | ES256
+	Secret    string // HS256 signing secret
+	KeyFile   string // ES256 PEM private key, or a public key to only verify
+	KeyID     string // kid of minted tokens
+	JWKSFile  string // verification keys by kid
+	Issuer    string // iss of minted tokens, required on parsed tokens when set
+
+	// longest time from iat to exp of parsed and minted tokens, 0 is
+	// unlimited. Tokens without exp are refused either way.
+	MaxLifetime time.Duration
+}
+
+// jwtClaims are the claims of a jwt key, the owner is the subject.
+type jwtClaims struct {
+	Scopes *types.KeyScopes `json:"scopes,omitempty"`
+	Tier   string           `json:"tier,omitempty"`
+	Notes  string           `json:"notes,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// JWTProvider authenticates signed tokens without storing keys, so replicas
+// sharing its verification keys authenticate without a database. Keys can not
+// be revoked, extended or changed once minted, and their usage is not kept.
+type JWTProvider struct {
+	opts    JWTOptions
+	method  jwt.SigningMethod
+	signKey interface{} // nil if the provider only verifies
+	key     interface{} // verification key of tokens without a kid or with opts.KeyID
+
+	// lock protects the jwks keys.
+	lock      sync.RWMutex
+	jwks      map[string]interface{}
+	jwksMod   time.Time
+	jwksCheck time.Time
+
+	defaultExpiry time.Duration
+}
+
+func NewJWTProvider(opts JWTOptions) (*JWTProvider, error) {
+	a := &JWTProvider{
+		opts:          opts,
+		jwks:          make(map[string]interface{}),
+		defaultExpiry: time.Hour * 24 * 30 * 3, // 3 months
+	}
+
+	switch opts.Algorithm {
+	case JWTAlgorithmHS256, "":
+		a.method = jwt.SigningMethodHS256
+		if opts.Secret != "" {
+			a.signKey = []byte(opts.Secret)
+			a.key = []byte(opts.Secret)
+		}
+
+	case JWTAlgorithmES256:
+		a.method = jwt.SigningMethodES256
+		if opts.KeyFile != "" {
+			b, err := os.ReadFile(opts.KeyFile)
+			if err != nil {
+				return nil, err
+			}
+
+			if private, err := jwt.ParseECPrivateKeyFromPEM(b); err == nil {
+				a.signKey = private
+				a.key = &private.PublicKey
+			} else if public, err := jwt.ParseECPublicKeyFromPEM(b); err == nil {
+				a.key = public
+			} else {
+				return nil, fmt.Errorf("invalid ES256 key file %s: %w", opts.KeyFile, err)
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("invalid jwt algorithm: %s", opts.Algorithm)
+	}
+
+	if opts.JWKSFile != "" {
+		if err := a.loadJWKS(); err != nil {
+			return nil, err
+		}
+	}
+
+	if a.key == nil && len(a.jwks) == 0 {
+		return nil, errors.New("jwt auth needs a secret, a key file or a jwks file")
+	}
+
+	return a, nil
+}
+
+func (a *JWTProvider) WithDefaultExpiry(defaultExpiry time.Duration) *JWTProvider {
+	if defaultExpiry < 0 {
+		slog.Warn("invalid default expiry, using default value", "default_expiry", defaultExpiry.String())
+		return a
+	}
+
+	a.defaultExpiry = defaultExpiry
+	return a
+}
+
+func (a *JWTProvider) Authenticate(r *http.Request) (AuthLevel, *KeyInfo, error) {
+	key := KeyFromRequest(r)
+
+	// check master
+	master := config.Get().API.AuthMasterKey
+	if master != "" && key == master {
+		return AuthLevelMaster, nil, nil
+	}
+
+	claims, err := a.parse(key)
+	if err != nil {
+		return AuthLevelUnauthorized, nil, err
+	}
+
+	return AuthLevelBasic, &KeyInfo{Scopes: claims.Scopes, Tier: claims.Tier}, nil
+}
+
+func (a *JWTProvider) Register(opts KeyOptions) (string, error) {
+	now := time.Now()
+	claims := &jwtClaims{
+		Scopes: opts.Scopes,
+		Tier:   opts.Tier,
+		Notes:  opts.Notes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:       uuid.New().String(),
+			Subject:  opts.Owner,
+			Issuer:   a.opts.Issuer,
+			IssuedAt: jwt.NewNumericDate(now),
+		},
+	}
+
+	// tokens can not be revoked, so they have to expire
+	exp := opts.expiry(now, a.defaultExpiry)
+	if exp == 0 && opts.Exp == nil && a.opts.MaxLifetime > 0 {
+		exp = now.Add(a.opts.MaxLifetime).Unix()
+	}
+
+	if exp == 0 || (a.opts.MaxLifetime > 0 && exp > now.Add(a.opts.MaxLifetime).Unix()) {
+		return "", ErrKeyLifetime
+	}
+	claims.ExpiresAt = jwt.NewNumericDate(time.Unix(exp, 0))
+
+	return a.mint(claims)
+}
+
+// mint signs claims with the signing key.
+func (a *JWTProvider) mint(claims *jwtClaims) (string, error) {
+	if a.signKey == nil {
+		return "", ErrCannotMint
+	}
+
+	token := jwt.NewWithClaims(a.method, claims)
+	if a.opts.KeyID != "" {
+		token.Header["kid"] = a.opts.KeyID
+	}
+
+	return token.SignedString(a.signKey)
+}
+
+// parse verifies a token and returns its claims, ErrExpiredKey if it expired
+// and ErrUnauthorized if it is invalid, has no exp or lives longer than the
+// max lifetime.
+func (a *JWTProvider) parse(key string) (*jwtClaims, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{JWTAlgorithmHS256, JWTAlgorithmES256}),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+	}
+	if a.opts.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(a.opts.Issuer))
+	}
+
+	claims := &jwtClaims{}
+	_, err := jwt.ParseWithClaims(key, claims, a.verificationKey, opts...)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredKey
+		}
+		return nil, ErrUnauthorized
+	}
+
+	if claims.Scopes != nil && claims.Scopes.Validate() != nil {
+		return nil, ErrUnauthorized
+	}
+
+	if a.opts.MaxLifetime > 0 {
+		if claims.IssuedAt == nil || claims.ExpiresAt.Sub(claims.IssuedAt.Time) > a.opts.MaxLifetime {
+			return nil, ErrUnauthorized
+		}
+	}
+
+	return claims, nil
+}
+
+// verificationKey returns the key of a token by its kid, the jwks is
+// reloaded if it changed.
+func (a *JWTProvider) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	var key interface{}
+	if kid == "" || kid == a.opts.KeyID {
+		key = a.key
+	}
+
+	if key == nil && a.opts.JWKSFile != "" {
+		key = a.jwksKey(kid)
+	}
+
+	if key == nil {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+
+	// the algorithm of the token has to match the key, HS256 tokens can not
+	// be verified with a public key
+	switch key.(type) {
+	case []byte:
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, jwt.ErrTokenSignatureInvalid
+		}
+	case *ecdsa.PublicKey:
+		if token.Method != jwt.SigningMethodES256 {
+			return nil, jwt.ErrTokenSignatureInvalid
+		}
+	}
+
+	return key, nil
+}
+
+func (a *JWTProvider) jwksKey(kid string) interface{} {
+	a.lock.RLock()
+	key, ok := a.jwks[kid]
+	stale := time.Since(a.jwksCheck) > jwksCheckInterval
+	a.lock.RUnlock()
+
+	if ok && !stale {
+		return key
+	}
+
+	if err := a.loadJWKS(); err != nil {
+		slog.Error("failed to reload jwks", "file", a.opts.JWKSFile, "err", err)
+	}
+
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.jwks[kid]
+}
+
+// loadJWKS reads the jwks file if it changed since it was last read.
+func (a *JWTProvider) loadJWKS() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.jwksCheck = time.Now()
+
+	info, err := os.Stat(a.opts.JWKSFile)
+	if err != nil {
+		return err
+	}
+
+	if info.ModTime().Equal(a.jwksMod) {
+		return nil
+	}
+
+	b, err := os.ReadFile(a.opts.JWKSFile)
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseJWKS(b)
+	if err != nil {
+		return err
+	}
+
+	a.jwks = keys
+	a.jwksMod = info.ModTime()
+	return nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// parseJWKS returns the HS256 (oct) and ES256 (EC P-256) keys of a jwks by
+// kid, other keys are skipped.
+func parseJWKS(b []byte) (map[string]interface{}, error) {
+	set := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("invalid jwks: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		switch {
+		case k.Kty == "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil || len(secret) == 0 {
+				return nil, fmt.Errorf("invalid jwk %q", k.Kid)
+			}
+			keys[k.Kid] = secret
+
+		case k.Kty == "EC" && k.Crv == "P-256":
+			x, errX := base64.RawURLEncoding.DecodeString(k.X)
+			y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+			if errX != nil || errY != nil {
+				return nil, fmt.Errorf("invalid jwk %q", k.Kid)
+			}
+
+			key := &ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(x),
+				Y:     new(big.Int).SetBytes(y),
+			}
+			if !key.Curve.IsOnCurve(key.X, key.Y) {
+				return nil, fmt.Errorf("invalid jwk %q", k.Kid)
+			}
+			keys[k.Kid] = key
+		}
+	}
+
+	return keys, nil
+}
+
+// jwt keys are not stored, so they can not be deleted or changed
+func (a *JWTProvider) UpdateUsage(key string, usageDelta KeyUsage) error {
+	return nil
+}
+
+func (a *JWTProvider) DeleteKey(key string) (bool, error) {
+	return false, nil
+}
+
+func (a *JWTProvider) RevokeKey(key string) (bool, error) {
+	return false, nil
+}
+
+// GetKeyStats returns the claims of a valid key, without usage.
+func (a *JWTProvider) GetKeyStats(key string) (*types.KeyStats, error) {
+	claims, err := a.parse(key)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	stats := &types.KeyStats{
+		Key:         key,
+		Owner:       claims.Subject,
+		Notes:       claims.Notes,
+		Scopes:      claims.Scopes,
+		Tier:        claims.Tier,
+		MethodUsage: map[string]int64{},
+	}
+	if claims.IssuedAt != nil {
+		stats.Iat = claims.IssuedAt.Unix()
+	}
+	if claims.ExpiresAt != nil {
+		stats.Exp = claims.ExpiresAt.Unix()
+	}
+
+	return stats, nil
+}
+
+func (a *JWTProvider) ListKeys() ([]*types.KeyStats, error) {
+	return []*types.KeyStats{}, nil
+}
+
+func (a *JWTProvider) ExtendKey(key string, exp int64) (bool, error) {
+	return false, nil
+}
+
+func (a *JWTProvider) SetKeyScopes(key string, scopes *types.KeyScopes) (bool, error) {
+	return false, nil
+}
+
+func (a *JWTProvider) SetKeyTier(key string, tier string) (bool, error) {
+	return false, nil
+}
+
+// RotateKey mints a key with the claims of a valid key and a new id, the old
+// key stays valid until it expires.
+func (a *JWTProvider) RotateKey(key string) (string, error) {
+	claims, err := a.parse(key)
+	if err != nil {
+		return "", ErrInvalidKey
+	}
+
+	claims.ID = uuid.New().String()
+	claims.Issuer = a.opts.Issuer
+	claims.IssuedAt = jwt.NewNumericDate(time.Now())
+
+	return a.mint(claims)
+}
+
+func (a *JWTProvider) ListExpiringKeys(before int64) ([]*types.KeyStats, error) {
+	return []*types.KeyStats{}, nil
+}
+
+func (a *JWTProvider) GetDailyUsage(key string, from string) ([]*types.KeyDailyUsage, error) {
+	if _, err := a.parse(key); err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	return []*types.KeyDailyUsage{}, nil
+}
+
+// ensure JWTProvider implements Provider
+var _ Provider = (*JWTProvider)(nil)
@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/autoapev1/indexer/types"
+)
+
+func authenticate(p Provider, key string) (AuthLevel, *KeyInfo, error) {
+	r := httptest.NewRequest("POST", "/", nil)
+	r.Header.Set("Authentication", "Bearer "+key)
+	return p.Authenticate(r)
+}
+
+func TestJWTProviderHS256(t *testing.T) {
+	p, err := NewJWTProvider(JWTOptions{Algorithm: JWTAlgorithmHS256, Secret: "secret", Issuer: "indexer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := p.Register(KeyOptions{
+		Owner:  "acme",
+		Scopes: &types.KeyScopes{Chains: []int64{1}},
+		Tier:   "pro",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	level, info, err := authenticate(p, key)
+	if err != nil || level != AuthLevelBasic {
+		t.Fatalf("expected a basic key, got %d %v", level, err)
+	}
+	if info.Tier != "pro" || !info.Scopes.AllowsChain(1) || info.Scopes.AllowsChain(56) {
+		t.Fatalf("unexpected key info %+v", info)
+	}
+
+	stats, err := p.GetKeyStats(key)
+	if err != nil || stats.Owner != "acme" || stats.Exp == 0 {
+		t.Fatalf("unexpected stats %+v %v", stats, err)
+	}
+
+	// expired keys and keys signed with another secret are refused
+	exp := time.Now().Add(-time.Minute).Unix()
+	expired, err := p.Register(KeyOptions{Exp: &exp})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := authenticate(p, expired); err != ErrExpiredKey {
+		t.Fatalf("expected an expired key, got %v", err)
+	}
+
+	other, err := NewJWTProvider(JWTOptions{Secret: "other", Issuer: "indexer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, err := other.Register(KeyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := authenticate(p, forged); err != ErrUnauthorized {
+		t.Fatalf("expected a forged key to be refused, got %v", err)
+	}
+}
+
+func TestJWTProviderLifetime(t *testing.T) {
+	p, err := NewJWTProvider(JWTOptions{Secret: "secret", MaxLifetime: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// keys get the max lifetime without an expiry, longer or no expiry is refused
+	if _, err := p.WithDefaultExpiry(0).Register(KeyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	never := int64(0)
+	if _, err := p.Register(KeyOptions{Exp: &never}); err != ErrKeyLifetime {
+		t.Fatalf("expected a key without expiry to be refused, got %v", err)
+	}
+
+	long := time.Now().Add(2 * time.Hour).Unix()
+	if _, err := p.Register(KeyOptions{Exp: &long}); err != ErrKeyLifetime {
+		t.Fatalf("expected a key over the max lifetime to be refused, got %v", err)
+	}
+
+	// keys minted elsewhere without exp or with a longer lifetime are refused
+	unlimited, err := NewJWTProvider(JWTOptions{Secret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := unlimited.Register(KeyOptions{Exp: &long})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := authenticate(p, key); err != ErrUnauthorized {
+		t.Fatalf("expected a key over the max lifetime to be refused, got %v", err)
+	}
+
+	noExp, err := unlimited.mint(&jwtClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := authenticate(unlimited, noExp); err != ErrUnauthorized {
+		t.Fatalf("expected a key without exp to be refused, got %v", err)
+	}
+}
+
+func TestJWTProviderJWKS(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key.pem")
+	jwksFile := filepath.Join(dir, "jwks.json")
+
+	old := writeECKey(t, keyFile)
+	writeJWKS(t, jwksFile, map[string]*ecdsa.PrivateKey{"old": old})
+
+	signer, err := NewJWTProvider(JWTOptions{Algorithm: JWTAlgorithmES256, KeyFile: keyFile, KeyID: "old"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldKey, err := signer.Register(KeyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a replica that only verifies with the jwks
+	verifier, err := NewJWTProvider(JWTOptions{Algorithm: JWTAlgorithmES256, JWKSFile: jwksFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.Register(KeyOptions{}); err != ErrCannotMint {
+		t.Fatalf("expected a verifier not to mint, got %v", err)
+	}
+	if level, _, err := authenticate(verifier, oldKey); err != nil || level != AuthLevelBasic {
+		t.Fatalf("expected the old key to authenticate, got %d %v", level, err)
+	}
+
+	// rotate to a new signing key, the unknown kid reloads the jwks
+	current := writeECKey(t, keyFile)
+	signer, err = NewJWTProvider(JWTOptions{Algorithm: JWTAlgorithmES256, KeyFile: keyFile, KeyID: "new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := signer.Register(KeyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := authenticate(verifier, newKey); err != ErrUnauthorized {
+		t.Fatalf("expected an unknown kid to be refused, got %v", err)
+	}
+
+	writeJWKS(t, jwksFile, map[string]*ecdsa.PrivateKey{"old": old, "new": current})
+	// the mod time has to change for the file to be read again
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(jwksFile, future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{oldKey, newKey} {
+		if level, _, err := authenticate(verifier, key); err != nil || level != AuthLevelBasic {
+			t.Fatalf("expected both keys to authenticate, got %d %v", level, err)
+		}
+	}
+}
+
+func writeECKey(t *testing.T, path string) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: b}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func writeJWKS(t *testing.T, path string, keys map[string]*ecdsa.PrivateKey) {
+	jwks := `{"keys":[`
+	i := 0
+	for kid, key := range keys {
+		if i > 0 {
+			jwks += ","
+		}
+		jwks += fmt.Sprintf(`{"kty":"EC","crv":"P-256","kid":%q,"x":%q,"y":%q}`, kid,
+			base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+			base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))))
+		i++
+	}
+	jwks += `]}`
+
+	if err := os.WriteFile(path, []byte(jwks), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,14 +5,20 @@ import (
 	"encoding/hex"
 	"errors"
 	"log/slog"
+	"time"
 
+	"github.com/autoapev1/indexer/types"
 	"github.com/google/uuid"
 )
 
 // context key for middleware
 type CtxAuthKey int
 
-const AuthKey CtxAuthKey = 0
+const (
+	AuthKey    CtxAuthKey = iota
+	APIKey                // api key of the request, empty without auth
+	KeyInfoKey            // *KeyInfo of the key, nil for the master key and noauth
+)
 
 // auth levels
 type AuthLevel int
@@ -42,6 +48,7 @@ const (
 	KeyTypeHex64
 	KeyTypeHex128
 	KeyTypeHex256
+	KeyTypeJWT // minted by the JWTProvider
 )
 
 // string key types
@@ -52,6 +59,7 @@ const (
 	KeyTypeHex64String  = "hex64"
 	KeyTypeHex128String = "hex128"
 	KeyTypeHex256String = "hex256"
+	KeyTypeJWTString    = "jwt"
 )
 
 // take string of key type to int KeyType
@@ -69,12 +77,35 @@ func ToKeyType(s string) KeyType {
 		return KeyTypeHex128
 	case KeyTypeHex256String:
 		return KeyTypeHex256
+	case KeyTypeJWTString:
+		return KeyTypeJWT
 	default:
 		slog.Warn("invalid key type", "KeyType", s)
 		return KeyTypeHex64
 	}
 }
 
+func (k KeyType) String() string {
+	switch k {
+	case KeyTypeUUID:
+		return KeyTypeUUIDString
+	case KeyTypeHex16:
+		return KeyTypeHex16String
+	case KeyTypeHex32:
+		return KeyTypeHex32String
+	case KeyTypeHex64:
+		return KeyTypeHex64String
+	case KeyTypeHex128:
+		return KeyTypeHex128String
+	case KeyTypeHex256:
+		return KeyTypeHex256String
+	case KeyTypeJWT:
+		return KeyTypeJWTString
+	default:
+		return ""
+	}
+}
+
 func GenerateKey(keyType KeyType) (string, error) {
 	switch keyType {
 	case KeyTypeUUID:
@@ -89,6 +120,8 @@ func GenerateKey(keyType KeyType) (string, error) {
 		return generateRandomHex(64) // 128 hex characters
 	case KeyTypeHex256:
 		return generateRandomHex(128) // 256 hex characters
+	case KeyTypeJWT:
+		return "", ErrCannotMint
 	default:
 		return "", errors.New("invalid key type")
 	}
@@ -103,9 +136,46 @@ func generateRandomHex(length int) (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
+// KeyOptions are set on a key when it is registered.
+type KeyOptions struct {
+	Exp    *int64 // unix, 0 never expires, nil for the provider default
+	Owner  string
+	Notes  string
+	Scopes *types.KeyScopes // nil is unrestricted
+	Tier   string           // empty for the default tier
+}
+
+// KeyInfo is what a key authenticates with besides its level.
+type KeyInfo struct {
+	Scopes *types.KeyScopes // nil is unrestricted
+	Tier   string           // empty for the default tier
+}
+
+// expiry returns the exp of a key registered at now.
+func (o KeyOptions) expiry(now time.Time, defaultExpiry time.Duration) int64 {
+	if o.Exp != nil {
+		return *o.Exp
+	}
+
+	if defaultExpiry <= 0 {
+		return 0
+	}
+
+	return now.Add(defaultExpiry).Unix()
+}
+
+func isExpired(exp int64, now time.Time) bool {
+	return exp != 0 && exp <= now.Unix()
+}
+
+// KeyUsage is a delta of the usage of a key, IP and AccessedAt are of its
+// latest call.
 type KeyUsage struct {
 	IP          string
 	AccessedAt  int64
+	Day         string // DayFormat, the day of every call, empty to skip the daily rollup
 	Requests    int64
+	Rows        int64
 	MethodUsage map[string]int64
+	MethodRows  map[string]int64
 }
@@ -3,21 +3,29 @@ package auth
 import (
 	"log/slog"
 	"net/http"
-	"strings"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/autoapev1/indexer/config"
+	"github.com/autoapev1/indexer/types"
 	"github.com/savsgio/gotils/nocopy"
 )
 
 type memoryKey struct {
-	Iat         int64            `json:"iat"`
-	Exp         int64            `json:"exp"`
-	LastIP      string           `json:"last_ip"`
-	LastAccess  int64            `json:"last_access"`
-	CallCount   int64            `json:"call_count"`
-	MethodUsage map[string]int64 `json:"method_usage"`
+	Iat         int64                           `json:"iat"`
+	Exp         int64                           `json:"exp"`
+	Revoked     bool                            `json:"revoked"`
+	Owner       string                          `json:"owner"`
+	Notes       string                          `json:"notes"`
+	Scopes      *types.KeyScopes                `json:"scopes"`
+	Tier        string                          `json:"tier"`
+	LastIP      string                          `json:"last_ip"`
+	LastAccess  int64                           `json:"last_access"`
+	CallCount   int64                           `json:"call_count"`
+	RowCount    int64                           `json:"row_count"`
+	MethodUsage map[string]int64                `json:"method_usage"`
+	Daily       map[string]*types.KeyDailyUsage `json:"daily"`
 }
 
 type MemoryProvider struct {
@@ -55,27 +63,31 @@ func (a *MemoryProvider) WithDefaultExpiry(defaultExpiry time.Duration) *MemoryP
 	return a
 }
 
-func (a *MemoryProvider) Authenticate(r *http.Request) (AuthLevel, error) {
+func (a *MemoryProvider) Authenticate(r *http.Request) (AuthLevel, *KeyInfo, error) {
 	a.lock.RLock()
 	defer a.lock.RUnlock()
 
-	key := r.Header.Get("Authentication")
-	key = strings.TrimPrefix(key, "Bearer ")
+	key := KeyFromRequest(r)
 
 	// check master
 	master := config.Get().API.AuthMasterKey
 	if master != "" && key == master {
-		return AuthLevelMaster, nil
+		return AuthLevelMaster, nil, nil
 	}
 
-	if _, ok := a.Keys[key]; !ok {
-		return AuthLevelUnauthorized, ErrUnauthorized
+	k, ok := a.Keys[key]
+	if !ok || k.Revoked {
+		return AuthLevelUnauthorized, nil, ErrUnauthorized
+	}
+
+	if isExpired(k.Exp, time.Now()) {
+		return AuthLevelUnauthorized, nil, ErrExpiredKey
 	}
 
-	return AuthLevelBasic, nil
+	return AuthLevelBasic, &KeyInfo{Scopes: k.Scopes, Tier: k.Tier}, nil
 }
 
-func (a *MemoryProvider) Register() (string, error) {
+func (a *MemoryProvider) Register(opts KeyOptions) (string, error) {
 	a.lock.Lock()
 	defer a.lock.Unlock()
 
@@ -84,7 +96,17 @@ func (a *MemoryProvider) Register() (string, error) {
 		return "", err
 	}
 
-	a.Keys[key] = &memoryKey{}
+	now := time.Now()
+	a.Keys[key] = &memoryKey{
+		Iat:         now.Unix(),
+		Exp:         opts.expiry(now, a.defaultExpiry),
+		Owner:       opts.Owner,
+		Notes:       opts.Notes,
+		Scopes:      opts.Scopes,
+		Tier:        opts.Tier,
+		MethodUsage: make(map[string]int64),
+		Daily:       make(map[string]*types.KeyDailyUsage),
+	}
 
 	return key, nil
 }
@@ -93,19 +115,238 @@ func (a *MemoryProvider) UpdateUsage(key string, usageDelta KeyUsage) error {
 	a.lock.Lock()
 	defer a.lock.Unlock()
 
-	if _, ok := a.Keys[key]; !ok {
+	k, ok := a.Keys[key]
+	if !ok {
 		return ErrInvalidKey
 	}
 
-	a.Keys[key].CallCount += usageDelta.Requests
-	a.Keys[key].LastIP = usageDelta.IP
-	a.Keys[key].LastAccess = usageDelta.AccessedAt
-	for k, v := range usageDelta.MethodUsage {
-		a.Keys[key].MethodUsage[k] += v
+	k.CallCount += usageDelta.Requests
+	k.RowCount += usageDelta.Rows
+	if usageDelta.AccessedAt >= k.LastAccess {
+		k.LastIP = usageDelta.IP
+		k.LastAccess = usageDelta.AccessedAt
+	}
+	for method, count := range usageDelta.MethodUsage {
+		k.MethodUsage[method] += count
+	}
+
+	if usageDelta.Day == "" {
+		return nil
+	}
+
+	day, ok := k.Daily[usageDelta.Day]
+	if !ok {
+		day = &types.KeyDailyUsage{
+			Day:         usageDelta.Day,
+			MethodUsage: make(map[string]int64),
+		}
+		k.Daily[usageDelta.Day] = day
+	}
+
+	day.Requests += usageDelta.Requests
+	day.Rows += usageDelta.Rows
+	for method, count := range usageDelta.MethodUsage {
+		day.MethodUsage[method] += count
 	}
 
 	return nil
 }
 
+func (a *MemoryProvider) GetDailyUsage(key string, from string) ([]*types.KeyDailyUsage, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	k, ok := a.Keys[key]
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+
+	days := []*types.KeyDailyUsage{}
+	for _, day := range k.Daily {
+		if day.Day < from {
+			continue
+		}
+
+		usage := make(map[string]int64, len(day.MethodUsage))
+		for method, count := range day.MethodUsage {
+			usage[method] = count
+		}
+
+		days = append(days, &types.KeyDailyUsage{
+			Day:         day.Day,
+			Requests:    day.Requests,
+			Rows:        day.Rows,
+			MethodUsage: usage,
+		})
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Day < days[j].Day
+	})
+
+	return days, nil
+}
+
+func (a *MemoryProvider) DeleteKey(key string) (bool, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if _, ok := a.Keys[key]; !ok {
+		return false, nil
+	}
+
+	delete(a.Keys, key)
+	return true, nil
+}
+
+func (a *MemoryProvider) RevokeKey(key string) (bool, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	k, ok := a.Keys[key]
+	if !ok {
+		return false, nil
+	}
+
+	k.Revoked = true
+	return true, nil
+}
+
+func (a *MemoryProvider) GetKeyStats(key string) (*types.KeyStats, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	k, ok := a.Keys[key]
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+
+	return k.stats(key), nil
+}
+
+func (a *MemoryProvider) ListKeys() ([]*types.KeyStats, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	keys := make([]*types.KeyStats, 0, len(a.Keys))
+	for key, k := range a.Keys {
+		keys = append(keys, k.stats(key))
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Iat != keys[j].Iat {
+			return keys[i].Iat < keys[j].Iat
+		}
+		return keys[i].Key < keys[j].Key
+	})
+
+	return keys, nil
+}
+
+func (a *MemoryProvider) ExtendKey(key string, exp int64) (bool, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	k, ok := a.Keys[key]
+	if !ok {
+		return false, nil
+	}
+
+	k.Exp = exp
+	return true, nil
+}
+
+func (a *MemoryProvider) SetKeyScopes(key string, scopes *types.KeyScopes) (bool, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	k, ok := a.Keys[key]
+	if !ok {
+		return false, nil
+	}
+
+	k.Scopes = scopes
+	return true, nil
+}
+
+func (a *MemoryProvider) SetKeyTier(key string, tier string) (bool, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	k, ok := a.Keys[key]
+	if !ok {
+		return false, nil
+	}
+
+	k.Tier = tier
+	return true, nil
+}
+
+func (a *MemoryProvider) RotateKey(key string) (string, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	k, ok := a.Keys[key]
+	if !ok {
+		return "", ErrInvalidKey
+	}
+
+	rotated, err := GenerateKey(a.keyType)
+	if err != nil {
+		return "", err
+	}
+
+	delete(a.Keys, key)
+	a.Keys[rotated] = k
+
+	return rotated, nil
+}
+
+func (a *MemoryProvider) ListExpiringKeys(before int64) ([]*types.KeyStats, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	now := time.Now().Unix()
+	keys := []*types.KeyStats{}
+	for key, k := range a.Keys {
+		if k.Revoked || k.Exp == 0 || k.Exp <= now || k.Exp > before {
+			continue
+		}
+		keys = append(keys, k.stats(key))
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Exp != keys[j].Exp {
+			return keys[i].Exp < keys[j].Exp
+		}
+		return keys[i].Key < keys[j].Key
+	})
+
+	return keys, nil
+}
+
+func (k *memoryKey) stats(key string) *types.KeyStats {
+	usage := make(map[string]int64, len(k.MethodUsage))
+	for method, count := range k.MethodUsage {
+		usage[method] = count
+	}
+
+	return &types.KeyStats{
+		Key:         key,
+		Iat:         k.Iat,
+		Exp:         k.Exp,
+		Revoked:     k.Revoked,
+		Owner:       k.Owner,
+		Notes:       k.Notes,
+		Scopes:      k.Scopes,
+		Tier:        k.Tier,
+		LastIP:      k.LastIP,
+		LastAccess:  k.LastAccess,
+		CallCount:   k.CallCount,
+		RowCount:    k.RowCount,
+		MethodUsage: usage,
+	}
+}
+
 // ensure MemoryProvider implements Provider
 var _ Provider = (*MemoryProvider)(nil)
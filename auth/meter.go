@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DayFormat is the layout of the days of daily usage, in UTC.
+const DayFormat = "2006-01-02"
+
+// UsageMeter buffers the calls of each key in memory and writes them to the
+// provider on Flush, so the provider is not hit on every request.
+type UsageMeter struct {
+	provider Provider
+
+	lock  sync.Mutex
+	usage map[meterKey]*KeyUsage
+
+	// held while writing to the provider, so a key is not rotated under a
+	// flush that still writes its usage
+	flush sync.Mutex
+}
+
+// usage is kept per day so every flushed KeyUsage falls on a single day.
+type meterKey struct {
+	key string
+	day string
+}
+
+func NewUsageMeter(provider Provider) *UsageMeter {
+	return &UsageMeter{
+		provider: provider,
+		usage:    make(map[meterKey]*KeyUsage),
+	}
+}
+
+// Record adds a call of method by key, rows is the number of results.
+func (m *UsageMeter) Record(key string, method string, ip string, at time.Time, rows int64) {
+	if key == "" {
+		return
+	}
+
+	mk := meterKey{key: key, day: at.UTC().Format(DayFormat)}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	u, ok := m.usage[mk]
+	if !ok {
+		u = &KeyUsage{
+			Day:         mk.day,
+			MethodUsage: make(map[string]int64),
+			MethodRows:  make(map[string]int64),
+		}
+		m.usage[mk] = u
+	}
+
+	u.Requests++
+	u.Rows += rows
+	u.MethodUsage[method]++
+	u.MethodRows[method] += rows
+
+	if at.Unix() >= u.AccessedAt {
+		u.AccessedAt = at.Unix()
+		u.IP = ip
+	}
+}
+
+// Flush writes the buffered usage. Usage of keys the provider does not know,
+// like the master key, is dropped.
+func (m *UsageMeter) Flush() {
+	m.flush.Lock()
+	defer m.flush.Unlock()
+
+	m.lock.Lock()
+	usage := m.usage
+	m.usage = make(map[meterKey]*KeyUsage)
+	m.lock.Unlock()
+
+	for mk, u := range usage {
+		if err := m.provider.UpdateUsage(mk.key, *u); err != nil && err != ErrInvalidKey {
+			slog.Error("failed to update key usage", "day", mk.day, "err", err)
+		}
+	}
+}
+
+// RotateKey rotates key with the provider and moves the usage buffered for it
+// to the rotated key, the provider drops usage of a key that no longer exists.
+func (m *UsageMeter) RotateKey(key string) (string, error) {
+	m.flush.Lock()
+	defer m.flush.Unlock()
+
+	rotated, err := m.provider.RotateKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for mk, u := range m.usage {
+		if mk.key != key {
+			continue
+		}
+
+		delete(m.usage, mk)
+		m.usage[meterKey{key: rotated, day: mk.day}] = u
+	}
+
+	return rotated, nil
+}
+
+// Run flushes every interval until ctx is done.
+func (m *UsageMeter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			m.Flush()
+			return
+		case <-ticker.C:
+			m.Flush()
+		}
+	}
+}
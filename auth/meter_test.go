@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUsageMeter(t *testing.T) {
+	p := NewMemoryProvider()
+	key, err := p.Register(KeyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day1 := time.Date(2024, 4, 1, 23, 59, 0, 0, time.UTC)
+	day2 := day1.Add(2 * time.Minute)
+
+	m := NewUsageMeter(p)
+	m.Record(key, "idx_findPairs", "10.0.0.1", day1, 100)
+	m.Record(key, "idx_findPairs", "10.0.0.2", day2, 50)
+	m.Record(key, "idx_getChains", "10.0.0.3", day1.Add(30*time.Second), 2)
+	// unknown keys are dropped on flush
+	m.Record("unknown", "idx_getChains", "10.0.0.4", day1, 1)
+	m.Flush()
+
+	stats, err := p.GetKeyStats(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.CallCount != 3 || stats.RowCount != 152 || stats.MethodUsage["idx_findPairs"] != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	if stats.LastIP != "10.0.0.2" || stats.LastAccess != day2.Unix() {
+		t.Fatalf("unexpected last access %s at %d", stats.LastIP, stats.LastAccess)
+	}
+
+	days, err := p.GetDailyUsage(key, "2024-04-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(days) != 2 || days[0].Day != "2024-04-01" || days[0].Requests != 2 || days[0].Rows != 102 || days[1].Rows != 50 {
+		t.Fatalf("unexpected daily usage %+v", days)
+	}
+
+	days, err = p.GetDailyUsage(key, "2024-04-02")
+	if err != nil || len(days) != 1 {
+		t.Fatalf("unexpected daily usage %+v, err %v", days, err)
+	}
+
+	// flushed usage is not written twice
+	m.Flush()
+	if stats, _ := p.GetKeyStats(key); stats.CallCount != 3 {
+		t.Fatalf("usage written twice %+v", stats)
+	}
+}
+
+func TestUsageMeterRotateKey(t *testing.T) {
+	p := NewMemoryProvider()
+	key, err := p.Register(KeyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewUsageMeter(p)
+	m.Record(key, "idx_getChains", "10.0.0.1", time.Now(), 1)
+
+	rotated, err := m.RotateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Flush()
+
+	stats, err := p.GetKeyStats(rotated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.CallCount != 1 {
+		t.Fatalf("usage of the old key was lost %+v", stats)
+	}
+}
@@ -1,6 +1,10 @@
 package auth
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/autoapev1/indexer/types"
+)
 
 type NoAuthProvider struct{}
 
@@ -9,11 +13,11 @@ func NewNoAuthProvider() *NoAuthProvider {
 }
 
 // with no auth, highest auth level is defualt
-func (a *NoAuthProvider) Authenticate(r *http.Request) (AuthLevel, error) {
-	return AuthLevelMaster, nil
+func (a *NoAuthProvider) Authenticate(r *http.Request) (AuthLevel, *KeyInfo, error) {
+	return AuthLevelMaster, nil, nil
 }
 
-func (a *NoAuthProvider) Register() (string, error) {
+func (a *NoAuthProvider) Register(opts KeyOptions) (string, error) {
 	return "", nil
 }
 
@@ -21,5 +25,46 @@ func (a *NoAuthProvider) UpdateUsage(key string, usageDelta KeyUsage) error {
 	return nil
 }
 
+// there are no keys without auth
+func (a *NoAuthProvider) DeleteKey(key string) (bool, error) {
+	return false, nil
+}
+
+func (a *NoAuthProvider) RevokeKey(key string) (bool, error) {
+	return false, nil
+}
+
+func (a *NoAuthProvider) GetKeyStats(key string) (*types.KeyStats, error) {
+	return nil, ErrInvalidKey
+}
+
+func (a *NoAuthProvider) ListKeys() ([]*types.KeyStats, error) {
+	return []*types.KeyStats{}, nil
+}
+
+func (a *NoAuthProvider) ExtendKey(key string, exp int64) (bool, error) {
+	return false, nil
+}
+
+func (a *NoAuthProvider) SetKeyScopes(key string, scopes *types.KeyScopes) (bool, error) {
+	return false, nil
+}
+
+func (a *NoAuthProvider) SetKeyTier(key string, tier string) (bool, error) {
+	return false, nil
+}
+
+func (a *NoAuthProvider) RotateKey(key string) (string, error) {
+	return "", ErrInvalidKey
+}
+
+func (a *NoAuthProvider) ListExpiringKeys(before int64) ([]*types.KeyStats, error) {
+	return []*types.KeyStats{}, nil
+}
+
+func (a *NoAuthProvider) GetDailyUsage(key string, from string) ([]*types.KeyDailyUsage, error) {
+	return nil, ErrInvalidKey
+}
+
 // ensure NoAuth implements Provider
 var _ Provider = (*NoAuthProvider)(nil)
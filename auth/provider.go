@@ -3,6 +3,9 @@ package auth
 import (
 	"errors"
 	"net/http"
+	"strings"
+
+	"github.com/autoapev1/indexer/types"
 )
 
 type AuthProvider string
@@ -11,6 +14,7 @@ const (
 	AuthProviderNoAuth AuthProvider = "noauth"
 	AuthProviderMemory AuthProvider = "memory"
 	AuthProviderSql    AuthProvider = "sql"
+	AuthProviderJWT    AuthProvider = "jwt"
 )
 
 func ToProvider(s string) AuthProvider {
@@ -21,20 +25,55 @@ func ToProvider(s string) AuthProvider {
 		return AuthProviderMemory
 	case "sql":
 		return AuthProviderSql
+	case "jwt":
+		return AuthProviderJWT
 	default:
 		return AuthProviderNoAuth
 	}
 }
 
 type Provider interface {
-	// Authenticate authenticates a request.
-	Authenticate(r *http.Request) (AuthLevel, error)
-	Register() (key string, err error)
+	// Authenticate authenticates a request, the info is nil for the master
+	// key.
+	Authenticate(r *http.Request) (AuthLevel, *KeyInfo, error)
+	Register(opts KeyOptions) (key string, err error)
 	UpdateUsage(key string, usageDelta KeyUsage) error
+	// DeleteKey removes a key and its usage, false if the key does not exist.
+	DeleteKey(key string) (bool, error)
+	// RevokeKey stops a key from authenticating but keeps its usage, false if
+	// the key does not exist.
+	RevokeKey(key string) (bool, error)
+	// GetKeyStats returns ErrInvalidKey if the key does not exist.
+	GetKeyStats(key string) (*types.KeyStats, error)
+	ListKeys() ([]*types.KeyStats, error)
+	// ExtendKey sets the exp of a key, 0 never expires. False if the key does
+	// not exist.
+	ExtendKey(key string, exp int64) (bool, error)
+	// SetKeyScopes replaces the scopes of a key, nil removes them. False if
+	// the key does not exist.
+	SetKeyScopes(key string, scopes *types.KeyScopes) (bool, error)
+	// SetKeyTier assigns a key to a tier, empty for the default tier. False
+	// if the key does not exist.
+	SetKeyTier(key string, tier string) (bool, error)
+	// RotateKey replaces the secret of a key, keeping its usage and metadata.
+	// It returns ErrInvalidKey if the key does not exist.
+	RotateKey(key string) (string, error)
+	// ListExpiringKeys returns the unrevoked keys that expire between now and
+	// before, soonest first.
+	ListExpiringKeys(before int64) ([]*types.KeyStats, error)
+	// GetDailyUsage returns the usage of a key per day since from, in
+	// DayFormat, oldest first.
+	GetDailyUsage(key string, from string) ([]*types.KeyDailyUsage, error)
 }
 
 var (
 	ErrCheckingAuth = errors.New("unable to check auth")
 	ErrUnauthorized = errors.New("unauthorized")
 	ErrInvalidKey   = errors.New("invalid key")
+	ErrExpiredKey   = errors.New("expired key")
 )
+
+// KeyFromRequest returns the api key of the Authentication header.
+func KeyFromRequest(r *http.Request) string {
+	return strings.TrimPrefix(r.Header.Get("Authentication"), "Bearer ")
+}
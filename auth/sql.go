@@ -3,11 +3,14 @@ package auth
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/autoapev1/indexer/config"
+	"github.com/autoapev1/indexer/types"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/pgdialect"
 	"github.com/uptrace/bun/driver/pgdriver"
@@ -19,8 +22,9 @@ func NewSqlDB(uri string) *bun.DB {
 }
 
 type SqlAuthProvider struct {
-	db      *bun.DB
-	keyType KeyType
+	db            *bun.DB
+	keyType       KeyType
+	defaultExpiry time.Duration
 }
 
 type sqlKey struct {
@@ -28,9 +32,15 @@ type sqlKey struct {
 	Key           string                `bun:"key,pk"`
 	Iat           int64                 `bun:"iat"`
 	Exp           int64                 `bun:"exp"`
+	Revoked       bool                  `bun:"revoked,notnull,default:false"`
+	Owner         string                `bun:"owner,notnull,default:''"`
+	Notes         string                `bun:"notes,notnull,default:''"`
+	Scopes        *types.KeyScopes      `bun:"scopes,type:jsonb"`
+	Tier          string                `bun:"tier,notnull,default:''"`
 	LastIP        string                `bun:"last_ip"`
 	LastAccess    int64                 `bun:"last_access"`
 	CallCount     int64                 `bun:"call_count"`
+	RowCount      int64                 `bun:"row_count,notnull,default:0"`
 	MethodUsages  []*sqlMethodUsage     `bun:"rel:has-many,join:key=key"`
 }
 
@@ -40,12 +50,25 @@ type sqlMethodUsage struct {
 	Key           string                         `bun:"key"`
 	MethodName    string                         `bun:"method_name"`
 	UsageCount    int64                          `bun:"usage_count"`
+	RowCount      int64                          `bun:"row_count,notnull,default:0"`
+}
+
+// sqlDailyUsage is the usage of a method by a key on a day.
+type sqlDailyUsage struct {
+	bun.BaseModel `bun:"key_usages_daily,alias:kd"` // Table name: key_usages_daily
+	ID            int64                             `bun:",pk,autoincrement"`
+	Key           string                            `bun:"key,notnull"`
+	Day           string                            `bun:"day,notnull"` // DayFormat
+	MethodName    string                            `bun:"method_name,notnull"`
+	UsageCount    int64                             `bun:"usage_count,notnull,default:0"`
+	RowCount      int64                             `bun:"row_count,notnull,default:0"`
 }
 
 func NewSqlAuthProvider(db *bun.DB) *SqlAuthProvider {
 	provider := &SqlAuthProvider{
-		db:      db,
-		keyType: KeyTypeHex64,
+		db:            db,
+		keyType:       KeyTypeHex64,
+		defaultExpiry: time.Hour * 24 * 30 * 3, // 3 months
 	}
 
 	provider.initTables()
@@ -57,7 +80,17 @@ func (a *SqlAuthProvider) WithKeyType(keyType KeyType) *SqlAuthProvider {
 	return a
 }
 
-// create table and index
+func (a *SqlAuthProvider) WithDefaultExpiry(defaultExpiry time.Duration) *SqlAuthProvider {
+	if defaultExpiry < 0 {
+		slog.Warn("invalid default expiry, using default value", "default_expiry", defaultExpiry.String())
+		return a
+	}
+
+	a.defaultExpiry = defaultExpiry
+	return a
+}
+
+// create tables and index
 func (a *SqlAuthProvider) initTables() {
 	_, err := a.db.NewCreateTable().
 		Model((*sqlKey)(nil)).
@@ -67,6 +100,75 @@ func (a *SqlAuthProvider) initTables() {
 		slog.Error("error creating table keys", "err", err)
 	}
 
+	_, err = a.db.NewCreateTable().
+		Model((*sqlMethodUsage)(nil)).
+		IfNotExists().
+		Exec(context.Background())
+	if err != nil {
+		slog.Error("error creating table method_usages", "err", err)
+	}
+
+	_, err = a.db.NewCreateTable().
+		Model((*sqlDailyUsage)(nil)).
+		IfNotExists().
+		Exec(context.Background())
+	if err != nil {
+		slog.Error("error creating table key_usages_daily", "err", err)
+	}
+
+	// keys created before revocation, metadata, row counts, scopes and tiers
+	keyColumns := []string{
+		"revoked BOOLEAN NOT NULL DEFAULT false",
+		"owner VARCHAR NOT NULL DEFAULT ''",
+		"notes VARCHAR NOT NULL DEFAULT ''",
+		"row_count BIGINT NOT NULL DEFAULT 0",
+		"scopes JSONB",
+		"tier VARCHAR NOT NULL DEFAULT ''",
+	}
+
+	for _, column := range keyColumns {
+		_, err = a.db.NewAddColumn().
+			Model((*sqlKey)(nil)).
+			IfNotExists().
+			ColumnExpr(column).
+			Exec(context.Background())
+		if err != nil {
+			slog.Error("error adding column to keys", "column", column, "err", err)
+		}
+	}
+
+	_, err = a.db.NewAddColumn().
+		Model((*sqlMethodUsage)(nil)).
+		IfNotExists().
+		ColumnExpr("row_count BIGINT NOT NULL DEFAULT 0").
+		Exec(context.Background())
+	if err != nil {
+		slog.Error("error adding column method_usages.row_count", "err", err)
+	}
+
+	// usage is upserted
+	indexes := []struct {
+		model   interface{}
+		name    string
+		columns []string
+	}{
+		{(*sqlMethodUsage)(nil), "method_usages_key_method_inx", []string{"key", "method_name"}},
+		{(*sqlDailyUsage)(nil), "key_usages_daily_key_day_method_inx", []string{"key", "day", "method_name"}},
+	}
+
+	for _, index := range indexes {
+		_, err = a.db.NewCreateIndex().
+			Model(index.model).
+			Index(index.name).
+			Column(index.columns...).
+			Unique().
+			IfNotExists().
+			Exec(context.Background())
+		if err != nil {
+			slog.Error("error creating index", "index", index.name, "err", err)
+		}
+	}
+
 	_, err = a.db.NewCreateIndex().
 		Model((*sqlKey)(nil)).
 		Index("key_inx").
@@ -82,40 +184,51 @@ func (a *SqlAuthProvider) initTables() {
 
 }
 
-func (a *SqlAuthProvider) Authenticate(r *http.Request) (AuthLevel, error) {
+func (a *SqlAuthProvider) Authenticate(r *http.Request) (AuthLevel, *KeyInfo, error) {
 	var sqlKey sqlKey
 
 	// get key from request
-	key := r.Header.Get("Authentication")
-	key = strings.TrimPrefix(key, "Bearer ")
+	key := KeyFromRequest(r)
 
 	// check master
 	master := config.Get().API.AuthMasterKey
 	if master != "" && key == master {
-		return AuthLevelMaster, nil
+		return AuthLevelMaster, nil, nil
 	}
 
 	// search db for key
 	err := a.db.NewSelect().
 		Model(&sqlKey).
 		Where("key = ?", key).
+		Where("NOT revoked").
 		Scan(context.Background())
 
 	if err != nil {
-		return AuthLevelUnauthorized, ErrUnauthorized
+		return AuthLevelUnauthorized, nil, ErrUnauthorized
+	}
+
+	if isExpired(sqlKey.Exp, time.Now()) {
+		return AuthLevelUnauthorized, nil, ErrExpiredKey
 	}
 
-	return AuthLevelBasic, nil
+	return AuthLevelBasic, &KeyInfo{Scopes: sqlKey.Scopes, Tier: sqlKey.Tier}, nil
 }
 
-func (a *SqlAuthProvider) Register() (string, error) {
+func (a *SqlAuthProvider) Register(opts KeyOptions) (string, error) {
 	key, err := GenerateKey(a.keyType)
 	if err != nil {
 		return "", err
 	}
 
+	now := time.Now()
 	sqlKey := &sqlKey{
 		Key:          key,
+		Iat:          now.Unix(),
+		Exp:          opts.expiry(now, a.defaultExpiry),
+		Owner:        opts.Owner,
+		Notes:        opts.Notes,
+		Scopes:       opts.Scopes,
+		Tier:         opts.Tier,
 		MethodUsages: make([]*sqlMethodUsage, 0),
 	}
 
@@ -128,6 +241,7 @@ func (a *SqlAuthProvider) Register() (string, error) {
 
 	return key, nil
 }
+
 func (a *SqlAuthProvider) UpdateUsage(key string, usageDelta KeyUsage) error {
 	// Start a transaction
 	tx, err := a.db.Begin()
@@ -139,48 +253,62 @@ func (a *SqlAuthProvider) UpdateUsage(key string, usageDelta KeyUsage) error {
 	defer tx.Rollback()
 
 	// Update the key usage
-	_, err = tx.NewUpdate().
+	res, err := tx.NewUpdate().
 		Model((*sqlKey)(nil)).
 		Where("key = ?", key).
 		Set("call_count = call_count + ?", usageDelta.Requests).
-		Set("last_access = ?", usageDelta.AccessedAt).
-		Set("last_ip = ?", usageDelta.IP).
+		Set("row_count = row_count + ?", usageDelta.Rows).
+		Set("last_access = GREATEST(last_access, ?)", usageDelta.AccessedAt).
+		Set("last_ip = CASE WHEN last_access > ? THEN last_ip ELSE ? END", usageDelta.AccessedAt, usageDelta.IP).
 		Exec(context.Background())
 	if err != nil {
 		return err
 	}
 
-	// Update method usages
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrInvalidKey
+	}
+
+	// Upsert method usages and the daily rollup
 	for method, count := range usageDelta.MethodUsage {
-		var methodUsage sqlMethodUsage
-		_, err := tx.NewSelect().
-			Model(&methodUsage).
-			Where("key = ? AND method_name = ?", key, method).
-			Exec(context.Background())
+		rows := usageDelta.MethodRows[method]
 
-		if err != nil && err != sql.ErrNoRows {
-			// Handle errors other than 'no rows found'
+		_, err = tx.NewInsert().
+			Model(&sqlMethodUsage{
+				Key:        key,
+				MethodName: method,
+				UsageCount: count,
+				RowCount:   rows,
+			}).
+			On("CONFLICT (key, method_name) DO UPDATE").
+			Set("usage_count = mu.usage_count + EXCLUDED.usage_count").
+			Set("row_count = mu.row_count + EXCLUDED.row_count").
+			Exec(context.Background())
+		if err != nil {
 			return err
 		}
 
-		if methodUsage.MethodUsageID == 0 {
-			// Insert new method usage
-			_, err = tx.NewInsert().
-				Model(&sqlMethodUsage{
-					Key:        key,
-					MethodName: method,
-					UsageCount: count,
-				}).
-				Exec(context.Background())
-		} else {
-			// Update existing method usage
-			_, err = tx.NewUpdate().
-				Model(&sqlMethodUsage{}).
-				Set("usage_count = usage_count + ?", count).
-				Where("key = ? AND method_name = ?", key, method).
-				Exec(context.Background())
+		if usageDelta.Day == "" {
+			continue
 		}
 
+		_, err = tx.NewInsert().
+			Model(&sqlDailyUsage{
+				Key:        key,
+				Day:        usageDelta.Day,
+				MethodName: method,
+				UsageCount: count,
+				RowCount:   rows,
+			}).
+			On("CONFLICT (key, day, method_name) DO UPDATE").
+			Set("usage_count = kd.usage_count + EXCLUDED.usage_count").
+			Set("row_count = kd.row_count + EXCLUDED.row_count").
+			Exec(context.Background())
 		if err != nil {
 			return err
 		}
@@ -190,4 +318,303 @@ func (a *SqlAuthProvider) UpdateUsage(key string, usageDelta KeyUsage) error {
 	return tx.Commit()
 }
 
+func (a *SqlAuthProvider) DeleteKey(key string) (bool, error) {
+	tx, err := a.db.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.NewDelete().
+		Model((*sqlMethodUsage)(nil)).
+		Where("key = ?", key).
+		Exec(context.Background())
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.NewDelete().
+		Model((*sqlDailyUsage)(nil)).
+		Where("key = ?", key).
+		Exec(context.Background())
+	if err != nil {
+		return false, err
+	}
+
+	res, err := tx.NewDelete().
+		Model((*sqlKey)(nil)).
+		Where("key = ?", key).
+		Exec(context.Background())
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, tx.Commit()
+}
+
+func (a *SqlAuthProvider) RevokeKey(key string) (bool, error) {
+	res, err := a.db.NewUpdate().
+		Model((*sqlKey)(nil)).
+		Set("revoked = true").
+		Where("key = ?", key).
+		Exec(context.Background())
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+func (a *SqlAuthProvider) GetKeyStats(key string) (*types.KeyStats, error) {
+	var k sqlKey
+
+	err := a.db.NewSelect().
+		Model(&k).
+		Relation("MethodUsages").
+		Where("ku.key = ?", key).
+		Scan(context.Background())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidKey
+		}
+		return nil, err
+	}
+
+	return k.stats(), nil
+}
+
+func (a *SqlAuthProvider) ListKeys() ([]*types.KeyStats, error) {
+	var keys []*sqlKey
+
+	err := a.db.NewSelect().
+		Model(&keys).
+		Relation("MethodUsages").
+		Order("ku.iat ASC", "ku.key ASC").
+		Scan(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]*types.KeyStats, 0, len(keys))
+	for _, k := range keys {
+		stats = append(stats, k.stats())
+	}
+
+	return stats, nil
+}
+
+func (a *SqlAuthProvider) ExtendKey(key string, exp int64) (bool, error) {
+	res, err := a.db.NewUpdate().
+		Model((*sqlKey)(nil)).
+		Set("exp = ?", exp).
+		Where("key = ?", key).
+		Exec(context.Background())
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+func (a *SqlAuthProvider) SetKeyScopes(key string, scopes *types.KeyScopes) (bool, error) {
+	var value interface{}
+	if scopes != nil {
+		b, err := json.Marshal(scopes)
+		if err != nil {
+			return false, err
+		}
+		value = string(b)
+	}
+
+	res, err := a.db.NewUpdate().
+		Model((*sqlKey)(nil)).
+		Set("scopes = ?::jsonb", value).
+		Where("key = ?", key).
+		Exec(context.Background())
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+func (a *SqlAuthProvider) SetKeyTier(key string, tier string) (bool, error) {
+	res, err := a.db.NewUpdate().
+		Model((*sqlKey)(nil)).
+		Set("tier = ?", tier).
+		Where("key = ?", key).
+		Exec(context.Background())
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+func (a *SqlAuthProvider) RotateKey(key string) (string, error) {
+	rotated, err := GenerateKey(a.keyType)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := a.db.Begin()
+	if err != nil {
+		return "", err
+	}
+
+	defer tx.Rollback()
+
+	res, err := tx.NewUpdate().
+		Model((*sqlKey)(nil)).
+		Set("key = ?", rotated).
+		Where("key = ?", key).
+		Exec(context.Background())
+	if err != nil {
+		return "", err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return "", err
+	}
+
+	if n == 0 {
+		return "", ErrInvalidKey
+	}
+
+	_, err = tx.NewUpdate().
+		Model((*sqlMethodUsage)(nil)).
+		Set("key = ?", rotated).
+		Where("key = ?", key).
+		Exec(context.Background())
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tx.NewUpdate().
+		Model((*sqlDailyUsage)(nil)).
+		Set("key = ?", rotated).
+		Where("key = ?", key).
+		Exec(context.Background())
+	if err != nil {
+		return "", err
+	}
+
+	return rotated, tx.Commit()
+}
+
+func (a *SqlAuthProvider) ListExpiringKeys(before int64) ([]*types.KeyStats, error) {
+	var keys []*sqlKey
+
+	err := a.db.NewSelect().
+		Model(&keys).
+		Relation("MethodUsages").
+		Where("NOT ku.revoked").
+		Where("ku.exp > ?", time.Now().Unix()).
+		Where("ku.exp <= ?", before).
+		Order("ku.exp ASC", "ku.key ASC").
+		Scan(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]*types.KeyStats, 0, len(keys))
+	for _, k := range keys {
+		stats = append(stats, k.stats())
+	}
+
+	return stats, nil
+}
+
+func (a *SqlAuthProvider) GetDailyUsage(key string, from string) ([]*types.KeyDailyUsage, error) {
+	exists, err := a.db.NewSelect().
+		Model((*sqlKey)(nil)).
+		Where("key = ?", key).
+		Exists(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, ErrInvalidKey
+	}
+
+	var rows []*sqlDailyUsage
+	err = a.db.NewSelect().
+		Model(&rows).
+		Where("key = ?", key).
+		Where("day >= ?", from).
+		Order("day ASC", "method_name ASC").
+		Scan(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	days := []*types.KeyDailyUsage{}
+	for _, row := range rows {
+		if len(days) == 0 || days[len(days)-1].Day != row.Day {
+			days = append(days, &types.KeyDailyUsage{
+				Day:         row.Day,
+				MethodUsage: make(map[string]int64),
+			})
+		}
+
+		day := days[len(days)-1]
+		day.Requests += row.UsageCount
+		day.Rows += row.RowCount
+		day.MethodUsage[row.MethodName] += row.UsageCount
+	}
+
+	return days, nil
+}
+
+func (k *sqlKey) stats() *types.KeyStats {
+	usage := make(map[string]int64, len(k.MethodUsages))
+	for _, u := range k.MethodUsages {
+		usage[u.MethodName] += u.UsageCount
+	}
+
+	return &types.KeyStats{
+		Key:         k.Key,
+		Iat:         k.Iat,
+		Exp:         k.Exp,
+		Revoked:     k.Revoked,
+		Owner:       k.Owner,
+		Notes:       k.Notes,
+		Scopes:      k.Scopes,
+		Tier:        k.Tier,
+		LastIP:      k.LastIP,
+		LastAccess:  k.LastAccess,
+		CallCount:   k.CallCount,
+		RowCount:    k.RowCount,
+		MethodUsage: usage,
+	}
+}
+
 var _ Provider = (*SqlAuthProvider)(nil)
@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/autoapev1/indexer/api"
 	"github.com/autoapev1/indexer/config"
 	"github.com/autoapev1/indexer/storage"
+	"github.com/autoapev1/indexer/tracing"
 	"github.com/autoapev1/indexer/utils"
 	"github.com/autoapev1/indexer/version"
 )
@@ -31,6 +36,11 @@ func main() {
 	conf := config.Get()
 	_ = conf
 
+	shutdownTracing, err := tracing.Init(context.Background(), conf.Tracing)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	storeMap := storage.NewStoreMap()
 
 	for _, v := range conf.Chains {
@@ -52,7 +62,31 @@ func main() {
 
 	server := api.NewServer(conf, storeMap)
 
-	log.Fatal(server.Listen(utils.ToAddress(conf.API.Host, conf.API.Port)))
+	errc := make(chan error, 1)
+	go func() {
+		errc <- server.Listen(utils.ToAddress(conf.API.Host, conf.API.Port))
+	}()
+
+	sig, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	select {
+	case err = <-errc:
+	case <-sig.Done():
+		slog.Info("shutting down")
+	}
+
+	// let the calls in flight finish, then flush the spans of the last requests
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if serr := server.Shutdown(ctx); serr != nil {
+		slog.Error("failed to shut down the server", "err", serr)
+	}
+	shutdownTracing(ctx)
+
+	if err != nil {
+		log.Fatal(err)
+	}
 }
 
 func banner() string {
@@ -2,6 +2,7 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 
 	"github.com/pelletier/go-toml/v2"
@@ -25,12 +26,64 @@ rpcURL = "http://localhost:8546"
 [api]
 host = "localhost"
 port = 8080
-authDefaultExpirary = 7776000 # 90 days
+corsOrigins = ["*"] # origins browsers and websockets may call from, * is any, the api host is always allowed
+trustedProxies = [] # ips or cidrs of reverse proxies, the client ip is read from their X-Forwarded-For or X-Real-IP headers
+authDefaultExpirary = 7776000 # seconds, 90 days, 0 never expires
 authKeyType = "hex64" # uuid | hex16 | hex32 | hex64 | hex128 | hex256 | jwt
 authMasterKey = "my-master-key" # key to access auth methods
-authProvider = "sql" # sql | memory | noauth
-rateLimitRequests = 500 # max requests per minute
+authProvider = "sql" # sql | memory | jwt | noauth
+authJWTAlgorithm = "HS256" # HS256 | ES256, for the jwt provider
+authJWTSecret = "" # HS256 signing secret
+authJWTKeyFile = "" # ES256 PEM private key, or a public key on replicas that only verify
+authJWTKeyID = "" # kid of minted keys
+authJWKSFile = "" # verification keys by kid, reloaded when the file changes
+authJWTIssuer = "" # iss of minted keys, required on keys when set
+authJWTMaxLifetime = 7776000 # seconds from iat to exp of jwt keys, 90 days, 0 is unlimited, keys without exp are refused
+rateLimitRequests = 500 # max cost per minute, see methodCosts
 rateLimitStrategy = "ip" # ip | key (requires auth)
+rateLimitAlgorithm = "token-bucket" # token-bucket | sliding-log | fixed-window
+usageFlushInterval = 10 # seconds between key usage writes, 0 disables metering
+defaultTier = "free" # tier of keys without one, empty leaves them without tier limits
+routesRefresh = 15 # seconds between route graph refreshes, 0 disables idx_findRoutes
+routesMinLiquidity = 0 # usd, pairs below this are left out of the route graph
+feedInterval = 3 # seconds between checks for new rows, 0 disables webhooks and subscriptions
+webhookMaxAttempts = 5 # retried with exponential backoff, then dead lettered
+webhookTimeout = 10 # seconds
+webhookAllowPrivate = false # allow webhook urls on loopback, private and link-local addresses, for local development
+graphqlMaxComplexity = 1000 # query cost allowed for basic keys, master keys get 10x, 0 disables /graphql
+grpcPort = 9090 # 0 disables the grpc server
+metrics = true # serve prometheus metrics at /metrics
+metricsPort = 0 # serve /metrics on its own port, 0 serves it on the api port to the master key only
+healthMaxLag = 100 # blocks a stage can be behind the chain head before /readyz fails, 0 disables the lag check
+
+# rate limit cost of a call, 1 unless set here or in the method registry
+[api.methodCosts]
+# idx_getLogs = 20
+
+# subscription tiers, 0 or an empty list is unlimited
+[[api.tiers]]
+name = "free"
+requestsPerMinute = 60
+requestsPerDay = 5000
+requestsPerMonth = 100000
+maxBatchSize = 10
+maxLimit = 100 # max limit of list queries
+methods = ["idx_*", "rpc.discover"] # * wildcards
+
+[[api.tiers]]
+name = "pro"
+requestsPerMinute = 600
+requestsPerDay = 200000
+requestsPerMonth = 5000000
+maxBatchSize = 100
+maxLimit = 1000
+
+[[api.tiers]]
+name = "enterprise"
+maxBatchSize = 1000
+
+[sync]
+pollInterval = 3 # seconds between chain head checks once caught up
 
 [sync.pairs]
 batchConcurrency = 2
@@ -45,6 +98,25 @@ batchSize = 10
 batchConcurrency = 2
 batchSize = 10
 
+[sync.reserves]
+blockRange = 100
+
+[sync.logs]
+blockRange = 1000
+
+[sync.wallets]
+blockRange = 20
+minTransferUSD = 10000 # token transfers of watched wallets below this are ignored
+
+[tracing]
+exporter = "" # otlp | stdout, empty disables tracing
+endpoint = "localhost:4318" # otlp http collector
+insecure = true # otlp without tls
+file = "" # stdout exporter writes here instead when set
+sampleRatio = 1 # fraction of new traces sampled, incoming sampled traces are always kept
+serviceName = "indexer"
+statements = false # record the sql of queries on spans, with their parameters like key hashes and webhook secrets
+
 # currently only postgres is supported
 [storage.postgres]
 host = "localhost"
@@ -81,6 +153,8 @@ type Config struct {
 	Storage StorageConfig
 
 	API APIConfig
+
+	Tracing TracingConfig
 }
 
 type ChainConfig struct {
@@ -89,12 +163,84 @@ type ChainConfig struct {
 	ShortName   string
 	ExplorerURL string
 	RPCURL      string
+	StartBlock  int64 // first block of the block headers stage
+	Factories   []FactoryConfig
+
+	// pricing, used to value liquidity in usd
+	WrappedNative string
+	Stablecoins   []string
+
+	// raw logs store, off unless enabled
+	Logs LogsConfig
+
+	// user defined event indexers
+	Events []EventsConfig
+}
+
+// EventsConfig indexes the events of a contract set into typed tables, one
+// table per event named <table>_<event>, with columns taken from the abi.
+type EventsConfig struct {
+	Table      string   // table prefix, also identifies the indexer
+	ABI        string   // path to a json abi
+	Events     []string // event names, every event in the abi if empty
+	Addresses  []string
+	Factory    EventsFactoryConfig // optional, discovers more addresses
+	StartBlock int64
+}
+
+// EventsFactoryConfig adds the contracts announced by a factory event to an
+// events indexer, e.g. the pool field of PoolCreated.
+type EventsFactoryConfig struct {
+	Address string
+	ABI     string // defaults to the indexer abi
+	Event   string
+	Field   string // event input holding the new contract address
+}
+
+// LogsConfig selects the raw logs kept for idx_getLogs. A log is kept when it
+// matches the address allowlist and the topic0 allowlist, an empty list
+// matches anything but at least one list must be set.
+type LogsConfig struct {
+	Enabled    bool
+	StartBlock int64
+	Addresses  []string
+	Topics     []string // topic0
+}
+
+// Active reports whether logs are kept, keeping every log of a chain is not
+// supported so an allowlist is required.
+func (c LogsConfig) Active() bool {
+	return c.Enabled && (len(c.Addresses) > 0 || len(c.Topics) > 0)
+}
+
+// FactoryConfig describes an additional pair factory to index on a chain.
+type FactoryConfig struct {
+	Name     string // dex name, e.g. "velodrome"
+	Protocol string // solidly
+	Address  string
 }
 
 type SyncConfig struct {
+	PollInterval    int // seconds between chain head checks once caught up
 	Tokens          TokensSyncConfig
 	Pairs           PairsSyncConfig
 	BlockTimestamps BlockTimestampsSyncConfig
+	Reserves        ReservesSyncConfig
+	Logs            LogsSyncConfig
+	Wallets         WalletsSyncConfig
+}
+
+type WalletsSyncConfig struct {
+	BlockRange     int
+	MinTransferUSD float64 // default threshold for watched wallet transfers
+}
+
+type LogsSyncConfig struct {
+	BlockRange int
+}
+
+type ReservesSyncConfig struct {
+	BlockRange int
 }
 
 type BlockTimestampsSyncConfig struct {
@@ -127,14 +273,73 @@ type PostgresConfig struct {
 }
 
 type APIConfig struct {
-	Host                string
-	Port                int
-	AuthProvider        string
-	AuthKeyType         string
-	AuthDefaultExpirary int64
-	AuthMasterKey       string
-	RateLimitStrategy   string
-	RateLimitRequests   int
+	Host                 string
+	Port                 int
+	CORSOrigins          []string // origins browsers and websockets may call from, * is any
+	TrustedProxies       []string // ips or cidrs of reverse proxies whose forwarding headers are trusted
+	AuthProvider         string
+	AuthKeyType          string
+	AuthDefaultExpirary  int64 // seconds new keys are valid for, 0 never expires
+	AuthMasterKey        string
+	AuthJWTAlgorithm     string // HS256 | ES256
+	AuthJWTSecret        string // HS256 signing secret
+	AuthJWTKeyFile       string // ES256 PEM private key, or a public key to only verify
+	AuthJWTKeyID         string // kid of minted keys
+	AuthJWKSFile         string // verification keys by kid, reloaded when the file changes
+	AuthJWTIssuer        string // iss of minted keys, required on keys when set
+	AuthJWTMaxLifetime   int64  // seconds from iat to exp of jwt keys, 0 is unlimited
+	RateLimitStrategy    string
+	RateLimitRequests    int
+	RateLimitAlgorithm   string         // token-bucket | sliding-log | fixed-window
+	MethodCosts          map[string]int // rate limit cost of a call by method
+	UsageFlushInterval   int            // seconds between key usage writes, 0 disables metering
+	DefaultTier          string         // tier of keys without one, empty leaves them without tier limits
+	Tiers                []TierConfig
+	RoutesRefresh        int     // seconds between route graph refreshes, 0 disables idx_findRoutes
+	RoutesMinLiquidity   float64 // pairs below this usd liquidity are left out of the route graph
+	FeedInterval         int     // seconds between checks for new rows, 0 disables webhooks and subscriptions
+	WebhookMaxAttempts   int     // delivery attempts before a payload is dead lettered
+	WebhookTimeout       int     // seconds
+	WebhookAllowPrivate  bool    // allow webhook urls on loopback, private and link-local addresses
+	GraphQLMaxComplexity int     // query cost allowed for basic keys, 0 disables /graphql
+	GRPCPort             int     // 0 disables the grpc server
+	Metrics              bool    // serve prometheus metrics at /metrics
+	MetricsPort          int     // serve /metrics on its own port, 0 serves it on the api port to the master key
+	HealthMaxLag         int64   // blocks a stage can be behind the chain head before /readyz fails, 0 disables the lag check
+}
+
+// TracingConfig exports opentelemetry spans of the api, database and rpc
+// calls. Trace context is propagated from W3C traceparent headers.
+type TracingConfig struct {
+	Exporter    string  // otlp | stdout, empty disables tracing
+	Endpoint    string  // otlp http collector, host:port
+	Insecure    bool    // otlp without tls
+	File        string  // stdout exporter writes here instead when set
+	SampleRatio float64 // fraction of new traces sampled, 0 samples every trace
+	ServiceName string
+	Statements  bool // record the sql of queries on spans, parameters included
+}
+
+// TierConfig limits the keys of a subscription tier, 0 or an empty list is
+// unlimited. Quotas count JSON-RPC calls, each element of a batch is a call.
+type TierConfig struct {
+	Name              string
+	RequestsPerMinute int // rate limit cost per minute, like api.rateLimitRequests
+	RequestsPerDay    int64
+	RequestsPerMonth  int64 // calendar month, UTC
+	MaxBatchSize      int
+	MaxLimit          int64    // max limit of list queries
+	Methods           []string // allowed methods, * wildcards
+}
+
+// Tier returns the tier named name, nil if there is none.
+func (c APIConfig) Tier(name string) *TierConfig {
+	for i := range c.Tiers {
+		if c.Tiers[i].Name == name {
+			return &c.Tiers[i]
+		}
+	}
+	return nil
 }
 
 func Parse(path string) error {
@@ -150,7 +355,22 @@ func Parse(path string) error {
 	}
 
 	err = toml.Unmarshal(b, &config)
-	return err
+	if err != nil {
+		return err
+	}
+
+	return config.validate()
+}
+
+// validate rejects settings that parse but cannot be served.
+func (c Config) validate() error {
+	for _, chain := range c.Chains {
+		if chain.Logs.Enabled && !chain.Logs.Active() {
+			return fmt.Errorf("chain %d: logs are enabled without an address or topic allowlist", chain.ChainID)
+		}
+	}
+
+	return nil
 }
 
 func Get() Config {
@@ -2,18 +2,74 @@ package eth
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"log/slog"
 	"sync"
 
 	"github.com/autoapev1/indexer/types"
+	"github.com/ethereum/go-ethereum/common"
 	etypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// rpcBlockExtras are the fields of eth_getBlockByNumber not kept by etypes.Header.
+type rpcBlockExtras struct {
+	Hash         common.Hash       `json:"hash"`
+	Transactions []json.RawMessage `json:"transactions"`
+}
+
 func (n *Network) GetBlockTimestamps(ctx context.Context, from int64, to int64) ([]*types.BlockTimestamp, error) {
+	blocks, err := n.GetBlocks(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	blockTimestamps := make([]*types.BlockTimestamp, 0, len(blocks))
+	for _, b := range blocks {
+		blockTimestamps = append(blockTimestamps, b.BlockTimestamp())
+	}
 
-	blockTimestamps := make([]*types.BlockTimestamp, 0, to-from)
+	return blockTimestamps, nil
+}
 
+// GetBlocks returns the blocks between from and to (inclusive). It fails if
+// any block is missing, the stored height is the highest block so a partial
+// result would leave a hole that is never synced.
+func (n *Network) GetBlocks(ctx context.Context, from int64, to int64) ([]*types.Block, error) {
+	var (
+		blocks   = make([]*types.Block, 0, to-from)
+		lock     sync.Mutex
+		firstErr error
+	)
+
+	n.forEachBlockBatch(ctx, from, to, false, func(batch []rpc.BatchElem) {
+		bs, err := n.getBlockBatch(ctx, batch)
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		if err != nil {
+			slog.Error("getBlockBatch", "err", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			return
+		}
+		blocks = append(blocks, bs...)
+	})
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return blocks, nil
+}
+
+// forEachBlockBatch calls fn with batches of eth_getBlockByNumber calls for
+// the blocks between from and to (inclusive), using the block timestamps
+// batch size and concurrency.
+func (n *Network) forEachBlockBatch(ctx context.Context, from int64, to int64, fullTx bool, fn func(batch []rpc.BatchElem)) {
 	batchSize := n.config.Sync.BlockTimestamps.BatchSize
 	concurrency := n.config.Sync.BlockTimestamps.BatchConcurrency
 
@@ -25,7 +81,7 @@ func (n *Network) GetBlockTimestamps(ctx context.Context, from int64, to int64)
 		concurrency = 2
 	}
 
-	batches := n.makeBlockTimestampBatches(from, to, int64(batchSize))
+	batches := n.makeBlockBatches(from, to, int64(batchSize), fullTx)
 
 	workers := make(chan int, concurrency)
 	var wg sync.WaitGroup
@@ -46,22 +102,14 @@ func (n *Network) GetBlockTimestamps(ctx context.Context, from int64, to int64)
 				wg.Done()
 			}()
 
-			bts, err := n.getBlockTimestampBatch(ctx, batch)
-			if err != nil {
-				slog.Error("getBlockTimestampBatch", "err", err)
-				return
-			}
-
-			blockTimestamps = append(blockTimestamps, bts...)
+			fn(batch)
 		}(batch)
 	}
 
 	wg.Wait()
-
-	return blockTimestamps, nil
 }
 
-func (n *Network) makeBlockTimestampBatches(from int64, to int64, batchSize int64) [][]rpc.BatchElem {
+func (n *Network) makeBlockBatches(from int64, to int64, batchSize int64, fullTx bool) [][]rpc.BatchElem {
 	batchCount := (to - from) / batchSize
 	if (to-from)%batchSize != 0 {
 		batchCount++
@@ -79,8 +127,8 @@ func (n *Network) makeBlockTimestampBatches(from int64, to int64, batchSize int6
 		for j := i; j < end; j++ {
 			batch = append(batch, rpc.BatchElem{
 				Method: "eth_getBlockByNumber",
-				Args:   []interface{}{j, false},
-				Result: new(etypes.Header),
+				Args:   []interface{}{j, fullTx},
+				Result: new(json.RawMessage),
 			})
 		}
 		batches = append(batches, batch)
@@ -89,10 +137,10 @@ func (n *Network) makeBlockTimestampBatches(from int64, to int64, batchSize int6
 	return batches
 }
 
-func (n *Network) getBlockTimestampBatch(ctx context.Context, batch []rpc.BatchElem) ([]*types.BlockTimestamp, error) {
-	var blockTimestamps []*types.BlockTimestamp
+func (n *Network) getBlockBatch(ctx context.Context, batch []rpc.BatchElem) ([]*types.Block, error) {
+	var blocks []*types.Block
 
-	if err := n.Client.Client().BatchCallContext(ctx, batch); err != nil {
+	if err := n.batchCall(ctx, batch); err != nil {
 		return nil, err
 	}
 
@@ -103,12 +151,52 @@ func (n *Network) getBlockTimestampBatch(ctx context.Context, batch []rpc.BatchE
 	}
 
 	for _, b := range batch {
-		header := b.Result.(*etypes.Header)
-		blockTimestamps = append(blockTimestamps, &types.BlockTimestamp{
-			Block:     header.Number.Int64(),
-			Timestamp: int64(header.Time),
-		})
+		raw := *b.Result.(*json.RawMessage)
+		if len(raw) == 0 || string(raw) == "null" {
+			return nil, fmt.Errorf("block %v not found", b.Args[0])
+		}
+
+		block, err := decodeBlock(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		blocks = append(blocks, block)
 	}
 
-	return blockTimestamps, nil
+	return blocks, nil
+}
+
+// decodeBlock builds a block from an eth_getBlockByNumber response without
+// transaction bodies, the hash is taken from the node rather than recomputed.
+func decodeBlock(raw json.RawMessage) (*types.Block, error) {
+	header := new(etypes.Header)
+	if err := json.Unmarshal(raw, header); err != nil {
+		return nil, err
+	}
+
+	extras := new(rpcBlockExtras)
+	if err := json.Unmarshal(raw, extras); err != nil {
+		return nil, err
+	}
+
+	block := &types.Block{
+		Number:     header.Number.Int64(),
+		Hash:       extras.Hash.String(),
+		ParentHash: header.ParentHash.String(),
+		Timestamp:  int64(header.Time),
+		BaseFee:    "0",
+		GasUsed:    int64(header.GasUsed),
+		GasLimit:   int64(header.GasLimit),
+		Miner:      header.Coinbase.String(),
+		TxCount:    int64(len(extras.Transactions)),
+	}
+
+	if header.BaseFee != nil {
+		block.BaseFee = header.BaseFee.String()
+	}
+
+	block.Lower()
+
+	return block, nil
 }
@@ -0,0 +1,101 @@
+package eth
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/autoapev1/indexer/types"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	etypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// GetLogs returns the logs between from and to (inclusive) emitted by one of
+// addresses with a topic0 in topics, ordered by block and log index. An empty
+// list matches any address or topic.
+func (n *Network) GetLogs(ctx context.Context, from int64, to int64, addresses []string, topics []string) ([]*types.Log, error) {
+	logs, err := n.FilterLogs(ctx, from, to, addresses, topics)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*types.Log, 0, len(logs))
+	for _, l := range logs {
+		result = append(result, decodeLog(l))
+	}
+
+	return result, nil
+}
+
+// FilterLogs is GetLogs without decoding, removed logs are dropped.
+func (n *Network) FilterLogs(ctx context.Context, from int64, to int64, addresses []string, topics []string) ([]etypes.Log, error) {
+	bRange := toRange(to, from)
+	if err := bRange.validate(); err != nil {
+		return nil, err
+	}
+
+	filter := ethereum.FilterQuery{
+		FromBlock: big.NewInt(bRange.from),
+		ToBlock:   big.NewInt(bRange.to),
+	}
+
+	for _, a := range addresses {
+		filter.Addresses = append(filter.Addresses, common.HexToAddress(a))
+	}
+
+	if len(topics) > 0 {
+		topic0 := make([]common.Hash, 0, len(topics))
+		for _, t := range topics {
+			topic0 = append(topic0, common.HexToHash(t))
+		}
+		filter.Topics = [][]common.Hash{topic0}
+	}
+
+	logs, err := n.filterLogs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]etypes.Log, 0, len(logs))
+	for _, l := range logs {
+		if l.Removed {
+			continue
+		}
+		result = append(result, l)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].BlockNumber == result[j].BlockNumber {
+			return result[i].Index < result[j].Index
+		}
+		return result[i].BlockNumber < result[j].BlockNumber
+	})
+
+	return result, nil
+}
+
+func decodeLog(l etypes.Log) *types.Log {
+	log := &types.Log{
+		BlockNumber: int64(l.BlockNumber),
+		LogIndex:    int64(l.Index),
+		BlockHash:   l.BlockHash.String(),
+		TxHash:      l.TxHash.String(),
+		TxIndex:     int64(l.TxIndex),
+		Address:     l.Address.String(),
+		Data:        hexutil.Encode(l.Data),
+	}
+
+	topics := []*string{&log.Topic0, &log.Topic1, &log.Topic2, &log.Topic3}
+	for i, t := range l.Topics {
+		if i >= len(topics) {
+			break
+		}
+		*topics[i] = t.String()
+	}
+
+	log.Lower()
+
+	return log
+}
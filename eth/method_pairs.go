@@ -7,6 +7,7 @@ import (
 	"math/big"
 	"strings"
 
+	"github.com/autoapev1/indexer/config"
 	"github.com/autoapev1/indexer/types"
 	"github.com/autoapev1/indexer/utils"
 	"github.com/ethereum/go-ethereum"
@@ -19,8 +20,13 @@ type pairMode int
 const (
 	pairModeV2 pairMode = iota
 	pairModeV3
+	pairModeSolidly
 )
 
+// ProtocolSolidly is the protocol name for Solidly/Velodrome style factories
+// that emit PairCreated(address,address,bool,address,uint256).
+const ProtocolSolidly = "solidly"
+
 func (n *Network) GetPairs(ctx context.Context, to int64, from int64) ([]*types.Pair, error) {
 	pairs := make([]*types.Pair, 0)
 
@@ -36,6 +42,7 @@ func (n *Network) GetPairs(ctx context.Context, to int64, from int64) ([]*types.
 	}
 
 	var (
+		dex           string
 		V2factoryAddr string
 		V3factoryAddr string
 		V2factoryABI  string
@@ -45,18 +52,32 @@ func (n *Network) GetPairs(ctx context.Context, to int64, from int64) ([]*types.
 	)
 	switch n.Chain.ChainID {
 	case 1:
+		dex = types.DexUniswap
 		V2factoryABI = types.EthV2FactoryABI
 		V3factoryABI = types.EthV3FactoryABI
 		V2factoryAddr = types.EthV2FactoryAddress
 		V3factoryAddr = types.EthV3FactoryAddress
 
 	case 56:
+		dex = types.DexPancakeSwap
 		V2factoryABI = types.BscV2FactoryABI
 		V3factoryABI = types.BscV3FactoryABI
 		V2factoryAddr = types.BscV2FactoryAddress
 		V3factoryAddr = types.BscV3FactoryAddress
 	default:
-		return nil, errors.New("invalid chain (n.Chain.ChainID)")
+		if len(n.factories(ProtocolSolidly)) == 0 {
+			return nil, errors.New("invalid chain (n.Chain.ChainID)")
+		}
+	}
+
+	solidly, err := n.getSolidlyPairs(ctx, bRange)
+	if err != nil {
+		return pairs, err
+	}
+	pairs = append(pairs, solidly...)
+
+	if V2factoryAddr == "" && V3factoryAddr == "" {
+		return pairs, nil
 	}
 
 	v2factoryDecoder, err := abi.JSON(strings.NewReader(V2factoryABI))
@@ -72,8 +93,8 @@ func (n *Network) GetPairs(ctx context.Context, to int64, from int64) ([]*types.
 	V2eventSig = utils.TopicToHash("PairCreated(address,address,address,uint256)")
 	V3eventSig = utils.TopicToHash("PoolCreated(address,address,uint24,int24,address)")
 
-	v2s, v2err := n.getPairs(ctx, v2factoryDecoder, V2eventSig, V2factoryAddr, bRange, pairModeV2)
-	v3s, v3err := n.getPairs(ctx, v3factoryDecoder, V3eventSig, V3factoryAddr, bRange, pairModeV3)
+	v2s, v2err := n.getPairs(ctx, v2factoryDecoder, V2eventSig, V2factoryAddr, dex, bRange, pairModeV2)
+	v3s, v3err := n.getPairs(ctx, v3factoryDecoder, V3eventSig, V3factoryAddr, dex, bRange, pairModeV3)
 
 	if v2err != nil {
 		return pairs, v2err
@@ -89,7 +110,57 @@ func (n *Network) GetPairs(ctx context.Context, to int64, from int64) ([]*types.
 	return pairs, nil
 }
 
-func (n *Network) getPairs(ctx context.Context, decoder abi.ABI, signature common.Hash, factory string, bRange blockRange, mode pairMode) ([]*types.Pair, error) {
+// getSolidlyPairs indexes every solidly factory configured for the chain.
+func (n *Network) getSolidlyPairs(ctx context.Context, bRange blockRange) ([]*types.Pair, error) {
+	pairs := make([]*types.Pair, 0)
+
+	factories := n.factories(ProtocolSolidly)
+	if len(factories) == 0 {
+		return pairs, nil
+	}
+
+	decoder, err := abi.JSON(strings.NewReader(types.SolidlyFactoryABI))
+	if err != nil {
+		return nil, err
+	}
+
+	eventSig := utils.TopicToHash("PairCreated(address,address,bool,address,uint256)")
+
+	for _, f := range factories {
+		dex := strings.ToLower(f.Name)
+		if dex == "" {
+			dex = ProtocolSolidly
+		}
+
+		ps, err := n.getPairs(ctx, decoder, eventSig, f.Address, dex, bRange, pairModeSolidly)
+		if err != nil {
+			return pairs, err
+		}
+		pairs = append(pairs, ps...)
+	}
+
+	return pairs, nil
+}
+
+// factories returns the configured factories for the network's chain using the given protocol.
+func (n *Network) factories(protocol string) []config.FactoryConfig {
+	factories := make([]config.FactoryConfig, 0)
+	for _, c := range n.config.Chains {
+		if c.ChainID != n.Chain.ChainID {
+			continue
+		}
+
+		for _, f := range c.Factories {
+			if strings.EqualFold(f.Protocol, protocol) && f.Address != "" {
+				factories = append(factories, f)
+			}
+		}
+	}
+
+	return factories
+}
+
+func (n *Network) getPairs(ctx context.Context, decoder abi.ABI, signature common.Hash, factory string, dex string, bRange blockRange, mode pairMode) ([]*types.Pair, error) {
 	var (
 		pairs = make([]*types.Pair, 0)
 		err   error
@@ -104,7 +175,7 @@ func (n *Network) getPairs(ctx context.Context, decoder abi.ABI, signature commo
 		Topics:    topic,
 	}
 
-	logs, err := n.Client.FilterLogs(ctx, filter)
+	logs, err := n.filterLogs(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -126,6 +197,7 @@ func (n *Network) getPairs(ctx context.Context, decoder abi.ABI, signature commo
 				Fee:           0,
 				TickSpacing:   0,
 				PoolType:      2,
+				Dex:           dex,
 			}
 
 			decoded, err := decoder.Unpack("PairCreated", l.Data)
@@ -165,6 +237,7 @@ func (n *Network) getPairs(ctx context.Context, decoder abi.ABI, signature commo
 				Token1Address: common.HexToAddress((l.Topics[2].String())).String(),
 				Fee:           l.Topics[3].Big().Int64(),
 				PoolType:      3,
+				Dex:           dex,
 				PoolAddress:   "0x0000000000000000000000000000000000000000",
 				TickSpacing:   0,
 			}
@@ -198,6 +271,55 @@ func (n *Network) getPairs(ctx context.Context, decoder abi.ABI, signature commo
 			p.Lower()
 			pairs = append(pairs, p)
 		}
+
+	case pairModeSolidly:
+		for _, l := range logs {
+			if len(l.Topics) != 3 {
+				slog.Warn("error decoding solidly PairCreated event", "error", "len(l.Topics) != 3")
+				continue
+			}
+
+			p := &types.Pair{
+				ChainID:       int16(n.Chain.ChainID),
+				CreatedAt:     int64(l.BlockNumber),
+				Hash:          l.TxHash.String(),
+				Token0Address: common.HexToAddress((l.Topics[1].String())).String(),
+				Token1Address: common.HexToAddress((l.Topics[2].String())).String(),
+				Fee:           0,
+				TickSpacing:   0,
+				PoolType:      2,
+				Dex:           dex,
+			}
+
+			decoded, err := decoder.Unpack("PairCreated", l.Data)
+			if err != nil {
+				slog.Warn("error decoding solidly PairCreated event", "error", err)
+				continue
+			}
+
+			if len(decoded) != 3 {
+				slog.Warn("error decoding solidly PairCreated event", "error", "len(decoded) != 3")
+				continue
+			}
+
+			stable, ok := decoded[0].(bool)
+			if !ok {
+				slog.Warn("error decoding solidly PairCreated event", "error", "stable, ok := decoded[0].(bool)")
+				continue
+			}
+
+			pair, ok := decoded[1].(common.Address)
+			if !ok {
+				slog.Warn("error decoding solidly PairCreated event", "error", "pair, ok := decoded[1].(common.Address)")
+				continue
+			}
+
+			p.Stable = stable
+			p.PoolAddress = pair.String()
+			p.Lower()
+			pairs = append(pairs, p)
+		}
+
 	default:
 		return nil, errors.New("invalid pair mode")
 	}
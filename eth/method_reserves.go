@@ -0,0 +1,256 @@
+package eth
+
+import (
+	"context"
+	"log/slog"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/autoapev1/indexer/types"
+	"github.com/autoapev1/indexer/utils"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	etypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	topicSyncV2    = utils.TopicToHash("Sync(uint112,uint112)")
+	topicSyncSolid = utils.TopicToHash("Sync(uint256,uint256)")
+	topicSwapV2    = utils.TopicToHash("Swap(address,uint256,uint256,uint256,uint256,address)")
+	topicMintV3    = utils.TopicToHash("Mint(address,address,int24,int24,uint128,uint256,uint256)")
+	topicSwapV3    = utils.TopicToHash("Swap(address,address,int256,int256,uint160,uint128,int24)")
+
+	// pancakeswap v3 appends the protocol fees of the swap, the amounts are
+	// the same as uniswap v3
+	topicSwapPancakeV3 = utils.TopicToHash("Swap(address,address,int256,int256,uint160,uint128,int24,uint128,uint128)")
+
+	// v3 Burn only moves liquidity to the owed tokens of a position, the
+	// tokens leave the pool on Collect
+	topicCollectV3         = utils.TopicToHash("Collect(address,address,int24,int24,uint128,uint128)")
+	topicCollectProtocolV3 = utils.TopicToHash("CollectProtocol(address,address,uint128,uint128)")
+)
+
+// GetReserveEvents returns the Sync, Mint, Collect and Swap events emitted by any pool
+// between from and to (inclusive), ordered by block and log index.
+func (n *Network) GetReserveEvents(ctx context.Context, from int64, to int64) ([]*types.ReserveEvent, error) {
+	bRange := toRange(to, from)
+	if err := bRange.validate(); err != nil {
+		return nil, err
+	}
+
+	filter := ethereum.FilterQuery{
+		FromBlock: big.NewInt(bRange.from),
+		ToBlock:   big.NewInt(bRange.to),
+		Topics: [][]common.Hash{{
+			topicSyncV2,
+			topicSyncSolid,
+			topicSwapV2,
+			topicMintV3,
+			topicCollectV3,
+			topicCollectProtocolV3,
+			topicSwapV3,
+			topicSwapPancakeV3,
+		}},
+	}
+
+	logs, err := n.filterLogs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*types.ReserveEvent, 0, len(logs))
+	for _, l := range logs {
+		if l.Removed || len(l.Topics) == 0 {
+			continue
+		}
+
+		e, ok := decodeReserveEvent(l)
+		if !ok {
+			continue
+		}
+
+		events = append(events, e)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Block == events[j].Block {
+			return events[i].LogIndex < events[j].LogIndex
+		}
+		return events[i].Block < events[j].Block
+	})
+
+	return events, nil
+}
+
+func decodeReserveEvent(l etypes.Log) (*types.ReserveEvent, bool) {
+	e := &types.ReserveEvent{
+		PoolAddress: strings.ToLower(l.Address.String()),
+		Block:       int64(l.BlockNumber),
+		LogIndex:    l.Index,
+	}
+
+	switch l.Topics[0] {
+	case topicSyncV2, topicSyncSolid:
+		words, ok := dataWords(l.Data, 2)
+		if !ok {
+			slog.Warn("error decoding Sync event", "error", "len(l.Data) < 64", "pool", e.PoolAddress)
+			return nil, false
+		}
+		e.Kind = types.ReserveEventSync
+		e.Amount0 = words[0]
+		e.Amount1 = words[1]
+
+	case topicSwapV2:
+		// amount0In, amount1In, amount0Out, amount1Out
+		words, ok := dataWords(l.Data, 4)
+		if !ok {
+			slog.Warn("error decoding v2 Swap event", "error", "len(l.Data) < 128", "pool", e.PoolAddress)
+			return nil, false
+		}
+		e.Kind = types.ReserveEventSwap
+		e.Volume0 = new(big.Int).Add(words[0], words[2])
+		e.Volume1 = new(big.Int).Add(words[1], words[3])
+
+	case topicMintV3:
+		// sender, amount, amount0, amount1
+		words, ok := dataWords(l.Data, 4)
+		if !ok {
+			slog.Warn("error decoding v3 Mint event", "error", "len(l.Data) < 128", "pool", e.PoolAddress)
+			return nil, false
+		}
+		e.Kind = types.ReserveEventMint
+		e.Amount0 = words[2]
+		e.Amount1 = words[3]
+
+	case topicCollectV3:
+		// recipient, amount0, amount1
+		words, ok := dataWords(l.Data, 3)
+		if !ok {
+			slog.Warn("error decoding v3 Collect event", "error", "len(l.Data) < 96", "pool", e.PoolAddress)
+			return nil, false
+		}
+		e.Kind = types.ReserveEventCollect
+		e.Amount0 = new(big.Int).Neg(words[1])
+		e.Amount1 = new(big.Int).Neg(words[2])
+
+	case topicCollectProtocolV3:
+		// amount0, amount1
+		words, ok := dataWords(l.Data, 2)
+		if !ok {
+			slog.Warn("error decoding v3 CollectProtocol event", "error", "len(l.Data) < 64", "pool", e.PoolAddress)
+			return nil, false
+		}
+		e.Kind = types.ReserveEventCollect
+		e.Amount0 = new(big.Int).Neg(words[0])
+		e.Amount1 = new(big.Int).Neg(words[1])
+
+	case topicSwapV3, topicSwapPancakeV3:
+		// amount0, amount1, sqrtPriceX96, liquidity, tick, and on pancakeswap
+		// protocolFeesToken0, protocolFeesToken1
+		words, ok := dataWords(l.Data, 2)
+		if !ok {
+			slog.Warn("error decoding v3 Swap event", "error", "len(l.Data) < 64", "pool", e.PoolAddress)
+			return nil, false
+		}
+		e.Kind = types.ReserveEventSwap
+		e.Amount0 = math.S256(words[0])
+		e.Amount1 = math.S256(words[1])
+		e.Volume0 = new(big.Int).Abs(e.Amount0)
+		e.Volume1 = new(big.Int).Abs(e.Amount1)
+
+	default:
+		return nil, false
+	}
+
+	return e, true
+}
+
+// GetPoolReserves returns the reserves of every pair at the end of block, in
+// the same order. v2 style pools report them with getReserves, v3 pools hold
+// them as token balances. Pools whose calls revert are returned empty.
+func (n *Network) GetPoolReserves(ctx context.Context, pairs []*types.Pair, block int64) ([][2]*big.Int, error) {
+	batchSize := n.config.Sync.Tokens.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	tag := hexutil.EncodeBig(big.NewInt(block))
+	call := func(to string, data string) rpc.BatchElem {
+		return rpc.BatchElem{
+			Method: "eth_call",
+			Args:   []interface{}{map[string]string{"to": to, "data": data}, tag},
+			Result: new(hexutil.Bytes),
+		}
+	}
+
+	reserves := make([][2]*big.Int, len(pairs))
+	for i := 0; i < len(pairs); i += batchSize {
+		end := i + batchSize
+		if end > len(pairs) {
+			end = len(pairs)
+		}
+
+		// one getReserves call per v2 pool, two balanceOf calls per v3 pool
+		batch := make([]rpc.BatchElem, 0, 2*(end-i))
+		for _, p := range pairs[i:end] {
+			if p.PoolType == 3 {
+				pool := common.LeftPadBytes(common.HexToAddress(p.PoolAddress).Bytes(), 32)
+				balanceOf := toMethodChecksum("balanceOf(address)") + common.Bytes2Hex(pool)
+				batch = append(batch, call(p.Token0Address, balanceOf), call(p.Token1Address, balanceOf))
+				continue
+			}
+			batch = append(batch, call(p.PoolAddress, toMethodChecksum("getReserves()")))
+		}
+
+		if err := n.batchCall(ctx, batch); err != nil {
+			return nil, err
+		}
+
+		for _, b := range batch {
+			if b.Error != nil && !strings.Contains(b.Error.Error(), "revert") {
+				return nil, b.Error
+			}
+		}
+
+		next := 0
+		for j, p := range pairs[i:end] {
+			r := [2]*big.Int{new(big.Int), new(big.Int)}
+
+			if p.PoolType == 3 {
+				for k := 0; k < 2; k++ {
+					if words, ok := dataWords(*batch[next+k].Result.(*hexutil.Bytes), 1); ok && batch[next+k].Error == nil {
+						r[k] = words[0]
+					}
+				}
+				next += 2
+			} else {
+				if words, ok := dataWords(*batch[next].Result.(*hexutil.Bytes), 2); ok && batch[next].Error == nil {
+					r = [2]*big.Int{words[0], words[1]}
+				}
+				next++
+			}
+
+			reserves[i+j] = r
+		}
+	}
+
+	return reserves, nil
+}
+
+// dataWords splits the first count 32 byte words of event data into unsigned ints.
+func dataWords(data []byte, count int) ([]*big.Int, bool) {
+	if len(data) < count*32 {
+		return nil, false
+	}
+
+	words := make([]*big.Int, count)
+	for i := 0; i < count; i++ {
+		words[i] = new(big.Int).SetBytes(data[i*32 : (i+1)*32])
+	}
+
+	return words, true
+}
@@ -0,0 +1,76 @@
+package eth
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/autoapev1/indexer/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	etypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestDecodePancakeV3Swap(t *testing.T) {
+	poolABI, err := abi.JSON(strings.NewReader(types.BscV3PoolABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	swap, ok := poolABI.Events["Swap"]
+	if !ok {
+		t.Fatal("pancake v3 pool abi has no Swap event")
+	}
+
+	if swap.ID != topicSwapPancakeV3 {
+		t.Fatalf("topic %s does not match the pool abi %s", topicSwapPancakeV3, swap.ID)
+	}
+
+	// 1.5 WBNB in for 912.34 USDT out, usdt is token0 of the pool
+	amount0, _ := new(big.Int).SetString("-912340000000000000000", 10)
+	amount1, _ := new(big.Int).SetString("1500000000000000000", 10)
+	sqrtPrice, _ := new(big.Int).SetString("3242549238214563582974683251", 10)
+
+	data, err := swap.Inputs.NonIndexed().Pack(
+		amount0,
+		amount1,
+		sqrtPrice,
+		big.NewInt(7_000_000_000_000_000),
+		big.NewInt(-56020),
+		big.NewInt(12_000_000_000_000),
+		big.NewInt(0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := common.HexToAddress("0x172fcD41E0913e95784454622d1c3724f546f849")
+	router := common.HexToHash("0x00000000000000000000000013f4ea83d0bd40e75c8222255bc855a974568dd4")
+
+	e, ok := decodeReserveEvent(etypes.Log{
+		Address:     pool,
+		Topics:      []common.Hash{topicSwapPancakeV3, router, router},
+		Data:        data,
+		BlockNumber: 38_000_000,
+		Index:       12,
+	})
+	if !ok {
+		t.Fatal("pancake v3 swap was not decoded")
+	}
+
+	if e.Kind != types.ReserveEventSwap {
+		t.Fatalf("kind = %v, want swap", e.Kind)
+	}
+
+	if e.PoolAddress != strings.ToLower(pool.String()) || e.Block != 38_000_000 || e.LogIndex != 12 {
+		t.Fatalf("unexpected position %s %d %d", e.PoolAddress, e.Block, e.LogIndex)
+	}
+
+	if e.Amount0.Cmp(amount0) != 0 || e.Amount1.Cmp(amount1) != 0 {
+		t.Fatalf("amounts = %s %s, want %s %s", e.Amount0, e.Amount1, amount0, amount1)
+	}
+
+	if e.Volume0.Cmp(new(big.Int).Neg(amount0)) != 0 || e.Volume1.Cmp(amount1) != 0 {
+		t.Fatalf("volumes = %s %s", e.Volume0, e.Volume1)
+	}
+}
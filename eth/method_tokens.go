@@ -160,7 +160,7 @@ func (n *Network) makeStage1TokenInfoBatches(tokens []string) [][]rpc.BatchElem
 func (n *Network) getStage1TokenInfoBatch(ctx context.Context, batch []rpc.BatchElem) (*types.Token, error) {
 	token := &types.Token{}
 
-	if err := n.Client.Client().BatchCallContext(ctx, batch); err != nil {
+	if err := n.batchCall(ctx, batch); err != nil {
 		return nil, err
 	}
 
@@ -260,7 +260,7 @@ func (n *Network) makeStage2TokenInfoBatches(tokens []*types.Token, batchSize in
 
 func (n *Network) getStage2TokenInfoBatch(ctx context.Context, batch []rpc.BatchElem, tokens []*types.Token) error {
 
-	if err := n.Client.Client().BatchCallContext(ctx, batch); err != nil {
+	if err := n.batchCall(ctx, batch); err != nil {
 		return err
 	}
 
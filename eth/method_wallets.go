@@ -0,0 +1,235 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/autoapev1/indexer/types"
+	"github.com/autoapev1/indexer/utils"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var topicTransfer = utils.TopicToHash("Transfer(address,address,uint256)")
+
+// maxTopicFilter is the most wallets put in one topic position of eth_getLogs.
+const maxTopicFilter = 500
+
+type rpcTransaction struct {
+	Hash  common.Hash     `json:"hash"`
+	From  common.Address  `json:"from"`
+	To    *common.Address `json:"to"`
+	Value *hexutil.Big    `json:"value"`
+}
+
+type rpcFullBlock struct {
+	Number       hexutil.Uint64    `json:"number"`
+	Transactions []*rpcTransaction `json:"transactions"`
+}
+
+// BalanceQuery is the native balance of an address at the end of a block.
+type BalanceQuery struct {
+	Address string
+	Block   int64
+}
+
+// GetTransactions returns the transactions of the blocks between from and to (inclusive).
+func (n *Network) GetTransactions(ctx context.Context, from int64, to int64) ([]*types.Transaction, error) {
+	var (
+		txs      []*types.Transaction
+		lock     sync.Mutex
+		firstErr error
+	)
+
+	n.forEachBlockBatch(ctx, from, to, true, func(batch []rpc.BatchElem) {
+		result, err := n.getTransactionBatch(ctx, batch)
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		if err != nil {
+			slog.Error("getTransactionBatch", "err", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			return
+		}
+		txs = append(txs, result...)
+	})
+
+	// a missing block would drop balance changes
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	sort.SliceStable(txs, func(i, j int) bool {
+		return txs[i].Block < txs[j].Block
+	})
+
+	return txs, nil
+}
+
+func (n *Network) getTransactionBatch(ctx context.Context, batch []rpc.BatchElem) ([]*types.Transaction, error) {
+	if err := n.batchCall(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	var txs []*types.Transaction
+	for _, b := range batch {
+		if b.Error != nil {
+			return nil, b.Error
+		}
+
+		raw := *b.Result.(*json.RawMessage)
+		if len(raw) == 0 || string(raw) == "null" {
+			continue
+		}
+
+		block := new(rpcFullBlock)
+		if err := json.Unmarshal(raw, block); err != nil {
+			return nil, err
+		}
+
+		for _, t := range block.Transactions {
+			tx := &types.Transaction{
+				Block: int64(block.Number),
+				Hash:  strings.ToLower(t.Hash.String()),
+				From:  strings.ToLower(t.From.String()),
+				Value: new(big.Int),
+			}
+
+			if t.To != nil {
+				tx.To = strings.ToLower(t.To.String())
+			}
+
+			if t.Value != nil {
+				tx.Value = t.Value.ToInt()
+			}
+
+			txs = append(txs, tx)
+		}
+	}
+
+	return txs, nil
+}
+
+// GetBalances returns the native balance for every query, in the same order.
+func (n *Network) GetBalances(ctx context.Context, queries []BalanceQuery) ([]*big.Int, error) {
+	batchSize := n.config.Sync.Tokens.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	balances := make([]*big.Int, len(queries))
+	for i := 0; i < len(queries); i += batchSize {
+		end := i + batchSize
+		if end > len(queries) {
+			end = len(queries)
+		}
+
+		batch := make([]rpc.BatchElem, 0, end-i)
+		for _, q := range queries[i:end] {
+			batch = append(batch, rpc.BatchElem{
+				Method: "eth_getBalance",
+				Args:   []interface{}{common.HexToAddress(q.Address), hexutil.EncodeBig(big.NewInt(q.Block))},
+				Result: new(hexutil.Big),
+			})
+		}
+
+		if err := n.batchCall(ctx, batch); err != nil {
+			return nil, err
+		}
+
+		for j, b := range batch {
+			if b.Error != nil {
+				return nil, b.Error
+			}
+			balances[i+j] = b.Result.(*hexutil.Big).ToInt()
+		}
+	}
+
+	return balances, nil
+}
+
+// GetTransfers returns the erc20 Transfer events between from and to
+// (inclusive) sent or received by one of wallets, ordered by block and log index.
+func (n *Network) GetTransfers(ctx context.Context, from int64, to int64, wallets []string) ([]*types.TokenTransfer, error) {
+	bRange := toRange(to, from)
+	if err := bRange.validate(); err != nil {
+		return nil, err
+	}
+
+	type logKey struct {
+		block uint64
+		index uint
+	}
+
+	seen := make(map[logKey]struct{})
+	transfers := make([]*types.TokenTransfer, 0)
+
+	for i := 0; i < len(wallets); i += maxTopicFilter {
+		end := i + maxTopicFilter
+		if end > len(wallets) {
+			end = len(wallets)
+		}
+
+		topics := make([]common.Hash, 0, end-i)
+		for _, w := range wallets[i:end] {
+			topics = append(topics, common.BytesToHash(common.HexToAddress(w).Bytes()))
+		}
+
+		// sent, then received
+		for _, filterTopics := range [][][]common.Hash{
+			{{topicTransfer}, topics},
+			{{topicTransfer}, nil, topics},
+		} {
+			logs, err := n.filterLogs(ctx, ethereum.FilterQuery{
+				FromBlock: big.NewInt(bRange.from),
+				ToBlock:   big.NewInt(bRange.to),
+				Topics:    filterTopics,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			for _, l := range logs {
+				// erc721 transfers index the token id and have no data
+				if l.Removed || len(l.Topics) != 3 || len(l.Data) != 32 {
+					continue
+				}
+
+				k := logKey{block: l.BlockNumber, index: l.Index}
+				if _, ok := seen[k]; ok {
+					continue
+				}
+				seen[k] = struct{}{}
+
+				transfers = append(transfers, &types.TokenTransfer{
+					Block:    int64(l.BlockNumber),
+					LogIndex: int64(l.Index),
+					TxHash:   strings.ToLower(l.TxHash.String()),
+					Token:    strings.ToLower(l.Address.String()),
+					From:     strings.ToLower(common.BytesToAddress(l.Topics[1].Bytes()).String()),
+					To:       strings.ToLower(common.BytesToAddress(l.Topics[2].Bytes()).String()),
+					Amount:   new(big.Int).SetBytes(l.Data),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(transfers, func(i, j int) bool {
+		if transfers[i].Block == transfers[j].Block {
+			return transfers[i].LogIndex < transfers[j].LogIndex
+		}
+		return transfers[i].Block < transfers[j].Block
+	})
+
+	return transfers, nil
+}
@@ -0,0 +1,110 @@
+package eth
+
+import (
+	"context"
+	"time"
+
+	"github.com/autoapev1/indexer/metrics"
+	"github.com/autoapev1/indexer/tracing"
+	"github.com/ethereum/go-ethereum"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// batchCall sends a batch to the rpc endpoint of the chain, recording the
+// calls and failed elements of the batch. The round trip is recorded under
+// the method of the first call, and traced as a single span.
+func (n *Network) batchCall(ctx context.Context, batch []rpc.BatchElem) error {
+	method := ""
+	if len(batch) > 0 {
+		method = batch[0].Method
+	}
+
+	ctx, span := n.startSpan(ctx, method)
+	defer span.End()
+	span.SetAttributes(attribute.Int("rpc.batch_size", len(batch)))
+
+	chain := n.chainLabel()
+	start := time.Now()
+	err := n.Client.Client().BatchCallContext(ctx, batch)
+	if len(batch) > 0 {
+		metrics.RPCDuration.WithLabelValues(chain, method).Observe(time.Since(start).Seconds())
+	}
+
+	failed := 0
+	for _, b := range batch {
+		metrics.RPCRequests.WithLabelValues(chain, b.Method).Inc()
+		if err != nil || b.Error != nil {
+			metrics.RPCErrors.WithLabelValues(chain, b.Method).Inc()
+			failed++
+		}
+	}
+
+	span.SetAttributes(attribute.Int("rpc.batch_errors", failed))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+// filterLogs returns the logs matching q.
+func (n *Network) filterLogs(ctx context.Context, q ethereum.FilterQuery) ([]ethtypes.Log, error) {
+	ctx, span := n.startSpan(ctx, "eth_getLogs")
+	defer span.End()
+
+	start := time.Now()
+	logs, err := n.Client.FilterLogs(ctx, q)
+	n.observe("eth_getLogs", start, err)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return logs, err
+}
+
+// GetBlockNumber returns the latest block of the chain.
+func (n *Network) GetBlockNumber(ctx context.Context) (int64, error) {
+	start := time.Now()
+	head, err := n.Client.BlockNumber(ctx)
+	n.observe("eth_blockNumber", start, err)
+	if err != nil {
+		return 0, err
+	}
+
+	metrics.ChainHead.WithLabelValues(n.chainLabel()).Set(float64(head))
+
+	return int64(head), nil
+}
+
+// observe records a single call to the rpc endpoint started at start.
+func (n *Network) observe(method string, start time.Time, err error) {
+	chain := n.chainLabel()
+	metrics.RPCDuration.WithLabelValues(chain, method).Observe(time.Since(start).Seconds())
+
+	metrics.RPCRequests.WithLabelValues(chain, method).Inc()
+	if err != nil {
+		metrics.RPCErrors.WithLabelValues(chain, method).Inc()
+	}
+}
+
+// startSpan starts a client span for a call to the rpc endpoint of the chain.
+func (n *Network) startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "rpc "+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "jsonrpc"),
+			attribute.String("rpc.method", method),
+			attribute.Int64("chain_id", int64(n.Chain.ChainID)),
+		),
+	)
+}
+
+func (n *Network) chainLabel() string {
+	return metrics.Chain(int64(n.Chain.ChainID))
+}
@@ -0,0 +1,543 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/autoapev1/indexer/config"
+	"github.com/autoapev1/indexer/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	etypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// Columns every event table starts with.
+const (
+	ColumnBlockNumber = "block_number"
+	ColumnLogIndex    = "log_index"
+	ColumnTxHash      = "tx_hash"
+	ColumnContract    = "contract_address"
+)
+
+var (
+	identRegexp = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+	// tables owned by the indexer itself
+	reservedTables = modelTables()
+
+	baseColumns = map[string]struct{}{
+		ColumnBlockNumber: {},
+		ColumnLogIndex:    {},
+		ColumnTxHash:      {},
+		ColumnContract:    {},
+	}
+)
+
+type event struct {
+	abi     abi.Event
+	table   *types.EventTable
+	columns []string // per abi input
+}
+
+type factory struct {
+	address string
+	event   abi.Event
+	field   int // index of the address input
+}
+
+// Indexer decodes the events of one configured contract set into table rows.
+type Indexer struct {
+	lock      sync.RWMutex
+	table     string
+	start     int64
+	addresses map[string]struct{}
+	events    map[common.Hash]*event
+	factory   *factory
+}
+
+// Load reads the abis of an events config from disk and builds its indexer.
+func Load(conf config.EventsConfig) (*Indexer, error) {
+	r, err := os.Open(conf.ABI)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var fr io.Reader
+	if conf.Factory.ABI != "" {
+		f, err := os.Open(conf.Factory.ABI)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		fr = f
+	}
+
+	return Parse(conf, r, fr)
+}
+
+// Parse builds an indexer from an events config and its abi. factoryABI may
+// be nil when the factory event is in the indexer abi.
+func Parse(conf config.EventsConfig, r io.Reader, factoryABI io.Reader) (*Indexer, error) {
+	if !identRegexp.MatchString(conf.Table) {
+		return nil, fmt.Errorf("invalid events table %q, use lowercase letters, digits and underscores", conf.Table)
+	}
+
+	contract, err := abi.JSON(r)
+	if err != nil {
+		return nil, fmt.Errorf("events %s: %w", conf.Table, err)
+	}
+
+	ix := &Indexer{
+		table:     conf.Table,
+		start:     conf.StartBlock,
+		addresses: make(map[string]struct{}),
+		events:    make(map[common.Hash]*event),
+	}
+
+	for _, a := range conf.Addresses {
+		if !common.IsHexAddress(a) {
+			return nil, fmt.Errorf("events %s: invalid address %q", conf.Table, a)
+		}
+		ix.addresses[strings.ToLower(a)] = struct{}{}
+	}
+
+	names := conf.Events
+	if len(names) == 0 {
+		for name := range contract.Events {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("events %s: abi has no events", conf.Table)
+	}
+
+	tables := make(map[string]string, len(names))
+	for _, name := range names {
+		ev, ok := contract.Events[name]
+		if !ok {
+			return nil, fmt.Errorf("events %s: event %s not found in abi", conf.Table, name)
+		}
+
+		if ev.Anonymous {
+			return nil, fmt.Errorf("events %s: anonymous event %s can not be indexed", conf.Table, name)
+		}
+
+		e, err := newEvent(conf.Table, ev)
+		if err != nil {
+			return nil, err
+		}
+
+		// overloaded events share their raw name, and with it a table
+		if other, ok := tables[e.table.Name]; ok {
+			return nil, fmt.Errorf("events %s: events %s and %s both map to table %s", conf.Table, other, ev.Sig, e.table.Name)
+		}
+		tables[e.table.Name] = ev.Sig
+
+		ix.events[ev.ID] = e
+	}
+
+	if conf.Factory.Address != "" {
+		ix.factory, err = newFactory(conf, contract, factoryABI)
+		if err != nil {
+			return nil, err
+		}
+	} else if len(ix.addresses) == 0 {
+		return nil, fmt.Errorf("events %s: addresses or a factory are required", conf.Table)
+	}
+
+	return ix, nil
+}
+
+// modelTables returns the tables of the chain database models.
+func modelTables() map[string]struct{} {
+	tables := make(map[string]struct{}, len(types.Models))
+	for _, m := range types.Models {
+		tables[types.TableName(m)] = struct{}{}
+	}
+
+	return tables
+}
+
+func newEvent(prefix string, ev abi.Event) (*event, error) {
+	name := prefix + "_" + toSnake(ev.RawName)
+	if len(name) > 63 {
+		return nil, fmt.Errorf("events table %s is longer than 63 characters", name)
+	}
+
+	if _, ok := reservedTables[name]; ok {
+		return nil, fmt.Errorf("events table %s is reserved", name)
+	}
+
+	e := &event{
+		abi: ev,
+		table: &types.EventTable{
+			Name:    name,
+			Event:   ev.Sig,
+			Columns: make([]types.EventColumn, 0, len(ev.Inputs)),
+		},
+		columns: make([]string, 0, len(ev.Inputs)),
+	}
+
+	seen := make(map[string]struct{})
+	for i, in := range ev.Inputs {
+		column := toSnake(in.Name)
+		if column == "" {
+			column = fmt.Sprintf("arg%d", i)
+		}
+
+		if _, ok := baseColumns[column]; ok {
+			column = "arg_" + column
+		}
+
+		if _, ok := seen[column]; ok {
+			column = fmt.Sprintf("%s_%d", column, i)
+		}
+		seen[column] = struct{}{}
+
+		e.columns = append(e.columns, column)
+		e.table.Columns = append(e.table.Columns, types.EventColumn{
+			Name: column,
+			Type: columnType(in),
+		})
+	}
+
+	return e, nil
+}
+
+func newFactory(conf config.EventsConfig, contract abi.ABI, r io.Reader) (*factory, error) {
+	if !common.IsHexAddress(conf.Factory.Address) {
+		return nil, fmt.Errorf("events %s: invalid factory address %q", conf.Table, conf.Factory.Address)
+	}
+
+	if r != nil {
+		var err error
+		contract, err = abi.JSON(r)
+		if err != nil {
+			return nil, fmt.Errorf("events %s factory: %w", conf.Table, err)
+		}
+	}
+
+	ev, ok := contract.Events[conf.Factory.Event]
+	if !ok {
+		return nil, fmt.Errorf("events %s: factory event %s not found in abi", conf.Table, conf.Factory.Event)
+	}
+
+	for i, in := range ev.Inputs {
+		if in.Name != conf.Factory.Field {
+			continue
+		}
+
+		if in.Type.T != abi.AddressTy {
+			return nil, fmt.Errorf("events %s: factory field %s is not an address", conf.Table, in.Name)
+		}
+
+		return &factory{
+			address: strings.ToLower(conf.Factory.Address),
+			event:   ev,
+			field:   i,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("events %s: factory field %s not found in %s", conf.Table, conf.Factory.Field, ev.Sig)
+}
+
+// Name identifies the indexer, it is the configured table prefix.
+func (ix *Indexer) Name() string {
+	return ix.table
+}
+
+// StartBlock is the first block the indexer syncs.
+func (ix *Indexer) StartBlock() int64 {
+	return ix.start
+}
+
+// Tables returns the tables of the indexed events, ordered by name.
+func (ix *Indexer) Tables() []*types.EventTable {
+	tables := make([]*types.EventTable, 0, len(ix.events))
+	for _, e := range ix.events {
+		tables = append(tables, e.table)
+	}
+
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].Name < tables[j].Name
+	})
+
+	return tables
+}
+
+// Topics returns the topic0 of every indexed event.
+func (ix *Indexer) Topics() []string {
+	topics := make([]string, 0, len(ix.events))
+	for id := range ix.events {
+		topics = append(topics, id.String())
+	}
+	sort.Strings(topics)
+
+	return topics
+}
+
+// Addresses returns the configured and discovered contracts.
+func (ix *Indexer) Addresses() []string {
+	ix.lock.RLock()
+	defer ix.lock.RUnlock()
+
+	addresses := make([]string, 0, len(ix.addresses))
+	for a := range ix.addresses {
+		addresses = append(addresses, a)
+	}
+	sort.Strings(addresses)
+
+	return addresses
+}
+
+// AddAddresses adds discovered contracts to the indexer.
+func (ix *Indexer) AddAddresses(addresses []string) {
+	ix.lock.Lock()
+	defer ix.lock.Unlock()
+
+	for _, a := range addresses {
+		ix.addresses[strings.ToLower(a)] = struct{}{}
+	}
+}
+
+// Watches reports whether logs of address are indexed.
+func (ix *Indexer) Watches(address string) bool {
+	ix.lock.RLock()
+	defer ix.lock.RUnlock()
+
+	_, ok := ix.addresses[strings.ToLower(address)]
+	return ok
+}
+
+// Factory returns the factory address and event topic, if the indexer has one.
+func (ix *Indexer) Factory() (string, string, bool) {
+	if ix.factory == nil {
+		return "", "", false
+	}
+
+	return ix.factory.address, ix.factory.event.ID.String(), true
+}
+
+// DecodeFactory returns the contract announced by a factory log.
+func (ix *Indexer) DecodeFactory(l etypes.Log) (*types.EventContract, bool) {
+	if ix.factory == nil || len(l.Topics) == 0 || l.Topics[0] != ix.factory.event.ID {
+		return nil, false
+	}
+
+	if !strings.EqualFold(l.Address.String(), ix.factory.address) {
+		return nil, false
+	}
+
+	values, err := decodeInputs(ix.factory.event, l)
+	if err != nil {
+		return nil, false
+	}
+
+	address, ok := values[ix.factory.field].(common.Address)
+	if !ok {
+		return nil, false
+	}
+
+	c := &types.EventContract{
+		Indexer:   ix.table,
+		Address:   address.String(),
+		CreatedAt: int64(l.BlockNumber),
+	}
+	c.Lower()
+
+	return c, true
+}
+
+// Decode returns the table and row of an indexed event log.
+func (ix *Indexer) Decode(l etypes.Log) (string, types.EventRow, error) {
+	if len(l.Topics) == 0 {
+		return "", nil, errors.New("log has no topics")
+	}
+
+	e, ok := ix.events[l.Topics[0]]
+	if !ok {
+		return "", nil, errors.New("log is not an indexed event")
+	}
+
+	values, err := decodeInputs(e.abi, l)
+	if err != nil {
+		return "", nil, err
+	}
+
+	row := types.EventRow{
+		ColumnBlockNumber: int64(l.BlockNumber),
+		ColumnLogIndex:    int64(l.Index),
+		ColumnTxHash:      strings.ToLower(l.TxHash.String()),
+		ColumnContract:    strings.ToLower(l.Address.String()),
+	}
+
+	for i, column := range e.columns {
+		v, err := toColumnValue(e.table.Columns[i].Type, values[i])
+		if err != nil {
+			return "", nil, fmt.Errorf("%s.%s: %w", e.table.Name, column, err)
+		}
+		row[column] = v
+	}
+
+	return e.table.Name, row, nil
+}
+
+// decodeInputs returns the values of every event input in abi order. Indexed
+// inputs of dynamic types are only available as their keccak256 hash.
+func decodeInputs(ev abi.Event, l etypes.Log) ([]interface{}, error) {
+	values := make([]interface{}, len(ev.Inputs))
+
+	data, err := ev.Inputs.NonIndexed().Unpack(l.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	topic, d := 1, 0
+	for i, in := range ev.Inputs {
+		if !in.Indexed {
+			values[i] = data[d]
+			d++
+			continue
+		}
+
+		if topic >= len(l.Topics) {
+			return nil, errors.New("log is missing indexed topics")
+		}
+
+		if isHashedTopic(in.Type) {
+			values[i] = l.Topics[topic]
+			topic++
+			continue
+		}
+
+		out := make(map[string]interface{})
+		arg := in
+		arg.Name = "v"
+		if err := abi.ParseTopicsIntoMap(out, abi.Arguments{arg}, l.Topics[topic:topic+1]); err != nil {
+			return nil, err
+		}
+		values[i] = out["v"]
+		topic++
+	}
+
+	return values, nil
+}
+
+func isHashedTopic(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	}
+	return false
+}
+
+// columnType maps an abi input to a postgres column type.
+func columnType(in abi.Argument) string {
+	if in.Indexed && isHashedTopic(in.Type) {
+		return "VARCHAR(66)"
+	}
+
+	switch in.Type.T {
+	case abi.AddressTy:
+		return "VARCHAR(42)"
+	case abi.BoolTy:
+		return "BOOLEAN"
+	case abi.IntTy:
+		if in.Type.Size <= 64 {
+			return "BIGINT"
+		}
+		return "NUMERIC"
+	case abi.UintTy:
+		if in.Type.Size <= 32 {
+			return "BIGINT"
+		}
+		return "NUMERIC"
+	case abi.FixedBytesTy, abi.HashTy:
+		return fmt.Sprintf("VARCHAR(%d)", 2+2*in.Type.Size)
+	case abi.StringTy, abi.BytesTy, abi.FunctionTy:
+		return "TEXT"
+	default:
+		// arrays and tuples
+		return "JSONB"
+	}
+}
+
+// toColumnValue converts a decoded abi value to a value of its column type.
+func toColumnValue(columnType string, v interface{}) (interface{}, error) {
+	switch value := v.(type) {
+	case common.Address:
+		return strings.ToLower(value.String()), nil
+	case common.Hash:
+		return strings.ToLower(value.String()), nil
+	case *big.Int:
+		return value.String(), nil
+	case bool:
+		return value, nil
+	case string:
+		// postgres text can not hold NUL
+		return strings.ReplaceAll(value, "\x00", ""), nil
+	case []byte:
+		return hexutil.Encode(value), nil
+	}
+
+	if columnType == "JSONB" {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return int64(rv.Uint()), nil
+	case reflect.Uint64:
+		return new(big.Int).SetUint64(rv.Uint()).String(), nil
+	case reflect.Array:
+		// bytesN and function
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return hexutil.Encode(b), nil
+	}
+
+	return nil, fmt.Errorf("unsupported value %T", v)
+}
+
+// toSnake converts an abi name such as "tokenId" or "_amount" to "token_id" and "amount".
+func toSnake(name string) string {
+	var b strings.Builder
+	name = strings.TrimLeft(name, "_")
+
+	for i, r := range name {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			if i > 0 {
+				prev := name[i-1]
+				if (prev >= 'a' && prev <= 'z') || (prev >= '0' && prev <= '9') {
+					b.WriteByte('_')
+				}
+			}
+			b.WriteRune(r + ('a' - 'A'))
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_':
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
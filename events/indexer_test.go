@@ -0,0 +1,171 @@
+package events
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/autoapev1/indexer/config"
+	"github.com/autoapev1/indexer/types"
+	"github.com/ethereum/go-ethereum/common"
+	etypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+const stakingABI = `[
+	{"type":"event","name":"Staked","anonymous":false,"inputs":[
+		{"name":"user","type":"address","indexed":true},
+		{"name":"amount","type":"uint256","indexed":false},
+		{"name":"lockDays","type":"uint16","indexed":false}
+	]},
+	{"type":"event","name":"PoolCreated","anonymous":false,"inputs":[
+		{"name":"pool","type":"address","indexed":false}
+	]}
+]`
+
+func TestParseTables(t *testing.T) {
+	ix, err := Parse(config.EventsConfig{
+		Table:     "staking",
+		Events:    []string{"Staked"},
+		Addresses: []string{"0x000000000000000000000000000000000000000A"},
+	}, strings.NewReader(stakingABI), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tables := ix.Tables()
+	if len(tables) != 1 || tables[0].Name != "staking_staked" {
+		t.Fatalf("unexpected tables %+v", tables)
+	}
+
+	want := []string{"user VARCHAR(42)", "amount NUMERIC", "lock_days BIGINT"}
+	for i, c := range tables[0].Columns {
+		if got := c.Name + " " + c.Type; got != want[i] {
+			t.Errorf("column %d: expected %s, got %s", i, want[i], got)
+		}
+	}
+
+	if !ix.Watches("0x000000000000000000000000000000000000000a") {
+		t.Error("expected configured address to be watched")
+	}
+}
+
+func TestParseReservedTables(t *testing.T) {
+	for _, m := range types.Models {
+		table := types.TableName(m)
+		prefix, rest, ok := strings.Cut(table, "_")
+		if !ok {
+			continue
+		}
+
+		// wallet_balances is the table of event Balances under prefix wallet
+		event := ""
+		for _, part := range strings.Split(rest, "_") {
+			event += strings.ToUpper(part[:1]) + part[1:]
+		}
+
+		contract := `[{"type":"event","name":"` + event + `","anonymous":false,"inputs":[]}]`
+		_, err := Parse(config.EventsConfig{
+			Table:     prefix,
+			Addresses: []string{"0x000000000000000000000000000000000000000A"},
+		}, strings.NewReader(contract), nil)
+		if err == nil || !strings.Contains(err.Error(), "reserved") {
+			t.Errorf("%s: expected a reserved table error, got %v", table, err)
+		}
+	}
+}
+
+func TestParseOverloadedEvents(t *testing.T) {
+	const contract = `[
+		{"type":"event","name":"Transfer","anonymous":false,"inputs":[
+			{"name":"to","type":"address","indexed":true}
+		]},
+		{"type":"event","name":"Transfer","anonymous":false,"inputs":[
+			{"name":"to","type":"address","indexed":true},
+			{"name":"data","type":"bytes","indexed":false}
+		]}
+	]`
+
+	_, err := Parse(config.EventsConfig{
+		Table:     "token",
+		Addresses: []string{"0x000000000000000000000000000000000000000A"},
+	}, strings.NewReader(contract), nil)
+	if err == nil || !strings.Contains(err.Error(), "token_transfer") {
+		t.Fatalf("expected overloaded events to be rejected, got %v", err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	ix, err := Parse(config.EventsConfig{
+		Table:  "staking",
+		Events: []string{"Staked"},
+		Factory: config.EventsFactoryConfig{
+			Address: "0x00000000000000000000000000000000000000fa",
+			Event:   "PoolCreated",
+			Field:   "pool",
+		},
+	}, strings.NewReader(stakingABI), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, factoryTopic, ok := ix.Factory()
+	if !ok {
+		t.Fatal("expected a factory")
+	}
+
+	pool := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	created := etypes.Log{
+		Address:     common.HexToAddress("0x00000000000000000000000000000000000000fa"),
+		Topics:      []common.Hash{common.HexToHash(factoryTopic)},
+		Data:        common.LeftPadBytes(pool.Bytes(), 32),
+		BlockNumber: 9,
+	}
+
+	c, ok := ix.DecodeFactory(created)
+	if !ok || c.Address != strings.ToLower(pool.String()) || c.CreatedAt != 9 {
+		t.Fatalf("unexpected factory contract %+v", c)
+	}
+
+	user := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	data := append(common.LeftPadBytes(big.NewInt(1000).Bytes(), 32), common.LeftPadBytes(big.NewInt(30).Bytes(), 32)...)
+	staked := etypes.Log{
+		Address:     pool,
+		Topics:      []common.Hash{common.HexToHash(ix.Topics()[0]), common.BytesToHash(user.Bytes())},
+		Data:        data,
+		BlockNumber: 10,
+		Index:       3,
+	}
+
+	table, row, err := ix.Decode(staked)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if table != "staking_staked" {
+		t.Errorf("unexpected table %s", table)
+	}
+
+	if row["user"] != strings.ToLower(user.String()) || row["amount"] != "1000" || row["lock_days"] != int64(30) {
+		t.Errorf("unexpected row %+v", row)
+	}
+
+	if row[ColumnBlockNumber] != int64(10) || row[ColumnLogIndex] != int64(3) {
+		t.Errorf("unexpected log position %+v", row)
+	}
+}
+
+func TestToSnake(t *testing.T) {
+	cases := map[string]string{
+		"tokenId":  "token_id",
+		"_amount":  "amount",
+		"lockDays": "lock_days",
+		"ID":       "id",
+		"value0":   "value0",
+	}
+
+	for in, want := range cases {
+		if got := toSnake(in); got != want {
+			t.Errorf("toSnake(%s): expected %s, got %s", in, want, got)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// Package feed streams the pairs, tokens and blocks written by the syncer to
+// subscribers of the api server. The syncer runs in another process, so new
+// rows are found by polling each chain store for rows past the last seen
+// ingestion sequence or block.
+package feed
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/autoapev1/indexer/types"
+)
+
+type Kind string
+
+const (
+	KindPair   Kind = "pair"
+	KindToken  Kind = "token"
+	KindBlock  Kind = "block"
+	KindStatus Kind = "status" // sync heights changed
+)
+
+// pollLimit is the most rows of each kind read per poll.
+const pollLimit = 1000
+
+// Event is a newly ingested row, or the new sync heights of a chain. Seq is
+// the ingestion sequence of pairs and tokens and the number of blocks.
+type Event struct {
+	ChainID int64
+	Kind    Kind
+	Seq     int64
+	Pair    *types.Pair
+	Token   *types.Token
+	Block   *types.Block
+	Heights *types.Heights
+}
+
+// Source is the part of a chain store read by the poller.
+type Source interface {
+	GetChainID() int64
+	GetPairsAfterSeq(seq int64, limit int) ([]*types.Pair, error)
+	GetTokensAfterSeq(seq int64, limit int) ([]*types.Token, error)
+	GetLatestSeqs() (pairs int64, tokens int64, err error)
+	GetBlocks(to int64, from int64) ([]*types.Block, error)
+	GetHeights() (*types.Heights, error)
+}
+
+// Hub fans out batches of events to its subscriptions.
+type Hub struct {
+	lock sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subs: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscription receives every published batch on C until closed.
+type Subscription struct {
+	C    chan []*Event
+	hub  *Hub
+	once sync.Once
+
+	// set when a batch is dropped because C is full
+	missed atomic.Bool
+}
+
+// Subscribe returns a subscription buffering up to buffer batches.
+func (h *Hub) Subscribe(buffer int) *Subscription {
+	sub := &Subscription{
+		C:   make(chan []*Event, buffer),
+		hub: h,
+	}
+
+	h.lock.Lock()
+	h.subs[sub] = struct{}{}
+	h.lock.Unlock()
+
+	return sub
+}
+
+// Close removes the subscription from the hub and closes C.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.hub.lock.Lock()
+		delete(s.hub.subs, s)
+		s.hub.lock.Unlock()
+
+		close(s.C)
+	})
+}
+
+// Missed reports whether a batch was dropped since the last call.
+func (s *Subscription) Missed() bool {
+	return s.missed.Swap(false)
+}
+
+// Publish sends events to every subscription without blocking, a
+// subscription with a full buffer misses the batch and is marked as Missed.
+func (h *Hub) Publish(events []*Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	for sub := range h.subs {
+		select {
+		case sub.C <- events:
+		default:
+			sub.missed.Store(true)
+			slog.Warn("feed subscriber is full, dropping events", "events", len(events))
+		}
+	}
+}
+
+// Poll publishes the rows of src ingested after it starts, checking every
+// interval until ctx is done.
+func Poll(ctx context.Context, hub *Hub, src Source, interval time.Duration) {
+	p := &poller{src: src, chainID: src.GetChainID()}
+	for {
+		err := p.init()
+		if err == nil {
+			break
+		}
+
+		slog.Error("failed to get feed position", "chain_id", p.chainID, "err", err)
+		if !sleep(ctx, interval) {
+			return
+		}
+	}
+
+	for sleep(ctx, interval) {
+		events, err := p.poll()
+		if err != nil {
+			slog.Error("failed to poll feed", "chain_id", p.chainID, "err", err)
+		}
+
+		hub.Publish(events)
+	}
+}
+
+// poller keeps the position of the feed in a chain store.
+type poller struct {
+	src      Source
+	chainID  int64
+	pairSeq  int64
+	tokenSeq int64
+	block    int64
+	heights  types.Heights
+}
+
+func (p *poller) init() error {
+	pairSeq, tokenSeq, err := p.src.GetLatestSeqs()
+	if err != nil {
+		return err
+	}
+
+	heights, err := p.src.GetHeights()
+	if err != nil {
+		return err
+	}
+
+	p.pairSeq, p.tokenSeq = pairSeq, tokenSeq
+	p.block = heights.Headers
+	p.heights = *heights
+
+	return nil
+}
+
+// poll reads the rows after the current position and advances it.
+func (p *poller) poll() ([]*Event, error) {
+	events := make([]*Event, 0)
+
+	pairs, err := p.src.GetPairsAfterSeq(p.pairSeq, pollLimit)
+	if err != nil {
+		return events, err
+	}
+
+	for _, pair := range pairs {
+		events = append(events, &Event{ChainID: p.chainID, Kind: KindPair, Seq: pair.Seq, Pair: pair})
+		p.pairSeq = pair.Seq
+	}
+
+	tokens, err := p.src.GetTokensAfterSeq(p.tokenSeq, pollLimit)
+	if err != nil {
+		return events, err
+	}
+
+	for _, t := range tokens {
+		events = append(events, &Event{ChainID: p.chainID, Kind: KindToken, Seq: t.Seq, Token: t})
+		p.tokenSeq = t.Seq
+	}
+
+	blocks, err := p.src.GetBlocks(p.block+pollLimit, p.block+1)
+	if err != nil {
+		return events, err
+	}
+
+	for _, b := range blocks {
+		events = append(events, &Event{ChainID: p.chainID, Kind: KindBlock, Seq: b.Number, Block: b})
+		p.block = b.Number
+	}
+
+	heights, err := p.src.GetHeights()
+	if err != nil {
+		return events, err
+	}
+
+	if *heights != p.heights {
+		p.heights = *heights
+		events = append(events, &Event{ChainID: p.chainID, Kind: KindStatus, Heights: heights})
+	}
+
+	return events, nil
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,135 @@
+package feed
+
+import (
+	"testing"
+
+	"github.com/autoapev1/indexer/types"
+)
+
+type memSource struct {
+	pairs   []*types.Pair
+	tokens  []*types.Token
+	blocks  []*types.Block
+	heights types.Heights
+}
+
+func (m *memSource) GetChainID() int64 { return 1 }
+
+func (m *memSource) GetPairsAfterSeq(seq int64, limit int) ([]*types.Pair, error) {
+	var pairs []*types.Pair
+	for _, p := range m.pairs {
+		if p.Seq > seq && len(pairs) < limit {
+			pairs = append(pairs, p)
+		}
+	}
+	return pairs, nil
+}
+
+func (m *memSource) GetTokensAfterSeq(seq int64, limit int) ([]*types.Token, error) {
+	var tokens []*types.Token
+	for _, t := range m.tokens {
+		if t.Seq > seq && len(tokens) < limit {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens, nil
+}
+
+func (m *memSource) GetLatestSeqs() (int64, int64, error) {
+	var pairs, tokens int64
+	if len(m.pairs) > 0 {
+		pairs = m.pairs[len(m.pairs)-1].Seq
+	}
+	if len(m.tokens) > 0 {
+		tokens = m.tokens[len(m.tokens)-1].Seq
+	}
+	return pairs, tokens, nil
+}
+
+func (m *memSource) GetBlocks(to int64, from int64) ([]*types.Block, error) {
+	var blocks []*types.Block
+	for _, b := range m.blocks {
+		if b.Number >= from && b.Number <= to {
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks, nil
+}
+
+func (m *memSource) GetHeights() (*types.Heights, error) {
+	h := m.heights
+	return &h, nil
+}
+
+func TestPoll(t *testing.T) {
+	src := &memSource{
+		pairs:   []*types.Pair{{Seq: 1}},
+		blocks:  []*types.Block{{Number: 10}},
+		heights: types.Heights{Headers: 10},
+	}
+
+	p := &poller{src: src, chainID: 1}
+	if err := p.init(); err != nil {
+		t.Fatal(err)
+	}
+
+	// rows written before the feed started are not published
+	events, err := p.poll()
+	if err != nil || len(events) != 0 {
+		t.Fatalf("unexpected events %+v, err %v", events, err)
+	}
+
+	src.pairs = append(src.pairs, &types.Pair{Seq: 2}, &types.Pair{Seq: 3})
+	src.tokens = append(src.tokens, &types.Token{Seq: 1})
+	src.blocks = append(src.blocks, &types.Block{Number: 11})
+	src.heights = types.Heights{Headers: 11}
+
+	events, err = p.poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := []Kind{KindPair, KindPair, KindToken, KindBlock, KindStatus}
+	if len(events) != len(kinds) {
+		t.Fatalf("expected %d events, got %d", len(kinds), len(events))
+	}
+
+	for i, k := range kinds {
+		if events[i].Kind != k {
+			t.Errorf("event %d: expected %s, got %s", i, k, events[i].Kind)
+		}
+	}
+
+	if p.pairSeq != 3 || p.tokenSeq != 1 || p.block != 11 {
+		t.Errorf("unexpected position %d %d %d", p.pairSeq, p.tokenSeq, p.block)
+	}
+
+	events, _ = p.poll()
+	if len(events) != 0 {
+		t.Fatalf("unexpected events %+v", events)
+	}
+}
+
+func TestHub(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe(1)
+
+	hub.Publish([]*Event{{Kind: KindPair}})
+	// full, dropped
+	hub.Publish([]*Event{{Kind: KindToken}})
+
+	if events := <-sub.C; len(events) != 1 || events[0].Kind != KindPair {
+		t.Fatalf("unexpected events %+v", events)
+	}
+
+	if !sub.Missed() || sub.Missed() {
+		t.Fatal("expected the dropped batch to be reported once")
+	}
+
+	sub.Close()
+	hub.Publish([]*Event{{Kind: KindPair}})
+
+	if _, ok := <-sub.C; ok {
+		t.Fatal("expected a closed subscription")
+	}
+}
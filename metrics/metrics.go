@@ -0,0 +1,124 @@
+// Package metrics holds the prometheus metrics of the api and the syncer,
+// they are registered with the default registry and served by Handler.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "indexer"
+
+var (
+	// JRPCRequests counts json-rpc calls by method and status, the error code
+	// of the call or "ok". Each element of a batch is a call.
+	JRPCRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "jrpc",
+		Name:      "requests_total",
+		Help:      "JSON-RPC calls by method and status.",
+	}, []string{"method", "status"})
+
+	JRPCDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "jrpc",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of JSON-RPC calls by method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "status"})
+
+	// RateLimited counts calls refused by the rate limit ("rate") or by the
+	// quotas of a tier ("daily", "monthly", "tier").
+	RateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "api",
+		Name:      "rate_limited_total",
+		Help:      "Calls refused by a rate limit or quota.",
+	}, []string{"limit"})
+
+	// AuthFailures counts requests refused by the auth provider, by reason
+	// ("unauthorized", "expired" or "error").
+	AuthFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "api",
+		Name:      "auth_failures_total",
+		Help:      "Requests refused by the auth provider.",
+	}, []string{"reason"})
+
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Latency of store methods by chain.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"chain_id", "method"})
+
+	// RPCRequests counts the calls sent to the rpc endpoint of a chain, each
+	// element of a batch is a call.
+	RPCRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "requests_total",
+		Help:      "Calls sent to the RPC endpoint of a chain by method.",
+	}, []string{"chain_id", "method"})
+
+	RPCErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "errors_total",
+		Help:      "Failed calls to the RPC endpoint of a chain by method.",
+	}, []string{"chain_id", "method"})
+
+	// RPCDuration observes round trips, a batch under the method of its first
+	// call.
+	RPCDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of round trips to the RPC endpoint of a chain by method.",
+		Buckets:   []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"chain_id", "method"})
+
+	SyncHeight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "height",
+		Help:      "Last block synced by stage.",
+	}, []string{"chain_id", "stage"})
+
+	ChainHead = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "chain_head",
+		Help:      "Latest block of the chain.",
+	}, []string{"chain_id"})
+
+	// ChainHeadLag is the number of blocks the synced blocks are behind the
+	// chain head.
+	ChainHeadLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "chain_head_lag",
+		Help:      "Blocks the synced blocks are behind the chain head.",
+	}, []string{"chain_id"})
+)
+
+// Handler serves the metrics of the default registry.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveQuery records the latency of a store method started at start.
+func ObserveQuery(chainID int64, method string, start time.Time) {
+	DBQueryDuration.WithLabelValues(Chain(chainID), method).Observe(time.Since(start).Seconds())
+}
+
+// Chain is the chain_id label of a chain.
+func Chain(chainID int64) string {
+	return strconv.FormatInt(chainID, 10)
+}
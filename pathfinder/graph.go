@@ -0,0 +1,359 @@
+package pathfinder
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/autoapev1/indexer/pricing"
+	"github.com/autoapev1/indexer/types"
+)
+
+const (
+	MaxHops   = 4
+	MaxRoutes = 50
+
+	// maxBranch is the number of pools, deepest first, followed out of an
+	// intermediate token. Hub tokens have hundreds of thousands of pools.
+	maxBranch = 24
+	// maxVisits bounds the number of partial paths explored per search.
+	maxVisits = 200000
+)
+
+type pool struct {
+	address   string
+	dex       string
+	poolType  uint8
+	fee       int64
+	stable    bool
+	token0    int32
+	token1    int32
+	reserve0  *big.Int
+	reserve1  *big.Int
+	liquidity float64
+}
+
+func (p *pool) other(token int32) int32 {
+	if p.token0 == token {
+		return p.token1
+	}
+	return p.token0
+}
+
+// feePPM returns the swap fee in parts per million used when quoting.
+func (p *pool) feePPM() int64 {
+	switch {
+	case p.poolType == 3 && p.fee > 0:
+		return p.fee
+	case p.stable:
+		return 500
+	case p.dex == types.DexPancakeSwap:
+		return 2500
+	default:
+		return 3000
+	}
+}
+
+// quote returns the output amount of a constant product swap. v3 and stable
+// pools are approximated from their reserves.
+func (p *pool) quote(tokenIn int32, amountIn *big.Int) *big.Int {
+	reserveIn, reserveOut := p.reserve0, p.reserve1
+	if tokenIn == p.token1 {
+		reserveIn, reserveOut = p.reserve1, p.reserve0
+	}
+
+	if reserveIn.Sign() <= 0 || reserveOut.Sign() <= 0 || amountIn.Sign() <= 0 {
+		return new(big.Int)
+	}
+
+	amountInWithFee := new(big.Int).Mul(amountIn, big.NewInt(1000000-p.feePPM()))
+	numerator := new(big.Int).Mul(amountInWithFee, reserveOut)
+	denominator := new(big.Int).Mul(reserveIn, big.NewInt(1000000))
+	denominator.Add(denominator, amountInWithFee)
+
+	return numerator.Div(numerator, denominator)
+}
+
+type pairKey struct {
+	a int32
+	b int32
+}
+
+func toPairKey(a int32, b int32) pairKey {
+	if a > b {
+		a, b = b, a
+	}
+	return pairKey{a: a, b: b}
+}
+
+// Graph is an in memory token graph of the pairs of one chain.
+type Graph struct {
+	lock    sync.RWMutex
+	chainID int64
+	height  int64
+	tokens  map[string]int32
+	addrs   []string
+	pools   map[string]*pool
+	adj     [][]*pool
+	pairs   map[pairKey][]*pool
+	dirty   map[int32]struct{}
+}
+
+func NewGraph(chainID int64) *Graph {
+	return &Graph{
+		chainID: chainID,
+		tokens:  make(map[string]int32),
+		pools:   make(map[string]*pool),
+		pairs:   make(map[pairKey][]*pool),
+		dirty:   make(map[int32]struct{}),
+	}
+}
+
+func (g *Graph) ChainID() int64 {
+	return g.chainID
+}
+
+// Height returns the highest created_at or reserves_block of the pairs in the graph.
+func (g *Graph) Height() int64 {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	return g.height
+}
+
+// Size returns the number of tokens and pools in the graph.
+func (g *Graph) Size() (int, int) {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	return len(g.addrs), len(g.pools)
+}
+
+// AddPairs adds new pairs to the graph and updates the reserves of known ones.
+func (g *Graph) AddPairs(pairs []*types.Pair) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	for _, p := range pairs {
+		if p.CreatedAt > g.height {
+			g.height = p.CreatedAt
+		}
+		if p.ReservesBlock > g.height {
+			g.height = p.ReservesBlock
+		}
+
+		address := strings.ToLower(p.PoolAddress)
+		existing, ok := g.pools[address]
+		if ok {
+			existing.reserve0 = pricing.ParseAmount(p.Reserve0)
+			existing.reserve1 = pricing.ParseAmount(p.Reserve1)
+			existing.liquidity = p.Liquidity
+			g.dirty[existing.token0] = struct{}{}
+			g.dirty[existing.token1] = struct{}{}
+			continue
+		}
+
+		t0 := g.tokenIndex(p.Token0Address)
+		t1 := g.tokenIndex(p.Token1Address)
+		if t0 == t1 {
+			continue
+		}
+
+		pl := &pool{
+			address:   address,
+			dex:       p.Dex,
+			poolType:  p.PoolType,
+			fee:       p.Fee,
+			stable:    p.Stable,
+			token0:    t0,
+			token1:    t1,
+			reserve0:  pricing.ParseAmount(p.Reserve0),
+			reserve1:  pricing.ParseAmount(p.Reserve1),
+			liquidity: p.Liquidity,
+		}
+
+		g.pools[address] = pl
+		g.adj[t0] = append(g.adj[t0], pl)
+		g.adj[t1] = append(g.adj[t1], pl)
+		k := toPairKey(t0, t1)
+		g.pairs[k] = append(g.pairs[k], pl)
+		g.dirty[t0] = struct{}{}
+		g.dirty[t1] = struct{}{}
+	}
+
+	// keep adjacency lists deepest first so searches follow the best pools
+	for t := range g.dirty {
+		sort.SliceStable(g.adj[t], func(i, j int) bool {
+			return g.adj[t][i].liquidity > g.adj[t][j].liquidity
+		})
+		delete(g.dirty, t)
+	}
+}
+
+func (g *Graph) tokenIndex(address string) int32 {
+	address = strings.ToLower(address)
+	if i, ok := g.tokens[address]; ok {
+		return i
+	}
+
+	i := int32(len(g.addrs))
+	g.tokens[address] = i
+	g.addrs = append(g.addrs, address)
+	g.adj = append(g.adj, nil)
+	return i
+}
+
+type search struct {
+	g        *Graph
+	to       int32
+	maxHops  int
+	visits   int
+	visited  map[int32]bool
+	path     []*pool
+	tokens   []int32
+	found    [][]*pool
+	foundTok [][]int32
+}
+
+func (s *search) walk(token int32) {
+	s.visits++
+	if s.visits > maxVisits {
+		return
+	}
+
+	// pools straight to the destination
+	for _, p := range s.g.pairs[toPairKey(token, s.to)] {
+		path := append(append([]*pool{}, s.path...), p)
+		tokens := append(append([]int32{}, s.tokens...), s.to)
+		s.found = append(s.found, path)
+		s.foundTok = append(s.foundTok, tokens)
+	}
+
+	if len(s.path)+1 >= s.maxHops {
+		return
+	}
+
+	branches := 0
+	for _, p := range s.g.adj[token] {
+		if branches >= maxBranch {
+			break
+		}
+
+		next := p.other(token)
+		if next == s.to || s.visited[next] {
+			continue
+		}
+		branches++
+
+		s.visited[next] = true
+		s.path = append(s.path, p)
+		s.tokens = append(s.tokens, next)
+
+		s.walk(next)
+
+		s.path = s.path[:len(s.path)-1]
+		s.tokens = s.tokens[:len(s.tokens)-1]
+		s.visited[next] = false
+	}
+}
+
+// FindRoutes returns up to limit paths of at most maxHops pools between two
+// tokens. When amountIn is set routes are ranked by quoted output, otherwise
+// by hop count and then by the shallowest pool along the path.
+func (g *Graph) FindRoutes(tokenIn string, tokenOut string, maxHops int, limit int, amountIn *big.Int) []*types.Route {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	routes := make([]*types.Route, 0)
+
+	from, ok := g.tokens[strings.ToLower(tokenIn)]
+	if !ok {
+		return routes
+	}
+
+	to, ok := g.tokens[strings.ToLower(tokenOut)]
+	if !ok || from == to {
+		return routes
+	}
+
+	if maxHops <= 0 || maxHops > MaxHops {
+		maxHops = MaxHops
+	}
+
+	s := &search{
+		g:       g,
+		to:      to,
+		maxHops: maxHops,
+		visited: map[int32]bool{from: true},
+		tokens:  []int32{from},
+	}
+	s.walk(from)
+
+	for i, path := range s.found {
+		routes = append(routes, g.toRoute(path, s.foundTok[i], amountIn))
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if amountIn != nil {
+			oi, oj := pricing.ParseAmount(routes[i].AmountOut), pricing.ParseAmount(routes[j].AmountOut)
+			if c := oi.Cmp(oj); c != 0 {
+				return c > 0
+			}
+		}
+
+		if len(routes[i].Hops) != len(routes[j].Hops) {
+			return len(routes[i].Hops) < len(routes[j].Hops)
+		}
+
+		return routes[i].Liquidity > routes[j].Liquidity
+	})
+
+	if limit > 0 && len(routes) > limit {
+		routes = routes[:limit]
+	}
+
+	return routes
+}
+
+func (g *Graph) toRoute(path []*pool, tokens []int32, amountIn *big.Int) *types.Route {
+	route := &types.Route{
+		Hops: make([]*types.RouteHop, 0, len(path)),
+	}
+
+	var amount *big.Int
+	if amountIn != nil {
+		amount = new(big.Int).Set(amountIn)
+		route.AmountIn = amountIn.String()
+	}
+
+	for i, p := range path {
+		hop := &types.RouteHop{
+			PoolAddress: p.address,
+			Dex:         p.dex,
+			PoolType:    p.poolType,
+			Fee:         p.fee,
+			Stable:      p.stable,
+			TokenIn:     g.addrs[tokens[i]],
+			TokenOut:    g.addrs[tokens[i+1]],
+			Liquidity:   p.liquidity,
+		}
+
+		if amount != nil {
+			amount = p.quote(tokens[i], amount)
+			hop.AmountOut = amount.String()
+		}
+
+		if i == 0 || p.liquidity < route.Liquidity {
+			route.Liquidity = p.liquidity
+		}
+
+		route.Hops = append(route.Hops, hop)
+	}
+
+	if amount != nil {
+		route.AmountOut = amount.String()
+	}
+
+	return route
+}
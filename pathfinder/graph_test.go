@@ -0,0 +1,84 @@
+package pathfinder
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/autoapev1/indexer/types"
+)
+
+const (
+	tokenA = "0x000000000000000000000000000000000000000a"
+	tokenB = "0x000000000000000000000000000000000000000b"
+	tokenC = "0x000000000000000000000000000000000000000c"
+	tokenD = "0x000000000000000000000000000000000000000d"
+)
+
+func testGraph() *Graph {
+	g := NewGraph(1)
+	g.AddPairs([]*types.Pair{
+		// shallow direct pool
+		{PoolAddress: "0x01", Token0Address: tokenA, Token1Address: tokenD, PoolType: 2, Reserve0: "1000", Reserve1: "1000", Liquidity: 10, CreatedAt: 1},
+		// deep two hop route through b
+		{PoolAddress: "0x02", Token0Address: tokenA, Token1Address: tokenB, PoolType: 2, Reserve0: "1000000", Reserve1: "1000000", Liquidity: 1000, CreatedAt: 2},
+		{PoolAddress: "0x03", Token0Address: tokenB, Token1Address: tokenD, PoolType: 2, Reserve0: "1000000", Reserve1: "1000000", Liquidity: 1000, CreatedAt: 3},
+		// unrelated pool
+		{PoolAddress: "0x04", Token0Address: tokenB, Token1Address: tokenC, PoolType: 2, Reserve0: "1", Reserve1: "1", Liquidity: 1, CreatedAt: 4},
+	})
+	return g
+}
+
+func TestFindRoutesByHops(t *testing.T) {
+	g := testGraph()
+
+	routes := g.FindRoutes(tokenA, tokenD, 3, 10, nil)
+	if len(routes) != 2 {
+		t.Fatalf("expected 2 routes, got %d", len(routes))
+	}
+
+	if len(routes[0].Hops) != 1 || routes[0].Hops[0].PoolAddress != "0x01" {
+		t.Errorf("expected the direct pool first without an amount, got %+v", routes[0].Hops[0])
+	}
+
+	if routes := g.FindRoutes(tokenA, tokenD, 1, 10, nil); len(routes) != 1 {
+		t.Errorf("expected 1 route with max_hops 1, got %d", len(routes))
+	}
+}
+
+func TestFindRoutesByAmount(t *testing.T) {
+	g := testGraph()
+
+	routes := g.FindRoutes(tokenA, tokenD, 3, 10, big.NewInt(500))
+	if len(routes) != 2 {
+		t.Fatalf("expected 2 routes, got %d", len(routes))
+	}
+
+	if len(routes[0].Hops) != 2 {
+		t.Errorf("expected the deep two hop route first with an amount, got %d hops", len(routes[0].Hops))
+	}
+
+	if routes[0].Hops[0].TokenOut != tokenB || routes[0].AmountOut == "" {
+		t.Errorf("unexpected route %+v", routes[0])
+	}
+}
+
+func TestAddPairsUpdatesReserves(t *testing.T) {
+	g := testGraph()
+
+	g.AddPairs([]*types.Pair{
+		{PoolAddress: "0x01", Token0Address: tokenA, Token1Address: tokenD, PoolType: 2, Reserve0: "100000000", Reserve1: "100000000", Liquidity: 100000, ReservesBlock: 10},
+	})
+
+	if g.Height() != 10 {
+		t.Errorf("expected height 10, got %d", g.Height())
+	}
+
+	if _, pools := g.Size(); pools != 4 {
+		t.Errorf("expected 4 pools, got %d", pools)
+	}
+
+	routes := g.FindRoutes(tokenA, tokenD, 3, 10, big.NewInt(500))
+	if routes[0].Hops[0].PoolAddress != "0x01" {
+		t.Errorf("expected the updated direct pool first, got %s", routes[0].Hops[0].PoolAddress)
+	}
+}
@@ -0,0 +1,241 @@
+package pricing
+
+import (
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/autoapev1/indexer/types"
+)
+
+// Oracle values token amounts in usd. Stablecoins are priced at 1 usd and the
+// wrapped native token is priced from its deepest stablecoin pair, every other
+// token is valued through the side of a pair that can be priced.
+type Oracle struct {
+	lock      sync.RWMutex
+	native    string
+	nativeUSD float64
+	stables   map[string]struct{}
+	decimals  map[string]uint8
+}
+
+func NewOracle(wrappedNative string, stablecoins []string) *Oracle {
+	o := &Oracle{
+		native:   strings.ToLower(wrappedNative),
+		stables:  make(map[string]struct{}, len(stablecoins)),
+		decimals: make(map[string]uint8),
+	}
+
+	for _, s := range stablecoins {
+		o.stables[strings.ToLower(s)] = struct{}{}
+	}
+
+	return o
+}
+
+// Native returns the wrapped native token address.
+func (o *Oracle) Native() string {
+	return o.native
+}
+
+// Stablecoins returns the stablecoin addresses.
+func (o *Oracle) Stablecoins() []string {
+	stables := make([]string, 0, len(o.stables))
+	for s := range o.stables {
+		stables = append(stables, s)
+	}
+
+	return stables
+}
+
+func (o *Oracle) NativeUSD() float64 {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+
+	return o.nativeUSD
+}
+
+func (o *Oracle) SetNativeUSD(price float64) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.nativeUSD = price
+}
+
+func (o *Oracle) SetDecimals(tokens []*types.Token) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	for _, t := range tokens {
+		o.decimals[strings.ToLower(t.Address)] = t.Decimals
+	}
+}
+
+// PriceUSD returns the usd price of a whole token, if it is known.
+func (o *Oracle) PriceUSD(token string) (float64, bool) {
+	token = strings.ToLower(token)
+
+	if _, ok := o.stables[token]; ok {
+		return 1, true
+	}
+
+	if token == o.native {
+		p := o.NativeUSD()
+		return p, p > 0
+	}
+
+	return 0, false
+}
+
+// Amount converts a raw token amount to whole tokens.
+func (o *Oracle) Amount(token string, raw *big.Int) (float64, bool) {
+	if raw == nil {
+		return 0, false
+	}
+
+	o.lock.RLock()
+	decimals, ok := o.decimals[strings.ToLower(token)]
+	o.lock.RUnlock()
+	if !ok {
+		return 0, false
+	}
+
+	return toFloat(raw, decimals), true
+}
+
+// ValueUSD returns the usd value of a raw token amount.
+func (o *Oracle) ValueUSD(token string, raw *big.Int) (float64, bool) {
+	price, ok := o.PriceUSD(token)
+	if !ok {
+		return 0, false
+	}
+
+	amount, ok := o.Amount(token, raw)
+	if !ok {
+		return 0, false
+	}
+
+	return amount * price, true
+}
+
+// Liquidity returns the usd tvl of a pair with the given reserves. When only one
+// side can be priced the other side is assumed to hold the same value.
+func (o *Oracle) Liquidity(p *types.Pair, reserve0 *big.Int, reserve1 *big.Int) float64 {
+	v0, ok0 := o.ValueUSD(p.Token0Address, reserve0)
+	v1, ok1 := o.ValueUSD(p.Token1Address, reserve1)
+
+	switch {
+	case ok0 && ok1:
+		return v0 + v1
+	case ok0:
+		return v0 * 2
+	case ok1:
+		return v1 * 2
+	default:
+		return 0
+	}
+}
+
+// Volume returns the usd value of a swap using the side that can be priced.
+func (o *Oracle) Volume(p *types.Pair, volume0 *big.Int, volume1 *big.Int) float64 {
+	if v, ok := o.ValueUSD(p.Token0Address, volume0); ok {
+		return v
+	}
+
+	if v, ok := o.ValueUSD(p.Token1Address, volume1); ok {
+		return v
+	}
+
+	return 0
+}
+
+// PriceFromPair returns the usd price of token implied by the reserves of a
+// pair whose other side can be priced.
+func (o *Oracle) PriceFromPair(token string, p *types.Pair) (float64, bool) {
+	token = strings.ToLower(token)
+
+	var tokenReserve, otherReserve, other string
+	switch token {
+	case p.Token0Address:
+		tokenReserve, otherReserve, other = p.Reserve0, p.Reserve1, p.Token1Address
+	case p.Token1Address:
+		tokenReserve, otherReserve, other = p.Reserve1, p.Reserve0, p.Token0Address
+	default:
+		return 0, false
+	}
+
+	value, ok := o.ValueUSD(other, ParseAmount(otherReserve))
+	if !ok || value == 0 {
+		return 0, false
+	}
+
+	amount, ok := o.Amount(token, ParseAmount(tokenReserve))
+	if !ok || amount == 0 {
+		return 0, false
+	}
+
+	return value / amount, true
+}
+
+// NativeUSDFromPairs returns the native token price from the deepest wrapped
+// native / stablecoin pair, or 0 if none can be used.
+func (o *Oracle) NativeUSDFromPairs(pairs []*types.Pair) float64 {
+	var (
+		price float64
+		depth float64
+	)
+
+	for _, p := range pairs {
+		var nativeReserve, stableReserve string
+		var stable string
+
+		switch {
+		case p.Token0Address == o.native:
+			nativeReserve, stableReserve, stable = p.Reserve0, p.Reserve1, p.Token1Address
+		case p.Token1Address == o.native:
+			nativeReserve, stableReserve, stable = p.Reserve1, p.Reserve0, p.Token0Address
+		default:
+			continue
+		}
+
+		if _, ok := o.stables[stable]; !ok {
+			continue
+		}
+
+		n, ok := o.Amount(o.native, ParseAmount(nativeReserve))
+		if !ok || n == 0 {
+			continue
+		}
+
+		s, ok := o.Amount(stable, ParseAmount(stableReserve))
+		if !ok || s <= depth {
+			continue
+		}
+
+		depth = s
+		price = s / n
+	}
+
+	return price
+}
+
+// ParseAmount parses a base 10 amount, returning 0 if it is invalid.
+func ParseAmount(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return new(big.Int)
+	}
+
+	return v
+}
+
+func toFloat(raw *big.Int, decimals uint8) float64 {
+	f := new(big.Float).SetInt(raw)
+	if decimals > 0 {
+		exp := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+		f.Quo(f, new(big.Float).SetInt(exp))
+	}
+
+	v, _ := f.Float64()
+	return v
+}
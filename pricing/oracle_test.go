@@ -0,0 +1,77 @@
+package pricing
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/autoapev1/indexer/types"
+)
+
+const (
+	weth = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+	usdc = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	pepe = "0x6982508145454ce325ddbe47a25d4ec3d2311933"
+)
+
+func newTestOracle() *Oracle {
+	o := NewOracle(weth, []string{usdc})
+	o.SetDecimals([]*types.Token{
+		{Address: weth, Decimals: 18},
+		{Address: usdc, Decimals: 6},
+		{Address: pepe, Decimals: 18},
+	})
+	return o
+}
+
+func TestNativeUSDFromPairs(t *testing.T) {
+	o := newTestOracle()
+
+	pairs := []*types.Pair{
+		// shallow pool at a bad price
+		{Token0Address: usdc, Token1Address: weth, Reserve0: "1000000000", Reserve1: "1000000000000000000"},
+		// deep pool, 2000 usd per eth
+		{Token0Address: usdc, Token1Address: weth, Reserve0: "20000000000000", Reserve1: "10000000000000000000000"},
+	}
+
+	price := o.NativeUSDFromPairs(pairs)
+	if math.Abs(price-2000) > 1e-9 {
+		t.Errorf("native price is not correct: %f", price)
+	}
+}
+
+func TestLiquidity(t *testing.T) {
+	o := newTestOracle()
+	o.SetNativeUSD(2000)
+
+	p := &types.Pair{Token0Address: pepe, Token1Address: weth}
+
+	// 5 eth on the priced side
+	reserve1, _ := new(big.Int).SetString("5000000000000000000", 10)
+	liquidity := o.Liquidity(p, big.NewInt(123), reserve1)
+	if math.Abs(liquidity-20000) > 1e-9 {
+		t.Errorf("liquidity is not correct: %f", liquidity)
+	}
+
+	unpriced := &types.Pair{Token0Address: pepe, Token1Address: pepe}
+	if l := o.Liquidity(unpriced, big.NewInt(1), big.NewInt(1)); l != 0 {
+		t.Errorf("unpriced liquidity should be 0: %f", l)
+	}
+}
+
+func TestPriceFromPair(t *testing.T) {
+	o := newTestOracle()
+	o.SetNativeUSD(2000)
+
+	// 1,000,000 pepe against 1 eth
+	p := &types.Pair{Token0Address: pepe, Token1Address: weth, Reserve0: "1000000000000000000000000", Reserve1: "1000000000000000000"}
+
+	price, ok := o.PriceFromPair(pepe, p)
+	if !ok || math.Abs(price-0.002) > 1e-12 {
+		t.Errorf("pepe price is not correct: %f", price)
+	}
+
+	if _, ok := o.PriceFromPair(usdc, p); ok {
+		t.Error("token outside of the pair should not be priced")
+	}
+}
@@ -0,0 +1,1183 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: indexer/v1/indexer.proto
+
+// The indexer gRPC service. Every unary rpc is served by the JSON-RPC method
+// of the same name (idx_ or admin_ prefixed), with the same validation, and
+// the fields of the messages follow the JSON field names.
+
+package indexerv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Indexer_GetBlockNumber_FullMethodName          = "/indexer.v1.Indexer/GetBlockNumber"
+	Indexer_GetChains_FullMethodName               = "/indexer.v1.Indexer/GetChains"
+	Indexer_GetHeights_FullMethodName              = "/indexer.v1.Indexer/GetHeights"
+	Indexer_GetBlockTimestamps_FullMethodName      = "/indexer.v1.Indexer/GetBlockTimestamps"
+	Indexer_GetBlockAtTimestamp_FullMethodName     = "/indexer.v1.Indexer/GetBlockAtTimestamp"
+	Indexer_GetBlocks_FullMethodName               = "/indexer.v1.Indexer/GetBlocks"
+	Indexer_FindTokens_FullMethodName              = "/indexer.v1.Indexer/FindTokens"
+	Indexer_StreamTokens_FullMethodName            = "/indexer.v1.Indexer/StreamTokens"
+	Indexer_GetTokenCount_FullMethodName           = "/indexer.v1.Indexer/GetTokenCount"
+	Indexer_FindPairs_FullMethodName               = "/indexer.v1.Indexer/FindPairs"
+	Indexer_StreamPairs_FullMethodName             = "/indexer.v1.Indexer/StreamPairs"
+	Indexer_GetPairCount_FullMethodName            = "/indexer.v1.Indexer/GetPairCount"
+	Indexer_GetPairReserves_FullMethodName         = "/indexer.v1.Indexer/GetPairReserves"
+	Indexer_GetTokenMarkets_FullMethodName         = "/indexer.v1.Indexer/GetTokenMarkets"
+	Indexer_SubscribePairs_FullMethodName          = "/indexer.v1.Indexer/SubscribePairs"
+	Indexer_FindRoutes_FullMethodName              = "/indexer.v1.Indexer/FindRoutes"
+	Indexer_GetLogs_FullMethodName                 = "/indexer.v1.Indexer/GetLogs"
+	Indexer_GetWalletBalanceHistory_FullMethodName = "/indexer.v1.Indexer/GetWalletBalanceHistory"
+	Indexer_GetWalletTransfers_FullMethodName      = "/indexer.v1.Indexer/GetWalletTransfers"
+	Indexer_CreateWebhook_FullMethodName           = "/indexer.v1.Indexer/CreateWebhook"
+	Indexer_DeleteWebhook_FullMethodName           = "/indexer.v1.Indexer/DeleteWebhook"
+	Indexer_ListWebhooks_FullMethodName            = "/indexer.v1.Indexer/ListWebhooks"
+	Indexer_GetWebhookDeliveries_FullMethodName    = "/indexer.v1.Indexer/GetWebhookDeliveries"
+	Indexer_GetWebhookDeadLetters_FullMethodName   = "/indexer.v1.Indexer/GetWebhookDeadLetters"
+	Indexer_WatchWallets_FullMethodName            = "/indexer.v1.Indexer/WatchWallets"
+	Indexer_UnwatchWallets_FullMethodName          = "/indexer.v1.Indexer/UnwatchWallets"
+	Indexer_GetWatchedWallets_FullMethodName       = "/indexer.v1.Indexer/GetWatchedWallets"
+)
+
+// IndexerClient is the client API for Indexer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type IndexerClient interface {
+	// global
+	GetBlockNumber(ctx context.Context, in *GetBlockNumberRequest, opts ...grpc.CallOption) (*GetBlockNumberResponse, error)
+	GetChains(ctx context.Context, in *GetChainsRequest, opts ...grpc.CallOption) (*GetChainsResponse, error)
+	GetHeights(ctx context.Context, in *GetHeightsRequest, opts ...grpc.CallOption) (*Heights, error)
+	// blocks
+	GetBlockTimestamps(ctx context.Context, in *GetBlockTimestampsRequest, opts ...grpc.CallOption) (*GetBlockTimestampsResponse, error)
+	GetBlockAtTimestamp(ctx context.Context, in *GetBlockAtTimestampRequest, opts ...grpc.CallOption) (*BlockTimestamp, error)
+	GetBlocks(ctx context.Context, in *GetBlocksRequest, opts ...grpc.CallOption) (*GetBlocksResponse, error)
+	// tokens
+	FindTokens(ctx context.Context, in *FindTokensRequest, opts ...grpc.CallOption) (*FindTokensResponse, error)
+	// StreamTokens sends every token matching the filter, paging through the
+	// result set from options.offset. options.limit caps the total, 0 streams all.
+	StreamTokens(ctx context.Context, in *FindTokensRequest, opts ...grpc.CallOption) (Indexer_StreamTokensClient, error)
+	GetTokenCount(ctx context.Context, in *GetTokenCountRequest, opts ...grpc.CallOption) (*CountResponse, error)
+	// pairs
+	FindPairs(ctx context.Context, in *FindPairsRequest, opts ...grpc.CallOption) (*FindPairsResponse, error)
+	// StreamPairs is StreamTokens for pairs.
+	StreamPairs(ctx context.Context, in *FindPairsRequest, opts ...grpc.CallOption) (Indexer_StreamPairsClient, error)
+	GetPairCount(ctx context.Context, in *GetPairCountRequest, opts ...grpc.CallOption) (*CountResponse, error)
+	GetPairReserves(ctx context.Context, in *GetPairReservesRequest, opts ...grpc.CallOption) (*GetPairReservesResponse, error)
+	GetTokenMarkets(ctx context.Context, in *GetTokenMarketsRequest, opts ...grpc.CallOption) (*GetTokenMarketsResponse, error)
+	// SubscribePairs sends the new pairs matching the filter as they are indexed.
+	SubscribePairs(ctx context.Context, in *SubscribePairsRequest, opts ...grpc.CallOption) (Indexer_SubscribePairsClient, error)
+	// routes
+	FindRoutes(ctx context.Context, in *FindRoutesRequest, opts ...grpc.CallOption) (*FindRoutesResponse, error)
+	// logs
+	GetLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (*GetLogsResponse, error)
+	// watched wallets
+	GetWalletBalanceHistory(ctx context.Context, in *GetWalletHistoryRequest, opts ...grpc.CallOption) (*GetWalletBalanceHistoryResponse, error)
+	GetWalletTransfers(ctx context.Context, in *GetWalletHistoryRequest, opts ...grpc.CallOption) (*GetWalletTransfersResponse, error)
+	// webhooks
+	CreateWebhook(ctx context.Context, in *CreateWebhookRequest, opts ...grpc.CallOption) (*Webhook, error)
+	DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*DeleteWebhookResponse, error)
+	ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error)
+	GetWebhookDeliveries(ctx context.Context, in *GetWebhookLogRequest, opts ...grpc.CallOption) (*GetWebhookDeliveriesResponse, error)
+	GetWebhookDeadLetters(ctx context.Context, in *GetWebhookLogRequest, opts ...grpc.CallOption) (*GetWebhookDeadLettersResponse, error)
+	// watchlist, master key only
+	WatchWallets(ctx context.Context, in *WatchWalletsRequest, opts ...grpc.CallOption) (*CountResponse, error)
+	UnwatchWallets(ctx context.Context, in *UnwatchWalletsRequest, opts ...grpc.CallOption) (*CountResponse, error)
+	GetWatchedWallets(ctx context.Context, in *GetWatchedWalletsRequest, opts ...grpc.CallOption) (*GetWatchedWalletsResponse, error)
+}
+
+type indexerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIndexerClient(cc grpc.ClientConnInterface) IndexerClient {
+	return &indexerClient{cc}
+}
+
+func (c *indexerClient) GetBlockNumber(ctx context.Context, in *GetBlockNumberRequest, opts ...grpc.CallOption) (*GetBlockNumberResponse, error) {
+	out := new(GetBlockNumberResponse)
+	err := c.cc.Invoke(ctx, Indexer_GetBlockNumber_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) GetChains(ctx context.Context, in *GetChainsRequest, opts ...grpc.CallOption) (*GetChainsResponse, error) {
+	out := new(GetChainsResponse)
+	err := c.cc.Invoke(ctx, Indexer_GetChains_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) GetHeights(ctx context.Context, in *GetHeightsRequest, opts ...grpc.CallOption) (*Heights, error) {
+	out := new(Heights)
+	err := c.cc.Invoke(ctx, Indexer_GetHeights_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) GetBlockTimestamps(ctx context.Context, in *GetBlockTimestampsRequest, opts ...grpc.CallOption) (*GetBlockTimestampsResponse, error) {
+	out := new(GetBlockTimestampsResponse)
+	err := c.cc.Invoke(ctx, Indexer_GetBlockTimestamps_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) GetBlockAtTimestamp(ctx context.Context, in *GetBlockAtTimestampRequest, opts ...grpc.CallOption) (*BlockTimestamp, error) {
+	out := new(BlockTimestamp)
+	err := c.cc.Invoke(ctx, Indexer_GetBlockAtTimestamp_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) GetBlocks(ctx context.Context, in *GetBlocksRequest, opts ...grpc.CallOption) (*GetBlocksResponse, error) {
+	out := new(GetBlocksResponse)
+	err := c.cc.Invoke(ctx, Indexer_GetBlocks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) FindTokens(ctx context.Context, in *FindTokensRequest, opts ...grpc.CallOption) (*FindTokensResponse, error) {
+	out := new(FindTokensResponse)
+	err := c.cc.Invoke(ctx, Indexer_FindTokens_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) StreamTokens(ctx context.Context, in *FindTokensRequest, opts ...grpc.CallOption) (Indexer_StreamTokensClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Indexer_ServiceDesc.Streams[0], Indexer_StreamTokens_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &indexerStreamTokensClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Indexer_StreamTokensClient interface {
+	Recv() (*Token, error)
+	grpc.ClientStream
+}
+
+type indexerStreamTokensClient struct {
+	grpc.ClientStream
+}
+
+func (x *indexerStreamTokensClient) Recv() (*Token, error) {
+	m := new(Token)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *indexerClient) GetTokenCount(ctx context.Context, in *GetTokenCountRequest, opts ...grpc.CallOption) (*CountResponse, error) {
+	out := new(CountResponse)
+	err := c.cc.Invoke(ctx, Indexer_GetTokenCount_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) FindPairs(ctx context.Context, in *FindPairsRequest, opts ...grpc.CallOption) (*FindPairsResponse, error) {
+	out := new(FindPairsResponse)
+	err := c.cc.Invoke(ctx, Indexer_FindPairs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) StreamPairs(ctx context.Context, in *FindPairsRequest, opts ...grpc.CallOption) (Indexer_StreamPairsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Indexer_ServiceDesc.Streams[1], Indexer_StreamPairs_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &indexerStreamPairsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Indexer_StreamPairsClient interface {
+	Recv() (*Pair, error)
+	grpc.ClientStream
+}
+
+type indexerStreamPairsClient struct {
+	grpc.ClientStream
+}
+
+func (x *indexerStreamPairsClient) Recv() (*Pair, error) {
+	m := new(Pair)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *indexerClient) GetPairCount(ctx context.Context, in *GetPairCountRequest, opts ...grpc.CallOption) (*CountResponse, error) {
+	out := new(CountResponse)
+	err := c.cc.Invoke(ctx, Indexer_GetPairCount_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) GetPairReserves(ctx context.Context, in *GetPairReservesRequest, opts ...grpc.CallOption) (*GetPairReservesResponse, error) {
+	out := new(GetPairReservesResponse)
+	err := c.cc.Invoke(ctx, Indexer_GetPairReserves_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) GetTokenMarkets(ctx context.Context, in *GetTokenMarketsRequest, opts ...grpc.CallOption) (*GetTokenMarketsResponse, error) {
+	out := new(GetTokenMarketsResponse)
+	err := c.cc.Invoke(ctx, Indexer_GetTokenMarkets_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) SubscribePairs(ctx context.Context, in *SubscribePairsRequest, opts ...grpc.CallOption) (Indexer_SubscribePairsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Indexer_ServiceDesc.Streams[2], Indexer_SubscribePairs_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &indexerSubscribePairsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Indexer_SubscribePairsClient interface {
+	Recv() (*Pair, error)
+	grpc.ClientStream
+}
+
+type indexerSubscribePairsClient struct {
+	grpc.ClientStream
+}
+
+func (x *indexerSubscribePairsClient) Recv() (*Pair, error) {
+	m := new(Pair)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *indexerClient) FindRoutes(ctx context.Context, in *FindRoutesRequest, opts ...grpc.CallOption) (*FindRoutesResponse, error) {
+	out := new(FindRoutesResponse)
+	err := c.cc.Invoke(ctx, Indexer_FindRoutes_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) GetLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (*GetLogsResponse, error) {
+	out := new(GetLogsResponse)
+	err := c.cc.Invoke(ctx, Indexer_GetLogs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) GetWalletBalanceHistory(ctx context.Context, in *GetWalletHistoryRequest, opts ...grpc.CallOption) (*GetWalletBalanceHistoryResponse, error) {
+	out := new(GetWalletBalanceHistoryResponse)
+	err := c.cc.Invoke(ctx, Indexer_GetWalletBalanceHistory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) GetWalletTransfers(ctx context.Context, in *GetWalletHistoryRequest, opts ...grpc.CallOption) (*GetWalletTransfersResponse, error) {
+	out := new(GetWalletTransfersResponse)
+	err := c.cc.Invoke(ctx, Indexer_GetWalletTransfers_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) CreateWebhook(ctx context.Context, in *CreateWebhookRequest, opts ...grpc.CallOption) (*Webhook, error) {
+	out := new(Webhook)
+	err := c.cc.Invoke(ctx, Indexer_CreateWebhook_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*DeleteWebhookResponse, error) {
+	out := new(DeleteWebhookResponse)
+	err := c.cc.Invoke(ctx, Indexer_DeleteWebhook_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksResponse, error) {
+	out := new(ListWebhooksResponse)
+	err := c.cc.Invoke(ctx, Indexer_ListWebhooks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) GetWebhookDeliveries(ctx context.Context, in *GetWebhookLogRequest, opts ...grpc.CallOption) (*GetWebhookDeliveriesResponse, error) {
+	out := new(GetWebhookDeliveriesResponse)
+	err := c.cc.Invoke(ctx, Indexer_GetWebhookDeliveries_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) GetWebhookDeadLetters(ctx context.Context, in *GetWebhookLogRequest, opts ...grpc.CallOption) (*GetWebhookDeadLettersResponse, error) {
+	out := new(GetWebhookDeadLettersResponse)
+	err := c.cc.Invoke(ctx, Indexer_GetWebhookDeadLetters_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) WatchWallets(ctx context.Context, in *WatchWalletsRequest, opts ...grpc.CallOption) (*CountResponse, error) {
+	out := new(CountResponse)
+	err := c.cc.Invoke(ctx, Indexer_WatchWallets_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) UnwatchWallets(ctx context.Context, in *UnwatchWalletsRequest, opts ...grpc.CallOption) (*CountResponse, error) {
+	out := new(CountResponse)
+	err := c.cc.Invoke(ctx, Indexer_UnwatchWallets_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerClient) GetWatchedWallets(ctx context.Context, in *GetWatchedWalletsRequest, opts ...grpc.CallOption) (*GetWatchedWalletsResponse, error) {
+	out := new(GetWatchedWalletsResponse)
+	err := c.cc.Invoke(ctx, Indexer_GetWatchedWallets_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IndexerServer is the server API for Indexer service.
+// All implementations must embed UnimplementedIndexerServer
+// for forward compatibility
+type IndexerServer interface {
+	// global
+	GetBlockNumber(context.Context, *GetBlockNumberRequest) (*GetBlockNumberResponse, error)
+	GetChains(context.Context, *GetChainsRequest) (*GetChainsResponse, error)
+	GetHeights(context.Context, *GetHeightsRequest) (*Heights, error)
+	// blocks
+	GetBlockTimestamps(context.Context, *GetBlockTimestampsRequest) (*GetBlockTimestampsResponse, error)
+	GetBlockAtTimestamp(context.Context, *GetBlockAtTimestampRequest) (*BlockTimestamp, error)
+	GetBlocks(context.Context, *GetBlocksRequest) (*GetBlocksResponse, error)
+	// tokens
+	FindTokens(context.Context, *FindTokensRequest) (*FindTokensResponse, error)
+	// StreamTokens sends every token matching the filter, paging through the
+	// result set from options.offset. options.limit caps the total, 0 streams all.
+	StreamTokens(*FindTokensRequest, Indexer_StreamTokensServer) error
+	GetTokenCount(context.Context, *GetTokenCountRequest) (*CountResponse, error)
+	// pairs
+	FindPairs(context.Context, *FindPairsRequest) (*FindPairsResponse, error)
+	// StreamPairs is StreamTokens for pairs.
+	StreamPairs(*FindPairsRequest, Indexer_StreamPairsServer) error
+	GetPairCount(context.Context, *GetPairCountRequest) (*CountResponse, error)
+	GetPairReserves(context.Context, *GetPairReservesRequest) (*GetPairReservesResponse, error)
+	GetTokenMarkets(context.Context, *GetTokenMarketsRequest) (*GetTokenMarketsResponse, error)
+	// SubscribePairs sends the new pairs matching the filter as they are indexed.
+	SubscribePairs(*SubscribePairsRequest, Indexer_SubscribePairsServer) error
+	// routes
+	FindRoutes(context.Context, *FindRoutesRequest) (*FindRoutesResponse, error)
+	// logs
+	GetLogs(context.Context, *GetLogsRequest) (*GetLogsResponse, error)
+	// watched wallets
+	GetWalletBalanceHistory(context.Context, *GetWalletHistoryRequest) (*GetWalletBalanceHistoryResponse, error)
+	GetWalletTransfers(context.Context, *GetWalletHistoryRequest) (*GetWalletTransfersResponse, error)
+	// webhooks
+	CreateWebhook(context.Context, *CreateWebhookRequest) (*Webhook, error)
+	DeleteWebhook(context.Context, *DeleteWebhookRequest) (*DeleteWebhookResponse, error)
+	ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error)
+	GetWebhookDeliveries(context.Context, *GetWebhookLogRequest) (*GetWebhookDeliveriesResponse, error)
+	GetWebhookDeadLetters(context.Context, *GetWebhookLogRequest) (*GetWebhookDeadLettersResponse, error)
+	// watchlist, master key only
+	WatchWallets(context.Context, *WatchWalletsRequest) (*CountResponse, error)
+	UnwatchWallets(context.Context, *UnwatchWalletsRequest) (*CountResponse, error)
+	GetWatchedWallets(context.Context, *GetWatchedWalletsRequest) (*GetWatchedWalletsResponse, error)
+	mustEmbedUnimplementedIndexerServer()
+}
+
+// UnimplementedIndexerServer must be embedded to have forward compatible implementations.
+type UnimplementedIndexerServer struct {
+}
+
+func (UnimplementedIndexerServer) GetBlockNumber(context.Context, *GetBlockNumberRequest) (*GetBlockNumberResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockNumber not implemented")
+}
+func (UnimplementedIndexerServer) GetChains(context.Context, *GetChainsRequest) (*GetChainsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChains not implemented")
+}
+func (UnimplementedIndexerServer) GetHeights(context.Context, *GetHeightsRequest) (*Heights, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHeights not implemented")
+}
+func (UnimplementedIndexerServer) GetBlockTimestamps(context.Context, *GetBlockTimestampsRequest) (*GetBlockTimestampsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockTimestamps not implemented")
+}
+func (UnimplementedIndexerServer) GetBlockAtTimestamp(context.Context, *GetBlockAtTimestampRequest) (*BlockTimestamp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockAtTimestamp not implemented")
+}
+func (UnimplementedIndexerServer) GetBlocks(context.Context, *GetBlocksRequest) (*GetBlocksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlocks not implemented")
+}
+func (UnimplementedIndexerServer) FindTokens(context.Context, *FindTokensRequest) (*FindTokensResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindTokens not implemented")
+}
+func (UnimplementedIndexerServer) StreamTokens(*FindTokensRequest, Indexer_StreamTokensServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTokens not implemented")
+}
+func (UnimplementedIndexerServer) GetTokenCount(context.Context, *GetTokenCountRequest) (*CountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTokenCount not implemented")
+}
+func (UnimplementedIndexerServer) FindPairs(context.Context, *FindPairsRequest) (*FindPairsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindPairs not implemented")
+}
+func (UnimplementedIndexerServer) StreamPairs(*FindPairsRequest, Indexer_StreamPairsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamPairs not implemented")
+}
+func (UnimplementedIndexerServer) GetPairCount(context.Context, *GetPairCountRequest) (*CountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPairCount not implemented")
+}
+func (UnimplementedIndexerServer) GetPairReserves(context.Context, *GetPairReservesRequest) (*GetPairReservesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPairReserves not implemented")
+}
+func (UnimplementedIndexerServer) GetTokenMarkets(context.Context, *GetTokenMarketsRequest) (*GetTokenMarketsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTokenMarkets not implemented")
+}
+func (UnimplementedIndexerServer) SubscribePairs(*SubscribePairsRequest, Indexer_SubscribePairsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribePairs not implemented")
+}
+func (UnimplementedIndexerServer) FindRoutes(context.Context, *FindRoutesRequest) (*FindRoutesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindRoutes not implemented")
+}
+func (UnimplementedIndexerServer) GetLogs(context.Context, *GetLogsRequest) (*GetLogsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLogs not implemented")
+}
+func (UnimplementedIndexerServer) GetWalletBalanceHistory(context.Context, *GetWalletHistoryRequest) (*GetWalletBalanceHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWalletBalanceHistory not implemented")
+}
+func (UnimplementedIndexerServer) GetWalletTransfers(context.Context, *GetWalletHistoryRequest) (*GetWalletTransfersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWalletTransfers not implemented")
+}
+func (UnimplementedIndexerServer) CreateWebhook(context.Context, *CreateWebhookRequest) (*Webhook, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateWebhook not implemented")
+}
+func (UnimplementedIndexerServer) DeleteWebhook(context.Context, *DeleteWebhookRequest) (*DeleteWebhookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWebhook not implemented")
+}
+func (UnimplementedIndexerServer) ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhooks not implemented")
+}
+func (UnimplementedIndexerServer) GetWebhookDeliveries(context.Context, *GetWebhookLogRequest) (*GetWebhookDeliveriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWebhookDeliveries not implemented")
+}
+func (UnimplementedIndexerServer) GetWebhookDeadLetters(context.Context, *GetWebhookLogRequest) (*GetWebhookDeadLettersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWebhookDeadLetters not implemented")
+}
+func (UnimplementedIndexerServer) WatchWallets(context.Context, *WatchWalletsRequest) (*CountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WatchWallets not implemented")
+}
+func (UnimplementedIndexerServer) UnwatchWallets(context.Context, *UnwatchWalletsRequest) (*CountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnwatchWallets not implemented")
+}
+func (UnimplementedIndexerServer) GetWatchedWallets(context.Context, *GetWatchedWalletsRequest) (*GetWatchedWalletsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWatchedWallets not implemented")
+}
+func (UnimplementedIndexerServer) mustEmbedUnimplementedIndexerServer() {}
+
+// UnsafeIndexerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IndexerServer will
+// result in compilation errors.
+type UnsafeIndexerServer interface {
+	mustEmbedUnimplementedIndexerServer()
+}
+
+func RegisterIndexerServer(s grpc.ServiceRegistrar, srv IndexerServer) {
+	s.RegisterService(&Indexer_ServiceDesc, srv)
+}
+
+func _Indexer_GetBlockNumber_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockNumberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).GetBlockNumber(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_GetBlockNumber_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).GetBlockNumber(ctx, req.(*GetBlockNumberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_GetChains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChainsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).GetChains(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_GetChains_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).GetChains(ctx, req.(*GetChainsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_GetHeights_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHeightsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).GetHeights(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_GetHeights_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).GetHeights(ctx, req.(*GetHeightsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_GetBlockTimestamps_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockTimestampsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).GetBlockTimestamps(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_GetBlockTimestamps_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).GetBlockTimestamps(ctx, req.(*GetBlockTimestampsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_GetBlockAtTimestamp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockAtTimestampRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).GetBlockAtTimestamp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_GetBlockAtTimestamp_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).GetBlockAtTimestamp(ctx, req.(*GetBlockAtTimestampRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_GetBlocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).GetBlocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_GetBlocks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).GetBlocks(ctx, req.(*GetBlocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_FindTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindTokensRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).FindTokens(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_FindTokens_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).FindTokens(ctx, req.(*FindTokensRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_StreamTokens_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FindTokensRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IndexerServer).StreamTokens(m, &indexerStreamTokensServer{stream})
+}
+
+type Indexer_StreamTokensServer interface {
+	Send(*Token) error
+	grpc.ServerStream
+}
+
+type indexerStreamTokensServer struct {
+	grpc.ServerStream
+}
+
+func (x *indexerStreamTokensServer) Send(m *Token) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Indexer_GetTokenCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTokenCountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).GetTokenCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_GetTokenCount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).GetTokenCount(ctx, req.(*GetTokenCountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_FindPairs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindPairsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).FindPairs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_FindPairs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).FindPairs(ctx, req.(*FindPairsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_StreamPairs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FindPairsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IndexerServer).StreamPairs(m, &indexerStreamPairsServer{stream})
+}
+
+type Indexer_StreamPairsServer interface {
+	Send(*Pair) error
+	grpc.ServerStream
+}
+
+type indexerStreamPairsServer struct {
+	grpc.ServerStream
+}
+
+func (x *indexerStreamPairsServer) Send(m *Pair) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Indexer_GetPairCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPairCountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).GetPairCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_GetPairCount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).GetPairCount(ctx, req.(*GetPairCountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_GetPairReserves_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPairReservesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).GetPairReserves(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_GetPairReserves_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).GetPairReserves(ctx, req.(*GetPairReservesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_GetTokenMarkets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTokenMarketsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).GetTokenMarkets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_GetTokenMarkets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).GetTokenMarkets(ctx, req.(*GetTokenMarketsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_SubscribePairs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribePairsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IndexerServer).SubscribePairs(m, &indexerSubscribePairsServer{stream})
+}
+
+type Indexer_SubscribePairsServer interface {
+	Send(*Pair) error
+	grpc.ServerStream
+}
+
+type indexerSubscribePairsServer struct {
+	grpc.ServerStream
+}
+
+func (x *indexerSubscribePairsServer) Send(m *Pair) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Indexer_FindRoutes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindRoutesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).FindRoutes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_FindRoutes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).FindRoutes(ctx, req.(*FindRoutesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_GetLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).GetLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_GetLogs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).GetLogs(ctx, req.(*GetLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_GetWalletBalanceHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWalletHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).GetWalletBalanceHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_GetWalletBalanceHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).GetWalletBalanceHistory(ctx, req.(*GetWalletHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_GetWalletTransfers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWalletHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).GetWalletTransfers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_GetWalletTransfers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).GetWalletTransfers(ctx, req.(*GetWalletHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_CreateWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).CreateWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_CreateWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).CreateWebhook(ctx, req.(*CreateWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_DeleteWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).DeleteWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_DeleteWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).DeleteWebhook(ctx, req.(*DeleteWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_ListWebhooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).ListWebhooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_ListWebhooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).ListWebhooks(ctx, req.(*ListWebhooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_GetWebhookDeliveries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWebhookLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).GetWebhookDeliveries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_GetWebhookDeliveries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).GetWebhookDeliveries(ctx, req.(*GetWebhookLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_GetWebhookDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWebhookLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).GetWebhookDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_GetWebhookDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).GetWebhookDeadLetters(ctx, req.(*GetWebhookLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_WatchWallets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WatchWalletsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).WatchWallets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_WatchWallets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).WatchWallets(ctx, req.(*WatchWalletsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_UnwatchWallets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnwatchWalletsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).UnwatchWallets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_UnwatchWallets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).UnwatchWallets(ctx, req.(*UnwatchWalletsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Indexer_GetWatchedWallets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWatchedWalletsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServer).GetWatchedWallets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Indexer_GetWatchedWallets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServer).GetWatchedWallets(ctx, req.(*GetWatchedWalletsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Indexer_ServiceDesc is the grpc.ServiceDesc for Indexer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Indexer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "indexer.v1.Indexer",
+	HandlerType: (*IndexerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetBlockNumber",
+			Handler:    _Indexer_GetBlockNumber_Handler,
+		},
+		{
+			MethodName: "GetChains",
+			Handler:    _Indexer_GetChains_Handler,
+		},
+		{
+			MethodName: "GetHeights",
+			Handler:    _Indexer_GetHeights_Handler,
+		},
+		{
+			MethodName: "GetBlockTimestamps",
+			Handler:    _Indexer_GetBlockTimestamps_Handler,
+		},
+		{
+			MethodName: "GetBlockAtTimestamp",
+			Handler:    _Indexer_GetBlockAtTimestamp_Handler,
+		},
+		{
+			MethodName: "GetBlocks",
+			Handler:    _Indexer_GetBlocks_Handler,
+		},
+		{
+			MethodName: "FindTokens",
+			Handler:    _Indexer_FindTokens_Handler,
+		},
+		{
+			MethodName: "GetTokenCount",
+			Handler:    _Indexer_GetTokenCount_Handler,
+		},
+		{
+			MethodName: "FindPairs",
+			Handler:    _Indexer_FindPairs_Handler,
+		},
+		{
+			MethodName: "GetPairCount",
+			Handler:    _Indexer_GetPairCount_Handler,
+		},
+		{
+			MethodName: "GetPairReserves",
+			Handler:    _Indexer_GetPairReserves_Handler,
+		},
+		{
+			MethodName: "GetTokenMarkets",
+			Handler:    _Indexer_GetTokenMarkets_Handler,
+		},
+		{
+			MethodName: "FindRoutes",
+			Handler:    _Indexer_FindRoutes_Handler,
+		},
+		{
+			MethodName: "GetLogs",
+			Handler:    _Indexer_GetLogs_Handler,
+		},
+		{
+			MethodName: "GetWalletBalanceHistory",
+			Handler:    _Indexer_GetWalletBalanceHistory_Handler,
+		},
+		{
+			MethodName: "GetWalletTransfers",
+			Handler:    _Indexer_GetWalletTransfers_Handler,
+		},
+		{
+			MethodName: "CreateWebhook",
+			Handler:    _Indexer_CreateWebhook_Handler,
+		},
+		{
+			MethodName: "DeleteWebhook",
+			Handler:    _Indexer_DeleteWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhooks",
+			Handler:    _Indexer_ListWebhooks_Handler,
+		},
+		{
+			MethodName: "GetWebhookDeliveries",
+			Handler:    _Indexer_GetWebhookDeliveries_Handler,
+		},
+		{
+			MethodName: "GetWebhookDeadLetters",
+			Handler:    _Indexer_GetWebhookDeadLetters_Handler,
+		},
+		{
+			MethodName: "WatchWallets",
+			Handler:    _Indexer_WatchWallets_Handler,
+		},
+		{
+			MethodName: "UnwatchWallets",
+			Handler:    _Indexer_UnwatchWallets_Handler,
+		},
+		{
+			MethodName: "GetWatchedWallets",
+			Handler:    _Indexer_GetWatchedWallets_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamTokens",
+			Handler:       _Indexer_StreamTokens_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamPairs",
+			Handler:       _Indexer_StreamPairs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribePairs",
+			Handler:       _Indexer_SubscribePairs_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "indexer/v1/indexer.proto",
+}
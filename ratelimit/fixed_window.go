@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// FixedWindow counts the cost of each key in windows starting at its first
+// charge, the cheapest limiter but it allows twice the limit across the edge
+// of two windows.
+type FixedWindow struct {
+	lock    sync.Mutex
+	limit   int
+	window  time.Duration
+	windows map[string]*fixedWindow
+	now     func() time.Time
+}
+
+type fixedWindow struct {
+	start time.Time
+	total int
+}
+
+func NewFixedWindow(limit int, window time.Duration) *FixedWindow {
+	return &FixedWindow{
+		limit:   limit,
+		window:  window,
+		windows: make(map[string]*fixedWindow),
+		now:     time.Now,
+	}
+}
+
+func (l *FixedWindow) Allow(key string, cost int) Result {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.start.Add(l.window)) {
+		w = &fixedWindow{start: now}
+		l.windows[key] = w
+	}
+
+	c := capCost(cost, l.limit)
+	allowed := w.total+c <= l.limit
+	if allowed {
+		w.total += c
+	}
+
+	return l.result(w, allowed)
+}
+
+func (l *FixedWindow) Peek(key string) Result {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.start.Add(l.window)) {
+		w = &fixedWindow{start: now}
+	}
+
+	return l.result(w, true)
+}
+
+func (l *FixedWindow) Evict() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	evicted := 0
+	for key, w := range l.windows {
+		if !now.Before(w.start.Add(l.window)) {
+			delete(l.windows, key)
+			evicted++
+		}
+	}
+
+	return evicted
+}
+
+func (l *FixedWindow) result(w *fixedWindow, allowed bool) Result {
+	return Result{
+		Allowed:   allowed,
+		Limit:     l.limit,
+		Remaining: l.limit - w.total,
+		Reset:     w.start.Add(l.window).Unix(),
+	}
+}
+
+var _ Limiter = (*FixedWindow)(nil)
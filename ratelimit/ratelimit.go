@@ -0,0 +1,80 @@
+// Package ratelimit limits the cost each key can spend per window.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Limiter limits the cost charged to each key per window. Costs above the
+// limit are charged as the limit, so an expensive call still fits a fresh key.
+type Limiter interface {
+	// Allow charges cost to key if it fits, nothing otherwise.
+	Allow(key string, cost int) Result
+	// Peek returns the state of key without charging it.
+	Peek(key string) Result
+	// Evict forgets the keys that are back to a fresh state, it returns the
+	// number of keys evicted.
+	Evict() int
+}
+
+// Result is the state of a key after a charge.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Reset     int64 // unix, when the key is back to its full limit
+}
+
+type Algorithm string
+
+const (
+	AlgorithmTokenBucket Algorithm = "token-bucket"
+	AlgorithmSlidingLog  Algorithm = "sliding-log"
+	AlgorithmFixedWindow Algorithm = "fixed-window"
+)
+
+// New returns a limiter of limit per window using algorithm, a token bucket
+// if algorithm is empty.
+func New(algorithm Algorithm, limit int, window time.Duration) (Limiter, error) {
+	if limit <= 0 || window <= 0 {
+		return nil, fmt.Errorf("invalid rate limit: %d per %s", limit, window)
+	}
+
+	switch algorithm {
+	case AlgorithmTokenBucket, "":
+		return NewTokenBucket(limit, window), nil
+	case AlgorithmSlidingLog:
+		return NewSlidingLog(limit, window), nil
+	case AlgorithmFixedWindow:
+		return NewFixedWindow(limit, window), nil
+	default:
+		return nil, fmt.Errorf("invalid rate limit algorithm: %s", algorithm)
+	}
+}
+
+// RunEviction evicts idle keys of l every interval until ctx is done.
+func RunEviction(ctx context.Context, l Limiter, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.Evict()
+		}
+	}
+}
+
+func capCost(cost int, limit int) int {
+	if cost < 1 {
+		return 1
+	}
+	if cost > limit {
+		return limit
+	}
+	return cost
+}
@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time { return c.t }
+
+func (c *clock) add(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestTokenBucket(t *testing.T) {
+	c := &clock{t: time.Unix(1700000000, 0)}
+	l := NewTokenBucket(10, time.Minute)
+	l.now = c.now
+
+	if r := l.Allow("a", 8); !r.Allowed || r.Remaining != 2 {
+		t.Fatalf("expected 2 remaining, got %+v", r)
+	}
+
+	if r := l.Allow("a", 3); r.Allowed {
+		t.Fatalf("expected cost 3 to be refused, got %+v", r)
+	}
+
+	// 6 seconds refill one token
+	c.add(6 * time.Second)
+	if r := l.Allow("a", 3); !r.Allowed || r.Remaining != 0 {
+		t.Fatalf("expected cost 3 to fit after a refill, got %+v", r)
+	}
+
+	// other keys have their own bucket, costs above the limit are capped
+	if r := l.Allow("b", 50); !r.Allowed || r.Remaining != 0 {
+		t.Fatalf("expected a capped cost on a fresh key, got %+v", r)
+	}
+
+	if n := l.Evict(); n != 0 {
+		t.Fatalf("expected no idle keys, evicted %d", n)
+	}
+
+	c.add(time.Minute)
+	if n := l.Evict(); n != 2 {
+		t.Fatalf("expected 2 idle keys, evicted %d", n)
+	}
+}
+
+func TestSlidingLog(t *testing.T) {
+	c := &clock{t: time.Unix(1700000000, 0)}
+	l := NewSlidingLog(10, time.Minute)
+	l.now = c.now
+
+	l.Allow("a", 6)
+	c.add(30 * time.Second)
+
+	if r := l.Allow("a", 4); !r.Allowed || r.Remaining != 0 {
+		t.Fatalf("expected 0 remaining, got %+v", r)
+	}
+
+	// the first charge is still in the window
+	c.add(29 * time.Second)
+	if r := l.Allow("a", 1); r.Allowed {
+		t.Fatalf("expected a full window, got %+v", r)
+	}
+
+	c.add(time.Second)
+	if r := l.Allow("a", 6); !r.Allowed || r.Remaining != 0 {
+		t.Fatalf("expected the first charge to leave the window, got %+v", r)
+	}
+
+	if n := l.Evict(); n != 0 {
+		t.Fatalf("expected no idle keys, evicted %d", n)
+	}
+
+	c.add(time.Minute)
+	if n := l.Evict(); n != 1 {
+		t.Fatalf("expected 1 idle key, evicted %d", n)
+	}
+}
+
+func TestFixedWindow(t *testing.T) {
+	c := &clock{t: time.Unix(1700000000, 0)}
+	l := NewFixedWindow(10, time.Minute)
+	l.now = c.now
+
+	if r := l.Allow("a", 10); !r.Allowed || r.Reset != 1700000060 {
+		t.Fatalf("expected the window to reset in a minute, got %+v", r)
+	}
+
+	if r := l.Allow("a", 1); r.Allowed {
+		t.Fatalf("expected a full window, got %+v", r)
+	}
+
+	c.add(time.Minute)
+	if r := l.Peek("a"); r.Remaining != 10 {
+		t.Fatalf("expected a new window, got %+v", r)
+	}
+
+	if n := l.Evict(); n != 1 {
+		t.Fatalf("expected 1 idle key, evicted %d", n)
+	}
+}
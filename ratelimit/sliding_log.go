@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// SlidingLog keeps the charges of each key over the last window, so the limit
+// holds for any window and not only aligned ones.
+type SlidingLog struct {
+	lock   sync.Mutex
+	limit  int
+	window time.Duration
+	logs   map[string]*chargeLog
+	now    func() time.Time
+}
+
+type chargeLog struct {
+	charges []charge // oldest first
+	total   int
+}
+
+type charge struct {
+	at   time.Time
+	cost int
+}
+
+func NewSlidingLog(limit int, window time.Duration) *SlidingLog {
+	return &SlidingLog{
+		limit:  limit,
+		window: window,
+		logs:   make(map[string]*chargeLog),
+		now:    time.Now,
+	}
+}
+
+func (l *SlidingLog) Allow(key string, cost int) Result {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	log, ok := l.logs[key]
+	if !ok {
+		log = &chargeLog{}
+		l.logs[key] = log
+	}
+	l.prune(log, now)
+
+	c := capCost(cost, l.limit)
+	allowed := log.total+c <= l.limit
+	if allowed {
+		log.charges = append(log.charges, charge{at: now, cost: c})
+		log.total += c
+	}
+
+	return l.result(log, now, allowed)
+}
+
+func (l *SlidingLog) Peek(key string) Result {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	log, ok := l.logs[key]
+	if !ok {
+		return l.result(&chargeLog{}, now, true)
+	}
+	l.prune(log, now)
+
+	return l.result(log, now, true)
+}
+
+func (l *SlidingLog) Evict() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	evicted := 0
+	for key, log := range l.logs {
+		if l.prune(log, now); log.total == 0 {
+			delete(l.logs, key)
+			evicted++
+		}
+	}
+
+	return evicted
+}
+
+// prune drops the charges that left the window. l.lock must be held.
+func (l *SlidingLog) prune(log *chargeLog, now time.Time) {
+	start := now.Add(-l.window)
+
+	i := 0
+	for i < len(log.charges) && !log.charges[i].at.After(start) {
+		log.total -= log.charges[i].cost
+		i++
+	}
+
+	if i > 0 {
+		log.charges = append(log.charges[:0], log.charges[i:]...)
+	}
+}
+
+func (l *SlidingLog) result(log *chargeLog, now time.Time, allowed bool) Result {
+	reset := now
+	if n := len(log.charges); n > 0 {
+		reset = log.charges[n-1].at.Add(l.window)
+	}
+
+	return Result{
+		Allowed:   allowed,
+		Limit:     l.limit,
+		Remaining: l.limit - log.total,
+		Reset:     reset.Unix(),
+	}
+}
+
+var _ Limiter = (*SlidingLog)(nil)
@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// TokenBucket refills the limit of each key evenly over the window, so bursts
+// up to the limit are allowed after a quiet period.
+type TokenBucket struct {
+	lock    sync.Mutex
+	limit   int
+	rate    float64 // tokens per second
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func NewTokenBucket(limit int, window time.Duration) *TokenBucket {
+	return &TokenBucket{
+		limit:   limit,
+		rate:    float64(limit) / window.Seconds(),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (l *TokenBucket) Allow(key string, cost int) Result {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	b := l.refill(key, now)
+
+	charge := float64(capCost(cost, l.limit))
+	allowed := b.tokens >= charge
+	if allowed {
+		if _, ok := l.buckets[key]; !ok {
+			l.buckets[key] = b
+		}
+		b.tokens -= charge
+	}
+
+	return l.result(b, now, allowed)
+}
+
+func (l *TokenBucket) Peek(key string) Result {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	return l.result(l.refill(key, now), now, true)
+}
+
+func (l *TokenBucket) Evict() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	evicted := 0
+	for key := range l.buckets {
+		if b := l.refill(key, now); b.tokens >= float64(l.limit) {
+			delete(l.buckets, key)
+			evicted++
+		}
+	}
+
+	return evicted
+}
+
+// refill returns the bucket of key at now, a full one that is not stored yet
+// for unknown keys. l.lock must be held.
+func (l *TokenBucket) refill(key string, now time.Time) *bucket {
+	b, ok := l.buckets[key]
+	if !ok {
+		return &bucket{tokens: float64(l.limit), last: now}
+	}
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(l.limit), b.tokens+elapsed*l.rate)
+		b.last = now
+	}
+
+	return b
+}
+
+func (l *TokenBucket) result(b *bucket, now time.Time, allowed bool) Result {
+	missing := float64(l.limit) - b.tokens
+	reset := now.Add(time.Duration(missing / l.rate * float64(time.Second)))
+
+	return Result{
+		Allowed:   allowed,
+		Limit:     l.limit,
+		Remaining: int(b.tokens),
+		Reset:     int64(math.Ceil(float64(reset.UnixNano()) / float64(time.Second))),
+	}
+}
+
+var _ Limiter = (*TokenBucket)(nil)
@@ -9,11 +9,11 @@ import (
 
 	"github.com/autoapev1/indexer/config"
 	"github.com/autoapev1/indexer/logger"
+	"github.com/autoapev1/indexer/metrics"
 	"github.com/autoapev1/indexer/types"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/dialect/pgdialect"
 	"github.com/uptrace/bun/driver/pgdriver"
-	"github.com/uptrace/bun/extra/bundebug"
 )
 
 type PostgresStore struct {
@@ -21,6 +21,9 @@ type PostgresStore struct {
 	ChainID int64
 	debug   bool
 	ready   bool
+
+	// parent of queries, see WithContext
+	ctx context.Context
 }
 
 func NewPostgresDB(conf config.PostgresConfig) *PostgresStore {
@@ -51,6 +54,15 @@ func (p *PostgresStore) Ready() bool {
 	return p.ready
 }
 
+func (p *PostgresStore) Ping() error {
+	defer metrics.ObserveQuery(p.ChainID, "Ping", time.Now())
+
+	ctx, cancel := context.WithTimeout(p.queryContext(), 5*time.Second)
+	defer cancel()
+
+	return p.DB.PingContext(ctx)
+}
+
 func (p *PostgresStore) Init() error {
 	st := time.Now()
 	err := p.CreateTables()
@@ -58,50 +70,149 @@ func (p *PostgresStore) Init() error {
 		return err
 	}
 
-	p.CreateIndexes()
-	if p.debug {
-		p.DB.AddQueryHook(bundebug.NewQueryHook(bundebug.WithVerbose(true)))
+	err = p.MigrateTables()
+	if err != nil {
+		return err
 	}
 
+	p.CreateIndexes()
+	p.DB.AddQueryHook(&queryHook{chainID: p.ChainID, verbose: p.debug})
+
 	logger.Time("Init()", time.Since(st), true)
 
 	return nil
 }
 
 func (p *PostgresStore) CreateTables() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(p.queryContext(), 15*time.Second)
 	defer cancel()
 
-	_, err := p.DB.NewCreateTable().
-		Model(&types.BlockTimestamp{}).
-		IfNotExists().
-		Exec(ctx)
-	if err != nil {
-		return err
+	for _, model := range types.Models {
+		_, err := p.DB.NewCreateTable().
+			Model(model).
+			IfNotExists().
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
 	}
 
-	_, err = p.DB.NewCreateTable().
-		IfNotExists().
-		Model(&types.Token{}).
-		Exec(ctx)
-	if err != nil {
-		return err
+	return nil
+}
+
+// MigrateTables adds columns introduced after a table was first created.
+func (p *PostgresStore) MigrateTables() error {
+	// serial columns fill every existing row, allow for large tables
+	ctx, cancel := context.WithTimeout(p.queryContext(), 30*time.Minute)
+	defer cancel()
+
+	pairColumns := []string{
+		"stable BOOLEAN NOT NULL DEFAULT false",
+		"dex VARCHAR(32) NOT NULL DEFAULT ''",
+		"reserve0 NUMERIC NOT NULL DEFAULT 0",
+		"reserve1 NUMERIC NOT NULL DEFAULT 0",
+		"reserves_block BIGINT NOT NULL DEFAULT 0",
+		"liquidity DOUBLE PRECISION NOT NULL DEFAULT 0",
+		"volume_24h DOUBLE PRECISION NOT NULL DEFAULT 0",
+		"seq BIGSERIAL",
 	}
 
-	_, err = p.DB.NewCreateTable().
-		Model(&types.Pair{}).
-		IfNotExists().
-		Exec(ctx)
-	if err != nil {
+	for _, column := range pairColumns {
+		_, err := p.DB.NewAddColumn().
+			Model(&types.Pair{}).
+			IfNotExists().
+			ColumnExpr(column).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := p.backfillDex(ctx); err != nil {
 		return err
 	}
 
+	tokenColumns := []string{
+		"seq BIGSERIAL",
+	}
+
+	for _, column := range tokenColumns {
+		_, err := p.DB.NewAddColumn().
+			Model(&types.Token{}).
+			IfNotExists().
+			ColumnExpr(column).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// backfillDex names the dex of pairs indexed before the dex column existed.
+// The row does not record its factory: stable pairs can only come from a
+// solidly factory, the others are named after the chain's v2/v3 factories,
+// or the solidly factory on chains without them. Volatile solidly pairs on
+// ethereum and bsc keep the v2 name.
+func (p *PostgresStore) backfillDex(ctx context.Context) error {
+	var dex string
+	switch p.ChainID {
+	case 1:
+		dex = types.DexUniswap
+	case 56:
+		dex = types.DexPancakeSwap
+	}
+
+	// same naming as the syncer, one solidly factory names its pairs, several
+	// fall back to the protocol
+	var solidly string
+	for _, c := range config.Get().Chains {
+		if int64(c.ChainID) != p.ChainID {
+			continue
+		}
+
+		for _, f := range c.Factories {
+			if !strings.EqualFold(f.Protocol, "solidly") || f.Address == "" {
+				continue
+			}
+
+			name := strings.ToLower(f.Name)
+			if name == "" {
+				name = "solidly"
+			}
+
+			if solidly != "" && solidly != name {
+				name = "solidly"
+			}
+			solidly = name
+		}
+	}
+
+	if dex == "" {
+		dex = solidly
+	}
+
+	if dex == "" {
+		return nil
+	}
+
+	if solidly == "" {
+		solidly = dex
+	}
+
+	_, err := p.DB.NewUpdate().
+		Model((*types.Pair)(nil)).
+		Set("dex = CASE WHEN stable THEN ? ELSE ? END", solidly, dex).
+		Where("dex = ''").
+		Exec(ctx)
+
+	return err
+}
+
 func (p *PostgresStore) CreateIndexes() {
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(p.queryContext(), 30*time.Second)
 	defer cancel()
 
 	_, _ = p.DB.NewCreateIndex().
@@ -111,6 +222,12 @@ func (p *PostgresStore) CreateIndexes() {
 		Index("block_timestamp_block_timestamp_idx").
 		Exec(ctx)
 
+	_, _ = p.DB.NewCreateIndex().
+		Model(&types.Block{}).
+		Column("hash").
+		Index("block_hash_idx").
+		Exec(ctx)
+
 	_, _ = p.DB.NewCreateIndex().
 		Model(&types.Token{}).
 		Column("address").
@@ -125,6 +242,66 @@ func (p *PostgresStore) CreateIndexes() {
 		Index("pair_addresses_idx").
 		Exec(ctx)
 
+	_, _ = p.DB.NewCreateIndex().
+		Model(&types.Pair{}).
+		Column("liquidity").
+		Index("pair_liquidity_idx").
+		Exec(ctx)
+
+	_, _ = p.DB.NewCreateIndex().
+		Model(&types.PairReserve{}).
+		Column("block").
+		Index("pair_reserves_block_idx").
+		Exec(ctx)
+
+	_, _ = p.DB.NewCreateIndex().
+		Model(&types.Pair{}).
+		Column("seq").
+		Index("pair_seq_idx").
+		Exec(ctx)
+
+	_, _ = p.DB.NewCreateIndex().
+		Model(&types.Token{}).
+		Column("seq").
+		Index("token_seq_idx").
+		Exec(ctx)
+
+	_, _ = p.DB.NewCreateIndex().
+		Model(&types.Webhook{}).
+		Column("owner").
+		Index("webhook_owner_idx").
+		Exec(ctx)
+
+	_, _ = p.DB.NewCreateIndex().
+		Model(&types.WebhookDelivery{}).
+		Column("webhook_id", "id").
+		Index("webhook_delivery_webhook_idx").
+		Exec(ctx)
+
+	_, _ = p.DB.NewCreateIndex().
+		Model(&types.WebhookDeadLetter{}).
+		Column("webhook_id", "id").
+		Index("webhook_dead_letter_webhook_idx").
+		Exec(ctx)
+
+	_, _ = p.DB.NewCreateIndex().
+		Model(&types.Log{}).
+		Column("address", "block_number").
+		Index("log_address_idx").
+		Exec(ctx)
+
+	_, _ = p.DB.NewCreateIndex().
+		Model(&types.Log{}).
+		Column("topic0", "block_number").
+		Index("log_topic0_idx").
+		Exec(ctx)
+
+	_, _ = p.DB.NewCreateIndex().
+		Model(&types.Log{}).
+		Column("block_hash").
+		Index("log_block_hash_idx").
+		Exec(ctx)
+
 }
 
 func (p *PostgresStore) GetChainID() int64 {
@@ -132,8 +309,10 @@ func (p *PostgresStore) GetChainID() int64 {
 }
 
 func (p *PostgresStore) GetBlockAtTimestamp(timestamp int64) (*types.BlockTimestamp, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetBlockAtTimestamp", time.Now())
+
 	blockTimestamp := new(types.BlockTimestamp)
-	ctx := context.Background()
+	ctx := p.queryContext()
 
 	const rangeOffset int64 = 20
 
@@ -151,7 +330,9 @@ func (p *PostgresStore) GetBlockAtTimestamp(timestamp int64) (*types.BlockTimest
 }
 
 func (p *PostgresStore) InsertBlockTimestamp(blockTimestamp *types.BlockTimestamp) error {
-	ctx := context.Background()
+	defer metrics.ObserveQuery(p.ChainID, "InsertBlockTimestamp", time.Now())
+
+	ctx := p.queryContext()
 	_, err := p.DB.NewInsert().Model(blockTimestamp).Exec(ctx)
 	if err != nil {
 		return err
@@ -161,7 +342,9 @@ func (p *PostgresStore) InsertBlockTimestamp(blockTimestamp *types.BlockTimestam
 }
 
 func (p *PostgresStore) BulkInsertBlockTimestamp(blockTimestamps []*types.BlockTimestamp) error {
-	ctx := context.Background()
+	defer metrics.ObserveQuery(p.ChainID, "BulkInsertBlockTimestamp", time.Now())
+
+	ctx := p.queryContext()
 	batchSize := 10000
 
 	for i := 0; i < len(blockTimestamps); i += batchSize {
@@ -171,12 +354,13 @@ func (p *PostgresStore) BulkInsertBlockTimestamp(blockTimestamps []*types.BlockT
 			end = len(blockTimestamps)
 		}
 
+		// live syncs read the last stored block again
 		batch := blockTimestamps[i:end]
-		_, err := p.DB.NewInsert().Model(&batch).Exec(ctx)
+		_, err := p.DB.NewInsert().
+			Model(&batch).
+			On("CONFLICT (block) DO NOTHING").
+			Exec(ctx)
 		if err != nil {
-			if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
-				continue
-			}
 			return err
 		}
 	}
@@ -184,8 +368,10 @@ func (p *PostgresStore) BulkInsertBlockTimestamp(blockTimestamps []*types.BlockT
 }
 
 func (p *PostgresStore) GetBlockTimestamps(to int64, from int64) ([]*types.BlockTimestamp, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetBlockTimestamps", time.Now())
+
 	var blockTimestamps []*types.BlockTimestamp
-	ctx := context.Background()
+	ctx := p.queryContext()
 
 	err := p.DB.NewSelect().
 		Model(&blockTimestamps).
@@ -200,9 +386,56 @@ func (p *PostgresStore) GetBlockTimestamps(to int64, from int64) ([]*types.Block
 	return blockTimestamps, nil
 }
 
+func (p *PostgresStore) BulkInsertBlocks(blocks []*types.Block) error {
+	defer metrics.ObserveQuery(p.ChainID, "BulkInsertBlocks", time.Now())
+
+	ctx := p.queryContext()
+	batchSize := 10000
+
+	for i := 0; i < len(blocks); i += batchSize {
+		end := i + batchSize
+		if end > len(blocks) {
+			end = len(blocks)
+		}
+
+		batch := blocks[i:end]
+		_, err := p.DB.NewInsert().
+			Model(&batch).
+			On("CONFLICT (number) DO NOTHING").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *PostgresStore) GetBlocks(to int64, from int64) ([]*types.Block, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetBlocks", time.Now())
+
+	var blocks []*types.Block
+	ctx, cancel := context.WithTimeout(p.queryContext(), 15*time.Second)
+	defer cancel()
+
+	err := p.DB.NewSelect().
+		Model(&blocks).
+		Where("number >= ?", from).
+		Where("number <= ?", to).
+		OrderExpr("number ASC").
+		Scan(ctx)
+	if err != nil {
+		return blocks, err
+	}
+
+	return blocks, nil
+}
+
 func (p *PostgresStore) GetHight() (int64, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetHight", time.Now())
+
 	var block int64
-	ctx := context.Background()
+	ctx := p.queryContext()
 	err := p.DB.NewSelect().
 		Table("block_timestamps").
 		ColumnExpr("MAX(block)").
@@ -215,7 +448,9 @@ func (p *PostgresStore) GetHight() (int64, error) {
 }
 
 func (p *PostgresStore) InsertTokenInfo(tokenInfo *types.Token) error {
-	ctx := context.Background()
+	defer metrics.ObserveQuery(p.ChainID, "InsertTokenInfo", time.Now())
+
+	ctx := p.queryContext()
 	_, err := p.DB.NewInsert().Model(tokenInfo).Exec(ctx)
 	if err != nil {
 		return err
@@ -224,7 +459,9 @@ func (p *PostgresStore) InsertTokenInfo(tokenInfo *types.Token) error {
 	return nil
 }
 func (p *PostgresStore) BulkInsertTokenInfo(tokenInfos []*types.Token) error {
-	ctx := context.Background()
+	defer metrics.ObserveQuery(p.ChainID, "BulkInsertTokenInfo", time.Now())
+
+	ctx := p.queryContext()
 	batchSize := 10000
 
 	for i := 0; i < len(tokenInfos); i += batchSize {
@@ -248,9 +485,33 @@ func (p *PostgresStore) BulkInsertTokenInfo(tokenInfos []*types.Token) error {
 	return nil
 }
 
+func (p *PostgresStore) GetTokensByAddress(addresses []string) ([]*types.Token, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetTokensByAddress", time.Now())
+
+	var tokens []*types.Token
+	if len(addresses) == 0 {
+		return tokens, nil
+	}
+
+	ctx, cancel := context.WithTimeout(p.queryContext(), 15*time.Second)
+	defer cancel()
+
+	err := p.DB.NewSelect().
+		Model(&tokens).
+		Where("address IN (?)", bun.In(addresses)).
+		Scan(ctx)
+	if err != nil {
+		return tokens, err
+	}
+
+	return tokens, nil
+}
+
 func (p *PostgresStore) GetTokenCount() (int64, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetTokenCount", time.Now())
+
 	var count int64
-	ctx := context.Background()
+	ctx := p.queryContext()
 	err := p.DB.NewSelect().ColumnExpr("COUNT(*)").Model(&types.Token{}).Scan(ctx, &count)
 	if err != nil {
 		return count, err
@@ -260,6 +521,8 @@ func (p *PostgresStore) GetTokenCount() (int64, error) {
 }
 
 func (p *PostgresStore) FindTokens(req *types.FindTokensRequest) ([]*types.Token, error) {
+	defer metrics.ObserveQuery(p.ChainID, "FindTokens", time.Now())
+
 	var tokens []*types.Token
 
 	query := p.DB.NewSelect().Model(&tokens)
@@ -310,7 +573,7 @@ func (p *PostgresStore) FindTokens(req *types.FindTokensRequest) ([]*types.Token
 	}
 
 	if req.Options.SortOrder != "" && req.Options.SortBy != "" {
-		query.OrderExpr(fmt.Sprintf("%s %s", req.Options.SortBy, req.Options.SortOrder))
+		keysetOrder(query, string(req.Options.SortBy), req.Options.SortOrder, "address", req.Options.After)
 	} else {
 		query.OrderExpr("created_at_block ASC")
 	}
@@ -327,7 +590,7 @@ func (p *PostgresStore) FindTokens(req *types.FindTokensRequest) ([]*types.Token
 		query.Offset(0)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(p.queryContext(), 15*time.Second)
 	defer cancel()
 
 	err := query.Scan(ctx)
@@ -339,7 +602,9 @@ func (p *PostgresStore) FindTokens(req *types.FindTokensRequest) ([]*types.Token
 }
 
 func (p *PostgresStore) InsertPairInfo(pairInfo *types.Pair) error {
-	ctx := context.Background()
+	defer metrics.ObserveQuery(p.ChainID, "InsertPairInfo", time.Now())
+
+	ctx := p.queryContext()
 	_, err := p.DB.NewInsert().Model(pairInfo).Exec(ctx)
 	if err != nil {
 		return err
@@ -349,7 +614,9 @@ func (p *PostgresStore) InsertPairInfo(pairInfo *types.Pair) error {
 }
 
 func (p *PostgresStore) BulkInsertPairInfo(pairInfos []*types.Pair) error {
-	ctx := context.Background()
+	defer metrics.ObserveQuery(p.ChainID, "BulkInsertPairInfo", time.Now())
+
+	ctx := p.queryContext()
 	batchSize := 100000
 
 	for i := 0; i < len(pairInfos); i += batchSize {
@@ -363,8 +630,12 @@ func (p *PostgresStore) BulkInsertPairInfo(pairInfos []*types.Pair) error {
 			end = len(pairInfos)
 		}
 
+		// live syncs read the last stored block again
 		batch := pairInfos[i:end]
-		_, err := p.DB.NewInsert().Model(&batch).Exec(ctx)
+		_, err := p.DB.NewInsert().
+			Model(&batch).
+			On("CONFLICT DO NOTHING").
+			Exec(ctx)
 		if err != nil {
 			return err
 		}
@@ -374,8 +645,10 @@ func (p *PostgresStore) BulkInsertPairInfo(pairInfos []*types.Pair) error {
 }
 
 func (p *PostgresStore) GetPairCount() (int64, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetPairCount", time.Now())
+
 	var count int64
-	ctx := context.Background()
+	ctx := p.queryContext()
 	err := p.DB.NewSelect().ColumnExpr("COUNT(*)").Model(&types.Pair{}).Scan(ctx, &count)
 	if err != nil {
 		return count, err
@@ -385,12 +658,17 @@ func (p *PostgresStore) GetPairCount() (int64, error) {
 }
 
 func (p *PostgresStore) FindPairs(req *types.FindPairsRequest) ([]*types.Pair, error) {
+	defer metrics.ObserveQuery(p.ChainID, "FindPairs", time.Now())
+
 	var pairs []*types.Pair
 
 	query := p.DB.NewSelect().Model(&pairs)
 	filter := req.Filter
 
 	if filter.Fuzzy {
+		if filter.TokenAddress != nil && *filter.TokenAddress != "" {
+			query.Where("(token0_address ILIKE ? OR token1_address ILIKE ?)", fuzWrap(filter.TokenAddress), fuzWrap(filter.TokenAddress))
+		}
 		if filter.Token0Address != nil && *filter.Token0Address != "" {
 			query.Where("token0_address ILIKE ?", fuzWrap(filter.Token0Address))
 		}
@@ -405,6 +683,9 @@ func (p *PostgresStore) FindPairs(req *types.FindPairsRequest) ([]*types.Pair, e
 		}
 
 	} else {
+		if filter.TokenAddress != nil {
+			query.Where("(token0_address = ? OR token1_address = ?)", filter.TokenAddress, filter.TokenAddress)
+		}
 		if filter.Token0Address != nil {
 			query.Where("token0_address = ?", filter.Token0Address)
 		}
@@ -439,8 +720,16 @@ func (p *PostgresStore) FindPairs(req *types.FindPairsRequest) ([]*types.Pair, e
 		query.Where("pool_type = ?", filter.PoolType)
 	}
 
+	if filter.Stable != nil {
+		query.Where("stable = ?", filter.Stable)
+	}
+
+	if filter.MinLiquidity != nil {
+		query.Where("liquidity >= ?", filter.MinLiquidity)
+	}
+
 	if req.Options.SortOrder != "" && req.Options.SortBy != "" {
-		query.OrderExpr(fmt.Sprintf("%s %s", req.Options.SortBy, req.Options.SortOrder))
+		keysetOrder(query, string(req.Options.SortBy), req.Options.SortOrder, "hash", req.Options.After)
 	} else {
 		query.OrderExpr("created_at_block ASC")
 	}
@@ -457,7 +746,7 @@ func (p *PostgresStore) FindPairs(req *types.FindPairsRequest) ([]*types.Pair, e
 		query.Offset(0)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(p.queryContext(), 15*time.Second)
 	defer cancel()
 
 	err := query.Scan(ctx)
@@ -468,96 +757,979 @@ func (p *PostgresStore) FindPairs(req *types.FindPairsRequest) ([]*types.Pair, e
 	return pairs, nil
 }
 
-func (p *PostgresStore) GetUniqueAddressesFromPairs() ([]string, error) {
-	// Query to get distinct addresses from both token0 and token1
-	var addresses []string
-	ctx := context.Background()
-	err := p.DB.NewSelect().
-		Table("pairs").
-		ColumnExpr("DISTINCT token0_address").Scan(ctx, &addresses)
-	if err != nil {
-		return addresses, err
+// keysetOrder orders query by the validated column sortBy and then by the
+// unique column id, so rows with the same sort value keep their order across
+// pages. With after set, only the rows after it are read.
+func keysetOrder(query *bun.SelectQuery, sortBy string, order types.SortOrder, id string, after *types.Keyset) {
+	dir, op := "ASC", ">"
+	if strings.EqualFold(string(order), string(types.SortDESC)) {
+		dir, op = "DESC", "<"
 	}
 
-	err = p.DB.NewSelect().
-		Table("pairs").
-		ColumnExpr("DISTINCT token1_address").Scan(ctx, &addresses)
-	if err != nil {
-		return addresses, err
+	if after != nil {
+		query.Where(fmt.Sprintf("(%s, %s) %s (?, ?)", sortBy, id, op), after.Value, after.ID)
 	}
 
-	// Remove duplicates
-	uniqueAddresses := make(map[string]bool)
-	for _, address := range addresses {
-		uniqueAddresses[address] = true
-	}
+	query.OrderExpr(fmt.Sprintf("%s %s, %s %s", sortBy, dir, id, dir))
+}
 
-	addresses = []string{}
-	for address := range uniqueAddresses {
-		addresses = append(addresses, address)
+func (p *PostgresStore) GetPairsByAddress(addresses []string) ([]*types.Pair, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetPairsByAddress", time.Now())
+
+	var pairs []*types.Pair
+	if len(addresses) == 0 {
+		return pairs, nil
 	}
 
-	return addresses, nil
-}
+	ctx, cancel := context.WithTimeout(p.queryContext(), 15*time.Second)
+	defer cancel()
 
-func (p *PostgresStore) GetUniqueAddressesFromTokens() ([]string, error) {
-	var addresses []string
-	ctx := context.Background()
 	err := p.DB.NewSelect().
-		Table("tokens").
-		ColumnExpr("DISTINCT address").Scan(ctx, &addresses)
+		Model(&pairs).
+		Where("pool_address IN (?)", bun.In(addresses)).
+		Scan(ctx)
 	if err != nil {
-		return addresses, err
+		return pairs, err
 	}
 
-	return addresses, nil
+	return pairs, nil
 }
 
-func (p *PostgresStore) GetPairsWithoutTokenInfo() ([]string, error) {
-	pairs, err := p.GetUniqueAddressesFromPairs()
-	if err != nil {
-		return nil, err
+// GetPairsBetween returns the pairs of token against any of others.
+func (p *PostgresStore) GetPairsBetween(token string, others []string) ([]*types.Pair, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetPairsBetween", time.Now())
+
+	var pairs []*types.Pair
+	if len(others) == 0 {
+		return pairs, nil
 	}
 
-	tokenAddresses, err := p.GetUniqueAddressesFromTokens()
+	ctx, cancel := context.WithTimeout(p.queryContext(), 15*time.Second)
+	defer cancel()
+
+	err := p.DB.NewSelect().
+		Model(&pairs).
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.
+				Where("token0_address = ? AND token1_address IN (?)", token, bun.In(others)).
+				WhereOr("token1_address = ? AND token0_address IN (?)", token, bun.In(others))
+		}).
+		Scan(ctx)
 	if err != nil {
-		return nil, err
+		return pairs, err
 	}
 
-	tokens := make(map[string]struct{})
-	for _, token := range tokenAddresses {
-		tokens[strings.ToLower(token)] = struct{}{}
+	return pairs, nil
+}
+
+// GetPairsForToken returns the pairs containing token on either side, deepest first.
+func (p *PostgresStore) GetPairsForToken(token string, minLiquidity float64, limit int) ([]*types.Pair, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetPairsForToken", time.Now())
+
+	var pairs []*types.Pair
+	token = strings.ToLower(token)
+
+	ctx, cancel := context.WithTimeout(p.queryContext(), 15*time.Second)
+	defer cancel()
+
+	query := p.DB.NewSelect().
+		Model(&pairs).
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.
+				Where("token0_address = ?", token).
+				WhereOr("token1_address = ?", token)
+		})
+
+	if minLiquidity > 0 {
+		query.Where("liquidity >= ?", minLiquidity)
 	}
 
-	var missing []string
-	for _, pair := range pairs {
-		if _, exists := tokens[strings.ToLower(pair)]; !exists {
-			missing = append(missing, pair)
-		}
+	err := query.
+		OrderExpr("liquidity DESC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return pairs, err
 	}
 
-	return missing, nil
+	return pairs, nil
 }
 
-func (p *PostgresStore) GetHeights() (*types.Heights, error) {
-	heights := &types.Heights{
-		Blocks: 0,
-		Tokens: 0,
-		Pairs:  0,
+// GetPairsSince returns the pairs created or with reserves updated after block,
+// ordered by (created_at, hash). Pages start after the last pair of the
+// previous page, an empty afterHash is the first page.
+func (p *PostgresStore) GetPairsSince(block int64, minLiquidity float64, afterCreatedAt int64, afterHash string, limit int) ([]*types.Pair, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetPairsSince", time.Now())
+
+	var pairs []*types.Pair
+
+	ctx, cancel := context.WithTimeout(p.queryContext(), 60*time.Second)
+	defer cancel()
+
+	query := p.DB.NewSelect().
+		Model(&pairs).
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.
+				Where("created_at > ?", block).
+				WhereOr("reserves_block > ?", block)
+		})
+
+	if minLiquidity > 0 {
+		query.Where("liquidity >= ?", minLiquidity)
 	}
 
-	ctx := context.Background()
+	// keyset rather than offset paging, the syncer moves rows in and out of
+	// the result by updating reserves_block while the pages are read
+	if afterHash != "" {
+		query.Where("(created_at, hash) > (?, ?)", afterCreatedAt, afterHash)
+	}
 
-	err := p.DB.NewSelect().
-		ColumnExpr("MAX(block)").
-		Model(&types.BlockTimestamp{}).
-		Scan(ctx, &heights.Blocks)
+	err := query.
+		OrderExpr("created_at ASC, hash ASC").
+		Limit(limit).
+		Scan(ctx)
 	if err != nil {
-		return heights, err
+		return pairs, err
 	}
 
-	err = p.DB.NewSelect().
-		ColumnExpr("MAX(created_at)").
+	return pairs, nil
+}
+
+// GetPairsAfterSeq returns the pairs ingested after seq, in ingestion order.
+func (p *PostgresStore) GetPairsAfterSeq(seq int64, limit int) ([]*types.Pair, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetPairsAfterSeq", time.Now())
+
+	var pairs []*types.Pair
+
+	ctx, cancel := context.WithTimeout(p.queryContext(), 15*time.Second)
+	defer cancel()
+
+	err := p.DB.NewSelect().
+		Model(&pairs).
+		Where("seq > ?", seq).
+		OrderExpr("seq ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return pairs, err
+	}
+
+	return pairs, nil
+}
+
+// GetTokensAfterSeq returns the tokens ingested after seq, in ingestion order.
+func (p *PostgresStore) GetTokensAfterSeq(seq int64, limit int) ([]*types.Token, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetTokensAfterSeq", time.Now())
+
+	var tokens []*types.Token
+
+	ctx, cancel := context.WithTimeout(p.queryContext(), 15*time.Second)
+	defer cancel()
+
+	err := p.DB.NewSelect().
+		Model(&tokens).
+		Where("seq > ?", seq).
+		OrderExpr("seq ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return tokens, err
+	}
+
+	return tokens, nil
+}
+
+// GetLatestSeqs returns the last ingestion sequence of pairs and tokens.
+func (p *PostgresStore) GetLatestSeqs() (int64, int64, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetLatestSeqs", time.Now())
+
+	var pairs, tokens int64
+
+	ctx, cancel := context.WithTimeout(p.queryContext(), 15*time.Second)
+	defer cancel()
+
+	err := p.DB.NewSelect().
+		Model(&types.Pair{}).
+		ColumnExpr("COALESCE(MAX(seq), 0)").
+		Scan(ctx, &pairs)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	err = p.DB.NewSelect().
+		Model(&types.Token{}).
+		ColumnExpr("COALESCE(MAX(seq), 0)").
+		Scan(ctx, &tokens)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return pairs, tokens, nil
+}
+
+func (p *PostgresStore) UpdatePairReserves(pairs []*types.Pair) error {
+	defer metrics.ObserveQuery(p.ChainID, "UpdatePairReserves", time.Now())
+
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(p.queryContext(), 30*time.Second)
+	defer cancel()
+
+	_, err := p.DB.NewUpdate().
+		Model(&pairs).
+		Column("reserve0", "reserve1", "reserves_block", "liquidity").
+		Bulk().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p *PostgresStore) BulkInsertPairReserves(reserves []*types.PairReserve) error {
+	defer metrics.ObserveQuery(p.ChainID, "BulkInsertPairReserves", time.Now())
+
+	ctx := p.queryContext()
+	batchSize := 10000
+
+	for i := 0; i < len(reserves); i++ {
+		reserves[i].Lower()
+	}
+
+	for i := 0; i < len(reserves); i += batchSize {
+		end := i + batchSize
+		if end > len(reserves) {
+			end = len(reserves)
+		}
+
+		batch := reserves[i:end]
+		_, err := p.DB.NewInsert().
+			Model(&batch).
+			On("CONFLICT (pool_address, block) DO UPDATE").
+			Set("reserve0 = EXCLUDED.reserve0").
+			Set("reserve1 = EXCLUDED.reserve1").
+			Set("liquidity = EXCLUDED.liquidity").
+			Set("volume_usd = EXCLUDED.volume_usd").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *PostgresStore) GetPairReserves(pool string, to int64, from int64) ([]*types.PairReserve, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetPairReserves", time.Now())
+
+	var reserves []*types.PairReserve
+
+	ctx, cancel := context.WithTimeout(p.queryContext(), 15*time.Second)
+	defer cancel()
+
+	err := p.DB.NewSelect().
+		Model(&reserves).
+		Where("pool_address = ?", strings.ToLower(pool)).
+		Where("block >= ?", from).
+		Where("block <= ?", to).
+		OrderExpr("block ASC").
+		Scan(ctx)
+	if err != nil {
+		return reserves, err
+	}
+
+	return reserves, nil
+}
+
+func (p *PostgresStore) BulkInsertLogs(logs []*types.Log) error {
+	defer metrics.ObserveQuery(p.ChainID, "BulkInsertLogs", time.Now())
+
+	ctx := p.queryContext()
+	batchSize := 5000
+
+	for i := 0; i < len(logs); i++ {
+		logs[i].Lower()
+	}
+
+	for i := 0; i < len(logs); i += batchSize {
+		end := i + batchSize
+		if end > len(logs) {
+			end = len(logs)
+		}
+
+		batch := logs[i:end]
+		_, err := p.DB.NewInsert().
+			Model(&batch).
+			On("CONFLICT (block_number, log_index) DO NOTHING").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetLogs returns up to limit logs matching an eth_getLogs style filter,
+// ordered by block and log index.
+func (p *PostgresStore) GetLogs(req *types.GetLogsRequest, limit int) ([]*types.Log, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetLogs", time.Now())
+
+	var logs []*types.Log
+
+	ctx, cancel := context.WithTimeout(p.queryContext(), 15*time.Second)
+	defer cancel()
+
+	q := p.DB.NewSelect().
+		Model(&logs)
+
+	if req.BlockHash != nil {
+		q.Where("block_hash = ?", *req.BlockHash)
+	} else {
+		q.Where("block_number >= ?", *req.FromBlock).
+			Where("block_number <= ?", *req.ToBlock)
+	}
+
+	if len(req.Address) > 0 {
+		q.Where("address IN (?)", bun.In([]string(req.Address)))
+	}
+
+	for i, topics := range req.Topics {
+		if len(topics) == 0 {
+			continue
+		}
+		q.Where("? IN (?)", bun.Ident(fmt.Sprintf("topic%d", i)), bun.In(topics))
+	}
+
+	err := q.
+		OrderExpr("block_number ASC, log_index ASC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return logs, err
+	}
+
+	for _, l := range logs {
+		l.SetTopics()
+	}
+
+	return logs, nil
+}
+
+// GetReservesStartBlock is the block the reserves stage resumes after when it
+// has no cursor yet, the latest reserves_block of a pair for databases synced
+// before the cursor existed. 0 means the reserves were never synced and the
+// pools are seeded from chain state.
+func (p *PostgresStore) GetReservesStartBlock() (int64, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetReservesStartBlock", time.Now())
+
+	var height int64
+	err := p.DB.NewSelect().
+		ColumnExpr("COALESCE(MAX(reserves_block), 0)").
+		Model(&types.Pair{}).
+		Scan(p.queryContext(), &height)
+
+	return height, err
+}
+
+func (p *PostgresStore) GetSyncHeight(name string) (int64, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetSyncHeight", time.Now())
+
+	var height int64
+
+	err := p.DB.NewSelect().
+		ColumnExpr("COALESCE(MAX(height), 0)").
+		Model(&types.SyncHeight{}).
+		Where("name = ?", name).
+		Scan(p.queryContext(), &height)
+	if err != nil {
+		return 0, err
+	}
+
+	return height, nil
+}
+
+func (p *PostgresStore) SetSyncHeight(name string, height int64) error {
+	defer metrics.ObserveQuery(p.ChainID, "SetSyncHeight", time.Now())
+
+	_, err := p.DB.NewInsert().
+		Model(&types.SyncHeight{Name: name, Height: height}).
+		On("CONFLICT (name) DO UPDATE").
+		Set("height = EXCLUDED.height").
+		Exec(p.queryContext())
+
+	return err
+}
+
+// CreateEventTable creates the table of a user defined event indexer. Column
+// types come from the events package, names are quoted.
+func (p *PostgresStore) CreateEventTable(table *types.EventTable) error {
+	defer metrics.ObserveQuery(p.ChainID, "CreateEventTable", time.Now())
+
+	ctx, cancel := context.WithTimeout(p.queryContext(), 15*time.Second)
+	defer cancel()
+
+	columns := []string{
+		"block_number BIGINT NOT NULL",
+		"log_index BIGINT NOT NULL",
+		"tx_hash VARCHAR(66) NOT NULL",
+		"contract_address VARCHAR(42) NOT NULL",
+	}
+	args := []interface{}{bun.Ident(table.Name)}
+
+	for _, c := range table.Columns {
+		columns = append(columns, "? "+c.Type)
+		args = append(args, bun.Ident(c.Name))
+	}
+	columns = append(columns, "PRIMARY KEY (block_number, log_index)")
+
+	_, err := p.DB.NewRaw("CREATE TABLE IF NOT EXISTS ? ("+strings.Join(columns, ", ")+")", args...).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.DB.NewRaw("CREATE INDEX IF NOT EXISTS ? ON ? (contract_address, block_number)",
+		bun.Ident(table.Name+"_contract_idx"), bun.Ident(table.Name)).
+		Exec(ctx)
+
+	return err
+}
+
+// BulkInsertEventRows inserts decoded events into their event table, rows
+// missing a column are stored with NULL.
+func (p *PostgresStore) BulkInsertEventRows(table *types.EventTable, rows []types.EventRow) error {
+	defer metrics.ObserveQuery(p.ChainID, "BulkInsertEventRows", time.Now())
+
+	ctx := p.queryContext()
+	batchSize := 1000
+
+	columns := []string{"block_number", "log_index", "tx_hash", "contract_address"}
+	for _, c := range table.Columns {
+		columns = append(columns, c.Name)
+	}
+
+	names := make([]interface{}, 0, len(columns))
+	for _, c := range columns {
+		names = append(names, bun.Ident(c))
+	}
+	placeholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+
+	for i := 0; i < len(rows); i += batchSize {
+		end := i + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		values := make([]string, 0, end-i)
+		args := append([]interface{}{bun.Ident(table.Name)}, names...)
+		for _, r := range rows[i:end] {
+			values = append(values, placeholders)
+			for _, c := range columns {
+				args = append(args, r[c])
+			}
+		}
+
+		query := "INSERT INTO ? " + placeholders + " VALUES " + strings.Join(values, ", ") +
+			" ON CONFLICT (block_number, log_index) DO NOTHING"
+
+		_, err := p.DB.NewRaw(query, args...).Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *PostgresStore) BulkInsertEventContracts(contracts []*types.EventContract) error {
+	defer metrics.ObserveQuery(p.ChainID, "BulkInsertEventContracts", time.Now())
+
+	if len(contracts) == 0 {
+		return nil
+	}
+
+	for _, c := range contracts {
+		c.Lower()
+	}
+
+	_, err := p.DB.NewInsert().
+		Model(&contracts).
+		On("CONFLICT (indexer, address) DO NOTHING").
+		Exec(p.queryContext())
+
+	return err
+}
+
+func (p *PostgresStore) GetEventContracts(indexer string) ([]string, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetEventContracts", time.Now())
+
+	var addresses []string
+
+	err := p.DB.NewSelect().
+		Model(&types.EventContract{}).
+		Column("address").
+		Where("indexer = ?", indexer).
+		Scan(p.queryContext(), &addresses)
+	if err != nil {
+		return addresses, err
+	}
+
+	return addresses, nil
+}
+
+// InsertWatchedWallets adds wallets to the watchlist, updating the label and
+// threshold of wallets already on it.
+func (p *PostgresStore) InsertWatchedWallets(wallets []*types.WatchedWallet) (int64, error) {
+	defer metrics.ObserveQuery(p.ChainID, "InsertWatchedWallets", time.Now())
+
+	if len(wallets) == 0 {
+		return 0, nil
+	}
+
+	for _, w := range wallets {
+		w.Lower()
+	}
+
+	res, err := p.DB.NewInsert().
+		Model(&wallets).
+		On("CONFLICT (address) DO UPDATE").
+		Set("label = EXCLUDED.label").
+		Set("min_transfer_usd = EXCLUDED.min_transfer_usd").
+		Exec(p.queryContext())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func (p *PostgresStore) DeleteWatchedWallets(addresses []string) (int64, error) {
+	defer metrics.ObserveQuery(p.ChainID, "DeleteWatchedWallets", time.Now())
+
+	if len(addresses) == 0 {
+		return 0, nil
+	}
+
+	res, err := p.DB.NewDelete().
+		Model(&types.WatchedWallet{}).
+		Where("address IN (?)", bun.In(addresses)).
+		Exec(p.queryContext())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func (p *PostgresStore) GetWatchedWallets() ([]*types.WatchedWallet, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetWatchedWallets", time.Now())
+
+	var wallets []*types.WatchedWallet
+
+	err := p.DB.NewSelect().
+		Model(&wallets).
+		OrderExpr("address ASC").
+		Scan(p.queryContext())
+	if err != nil {
+		return wallets, err
+	}
+
+	return wallets, nil
+}
+
+// GetLatestWalletBalances returns the last recorded balance of each address.
+func (p *PostgresStore) GetLatestWalletBalances(addresses []string) ([]*types.WalletBalance, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetLatestWalletBalances", time.Now())
+
+	var balances []*types.WalletBalance
+	if len(addresses) == 0 {
+		return balances, nil
+	}
+
+	ctx, cancel := context.WithTimeout(p.queryContext(), 15*time.Second)
+	defer cancel()
+
+	err := p.DB.NewSelect().
+		Model(&balances).
+		DistinctOn("address").
+		Where("address IN (?)", bun.In(addresses)).
+		OrderExpr("address ASC, block DESC").
+		Scan(ctx)
+	if err != nil {
+		return balances, err
+	}
+
+	return balances, nil
+}
+
+func (p *PostgresStore) BulkInsertWalletBalances(balances []*types.WalletBalance) error {
+	defer metrics.ObserveQuery(p.ChainID, "BulkInsertWalletBalances", time.Now())
+
+	ctx := p.queryContext()
+	batchSize := 10000
+
+	for i := 0; i < len(balances); i++ {
+		balances[i].Lower()
+	}
+
+	for i := 0; i < len(balances); i += batchSize {
+		end := i + batchSize
+		if end > len(balances) {
+			end = len(balances)
+		}
+
+		batch := balances[i:end]
+		_, err := p.DB.NewInsert().
+			Model(&batch).
+			On("CONFLICT (address, block) DO NOTHING").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *PostgresStore) BulkInsertWalletTransfers(transfers []*types.WalletTransfer) error {
+	defer metrics.ObserveQuery(p.ChainID, "BulkInsertWalletTransfers", time.Now())
+
+	ctx := p.queryContext()
+	batchSize := 10000
+
+	for i := 0; i < len(transfers); i++ {
+		transfers[i].Lower()
+	}
+
+	for i := 0; i < len(transfers); i += batchSize {
+		end := i + batchSize
+		if end > len(transfers) {
+			end = len(transfers)
+		}
+
+		batch := transfers[i:end]
+		_, err := p.DB.NewInsert().
+			Model(&batch).
+			On("CONFLICT (address, block, log_index) DO NOTHING").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *PostgresStore) GetWalletBalances(address string, to int64, from int64) ([]*types.WalletBalance, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetWalletBalances", time.Now())
+
+	var balances []*types.WalletBalance
+
+	ctx, cancel := context.WithTimeout(p.queryContext(), 15*time.Second)
+	defer cancel()
+
+	err := p.DB.NewSelect().
+		Model(&balances).
+		Where("address = ?", strings.ToLower(address)).
+		Where("block >= ?", from).
+		Where("block <= ?", to).
+		OrderExpr("block ASC").
+		Scan(ctx)
+	if err != nil {
+		return balances, err
+	}
+
+	return balances, nil
+}
+
+func (p *PostgresStore) GetWalletTransfers(address string, to int64, from int64) ([]*types.WalletTransfer, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetWalletTransfers", time.Now())
+
+	var transfers []*types.WalletTransfer
+
+	ctx, cancel := context.WithTimeout(p.queryContext(), 15*time.Second)
+	defer cancel()
+
+	err := p.DB.NewSelect().
+		Model(&transfers).
+		Where("address = ?", strings.ToLower(address)).
+		Where("block >= ?", from).
+		Where("block <= ?", to).
+		OrderExpr("block ASC, log_index ASC").
+		Scan(ctx)
+	if err != nil {
+		return transfers, err
+	}
+
+	return transfers, nil
+}
+
+func (p *PostgresStore) InsertWebhook(hook *types.Webhook) error {
+	defer metrics.ObserveQuery(p.ChainID, "InsertWebhook", time.Now())
+
+	_, err := p.DB.NewInsert().
+		Model(hook).
+		Returning("id").
+		Exec(p.queryContext())
+	return err
+}
+
+// DeleteWebhook removes a webhook of owner, it reports false if there is none.
+func (p *PostgresStore) DeleteWebhook(owner string, id int64) (bool, error) {
+	defer metrics.ObserveQuery(p.ChainID, "DeleteWebhook", time.Now())
+
+	res, err := p.DB.NewDelete().
+		Model(&types.Webhook{}).
+		Where("id = ?", id).
+		Where("owner = ?", owner).
+		Exec(p.queryContext())
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetWebhooks returns the webhooks of owner.
+func (p *PostgresStore) GetWebhooks(owner string) ([]*types.Webhook, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetWebhooks", time.Now())
+
+	var hooks []*types.Webhook
+
+	err := p.DB.NewSelect().
+		Model(&hooks).
+		Where("owner = ?", owner).
+		OrderExpr("id ASC").
+		Scan(p.queryContext())
+	if err != nil {
+		return hooks, err
+	}
+
+	return hooks, nil
+}
+
+// TransferWebhooks moves the webhooks of owner from to owner to, for rotated
+// api keys.
+func (p *PostgresStore) TransferWebhooks(from string, to string) (int64, error) {
+	defer metrics.ObserveQuery(p.ChainID, "TransferWebhooks", time.Now())
+
+	res, err := p.DB.NewUpdate().
+		Model((*types.Webhook)(nil)).
+		Set("owner = ?", to).
+		Where("owner = ?", from).
+		Exec(p.queryContext())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func (p *PostgresStore) GetAllWebhooks() ([]*types.Webhook, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetAllWebhooks", time.Now())
+
+	var hooks []*types.Webhook
+
+	err := p.DB.NewSelect().
+		Model(&hooks).
+		OrderExpr("id ASC").
+		Scan(p.queryContext())
+	if err != nil {
+		return hooks, err
+	}
+
+	return hooks, nil
+}
+
+func (p *PostgresStore) InsertWebhookDelivery(delivery *types.WebhookDelivery) error {
+	defer metrics.ObserveQuery(p.ChainID, "InsertWebhookDelivery", time.Now())
+
+	_, err := p.DB.NewInsert().
+		Model(delivery).
+		Exec(p.queryContext())
+	return err
+}
+
+func (p *PostgresStore) InsertWebhookDeadLetter(letter *types.WebhookDeadLetter) error {
+	defer metrics.ObserveQuery(p.ChainID, "InsertWebhookDeadLetter", time.Now())
+
+	_, err := p.DB.NewInsert().
+		Model(letter).
+		Exec(p.queryContext())
+	return err
+}
+
+// GetWebhookDeliveries returns the latest delivery attempts of a webhook, newest first.
+func (p *PostgresStore) GetWebhookDeliveries(id int64, limit int) ([]*types.WebhookDelivery, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetWebhookDeliveries", time.Now())
+
+	var deliveries []*types.WebhookDelivery
+
+	err := p.DB.NewSelect().
+		Model(&deliveries).
+		Where("webhook_id = ?", id).
+		OrderExpr("id DESC").
+		Limit(limit).
+		Scan(p.queryContext())
+	if err != nil {
+		return deliveries, err
+	}
+
+	return deliveries, nil
+}
+
+// GetWebhookDeadLetters returns the latest failed payloads of a webhook, newest first.
+func (p *PostgresStore) GetWebhookDeadLetters(id int64, limit int) ([]*types.WebhookDeadLetter, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetWebhookDeadLetters", time.Now())
+
+	var letters []*types.WebhookDeadLetter
+
+	err := p.DB.NewSelect().
+		Model(&letters).
+		Where("webhook_id = ?", id).
+		OrderExpr("id DESC").
+		Limit(limit).
+		Scan(p.queryContext())
+	if err != nil {
+		return letters, err
+	}
+
+	return letters, nil
+}
+
+// UpdateVolume24h sets volume_24h on every pair to the swap volume recorded since fromBlock.
+func (p *PostgresStore) UpdateVolume24h(fromBlock int64) error {
+	defer metrics.ObserveQuery(p.ChainID, "UpdateVolume24h", time.Now())
+
+	ctx, cancel := context.WithTimeout(p.queryContext(), 60*time.Second)
+	defer cancel()
+
+	_, err := p.DB.NewRaw(`
+		UPDATE pairs SET volume_24h = COALESCE(v.volume, 0)
+		FROM (
+			SELECT pairs.pool_address, SUM(pair_reserves.volume_usd) AS volume
+			FROM pairs
+			LEFT JOIN pair_reserves ON pair_reserves.pool_address = pairs.pool_address AND pair_reserves.block >= ?
+			WHERE pairs.volume_24h > 0 OR pair_reserves.block IS NOT NULL
+			GROUP BY pairs.pool_address
+		) AS v
+		WHERE pairs.pool_address = v.pool_address`, fromBlock).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p *PostgresStore) GetUniqueAddressesFromPairs() ([]string, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetUniqueAddressesFromPairs", time.Now())
+
+	// Query to get distinct addresses from both token0 and token1
+	var addresses []string
+	ctx := p.queryContext()
+	err := p.DB.NewSelect().
+		Table("pairs").
+		ColumnExpr("DISTINCT token0_address").Scan(ctx, &addresses)
+	if err != nil {
+		return addresses, err
+	}
+
+	err = p.DB.NewSelect().
+		Table("pairs").
+		ColumnExpr("DISTINCT token1_address").Scan(ctx, &addresses)
+	if err != nil {
+		return addresses, err
+	}
+
+	// Remove duplicates
+	uniqueAddresses := make(map[string]bool)
+	for _, address := range addresses {
+		uniqueAddresses[address] = true
+	}
+
+	addresses = []string{}
+	for address := range uniqueAddresses {
+		addresses = append(addresses, address)
+	}
+
+	return addresses, nil
+}
+
+func (p *PostgresStore) GetUniqueAddressesFromTokens() ([]string, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetUniqueAddressesFromTokens", time.Now())
+
+	var addresses []string
+	ctx := p.queryContext()
+	err := p.DB.NewSelect().
+		Table("tokens").
+		ColumnExpr("DISTINCT address").Scan(ctx, &addresses)
+	if err != nil {
+		return addresses, err
+	}
+
+	return addresses, nil
+}
+
+func (p *PostgresStore) GetPairsWithoutTokenInfo() ([]string, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetPairsWithoutTokenInfo", time.Now())
+
+	pairs, err := p.GetUniqueAddressesFromPairs()
+	if err != nil {
+		return nil, err
+	}
+
+	tokenAddresses, err := p.GetUniqueAddressesFromTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make(map[string]struct{})
+	for _, token := range tokenAddresses {
+		tokens[strings.ToLower(token)] = struct{}{}
+	}
+
+	var missing []string
+	for _, pair := range pairs {
+		if _, exists := tokens[strings.ToLower(pair)]; !exists {
+			missing = append(missing, pair)
+		}
+	}
+
+	return missing, nil
+}
+
+func (p *PostgresStore) GetHeights() (*types.Heights, error) {
+	defer metrics.ObserveQuery(p.ChainID, "GetHeights", time.Now())
+
+	heights := &types.Heights{
+		Blocks: 0,
+		Tokens: 0,
+		Pairs:  0,
+	}
+
+	ctx := p.queryContext()
+
+	err := p.DB.NewSelect().
+		ColumnExpr("MAX(block)").
+		Model(&types.BlockTimestamp{}).
+		Scan(ctx, &heights.Blocks)
+	if err != nil {
+		return heights, err
+	}
+
+	heights.Headers, err = p.GetSyncHeight(types.SyncHeightHeaders)
+	if err != nil {
+		return heights, err
+	}
+
+	err = p.DB.NewSelect().
+		ColumnExpr("MAX(created_at)").
 		Model(&types.Token{}).
 		Scan(ctx, &heights.Tokens)
 	if err != nil {
@@ -572,6 +1744,16 @@ func (p *PostgresStore) GetHeights() (*types.Heights, error) {
 		return heights, err
 	}
 
+	heights.Reserves, err = p.GetSyncHeight(types.SyncHeightReserves)
+	if err != nil {
+		return heights, err
+	}
+
+	heights.Logs, err = p.GetSyncHeight(types.SyncHeightLogs)
+	if err != nil {
+		return heights, err
+	}
+
 	return heights, nil
 }
 
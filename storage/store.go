@@ -1,10 +1,18 @@
 package storage
 
-import "github.com/autoapev1/indexer/types"
+import (
+	"context"
+
+	"github.com/autoapev1/indexer/types"
+)
 
 type Store interface {
 	Init() error
 	Ready() bool
+	// Ping checks the connection to the database.
+	Ping() error
+	// WithContext returns the store with its queries traced under ctx.
+	WithContext(ctx context.Context) Store
 	GetChainID() int64
 	GetHight() (int64, error)
 
@@ -14,17 +22,71 @@ type Store interface {
 	BulkInsertBlockTimestamp([]*types.BlockTimestamp) error
 	GetBlockTimestamps(to int64, from int64) ([]*types.BlockTimestamp, error)
 
+	// blocks
+	BulkInsertBlocks([]*types.Block) error
+	GetBlocks(to int64, from int64) ([]*types.Block, error)
+
 	// token info
 	FindTokens(*types.FindTokensRequest) ([]*types.Token, error)
 	GetTokenCount() (int64, error)
 	InsertTokenInfo(*types.Token) error
 	BulkInsertTokenInfo([]*types.Token) error
+	GetTokensByAddress([]string) ([]*types.Token, error)
 
 	// pair info
 	FindPairs(*types.FindPairsRequest) ([]*types.Pair, error)
 	GetPairCount() (int64, error)
 	InsertPairInfo(*types.Pair) error
 	BulkInsertPairInfo([]*types.Pair) error
+	GetPairsByAddress([]string) ([]*types.Pair, error)
+	GetPairsBetween(token string, others []string) ([]*types.Pair, error)
+	GetPairsForToken(token string, minLiquidity float64, limit int) ([]*types.Pair, error)
+	GetPairsSince(block int64, minLiquidity float64, afterCreatedAt int64, afterHash string, limit int) ([]*types.Pair, error)
+
+	// ingestion feed
+	GetPairsAfterSeq(seq int64, limit int) ([]*types.Pair, error)
+	GetTokensAfterSeq(seq int64, limit int) ([]*types.Token, error)
+	GetLatestSeqs() (pairs int64, tokens int64, err error)
+
+	// liquidity
+	UpdatePairReserves([]*types.Pair) error
+	BulkInsertPairReserves([]*types.PairReserve) error
+	GetPairReserves(pool string, to int64, from int64) ([]*types.PairReserve, error)
+	UpdateVolume24h(fromBlock int64) error
+	GetReservesStartBlock() (int64, error)
+
+	// raw logs
+	BulkInsertLogs([]*types.Log) error
+	GetLogs(req *types.GetLogsRequest, limit int) ([]*types.Log, error)
+	GetSyncHeight(name string) (int64, error)
+	SetSyncHeight(name string, height int64) error
+
+	// user defined events
+	CreateEventTable(*types.EventTable) error
+	BulkInsertEventRows(table *types.EventTable, rows []types.EventRow) error
+	BulkInsertEventContracts([]*types.EventContract) error
+	GetEventContracts(indexer string) ([]string, error)
+
+	// watched wallets
+	InsertWatchedWallets([]*types.WatchedWallet) (int64, error)
+	DeleteWatchedWallets(addresses []string) (int64, error)
+	GetWatchedWallets() ([]*types.WatchedWallet, error)
+	GetLatestWalletBalances(addresses []string) ([]*types.WalletBalance, error)
+	BulkInsertWalletBalances([]*types.WalletBalance) error
+	BulkInsertWalletTransfers([]*types.WalletTransfer) error
+	GetWalletBalances(address string, to int64, from int64) ([]*types.WalletBalance, error)
+	GetWalletTransfers(address string, to int64, from int64) ([]*types.WalletTransfer, error)
+
+	// webhooks
+	InsertWebhook(*types.Webhook) error
+	DeleteWebhook(owner string, id int64) (bool, error)
+	GetWebhooks(owner string) ([]*types.Webhook, error)
+	GetAllWebhooks() ([]*types.Webhook, error)
+	TransferWebhooks(from string, to string) (int64, error)
+	InsertWebhookDelivery(*types.WebhookDelivery) error
+	InsertWebhookDeadLetter(*types.WebhookDeadLetter) error
+	GetWebhookDeliveries(id int64, limit int) ([]*types.WebhookDelivery, error)
+	GetWebhookDeadLetters(id int64, limit int) ([]*types.WebhookDeadLetter, error)
 
 	// util
 	GetUniqueAddressesFromPairs() ([]string, error)
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/autoapev1/indexer/tracing"
+	"github.com/uptrace/bun"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxStatementLength truncates the statements recorded on spans, bulk inserts
+// can be megabytes long.
+const maxStatementLength = 4096
+
+// queryHook records a span for every query, and logs the queries of stores
+// created WithDebug.
+type queryHook struct {
+	chainID int64
+	verbose bool
+}
+
+var _ bun.QueryHook = (*queryHook)(nil)
+
+func (h *queryHook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	ctx, _ = tracing.Tracer().Start(ctx, "db."+event.Operation(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation", event.Operation()),
+			attribute.Int64("chain_id", h.chainID),
+		),
+	)
+
+	return ctx
+}
+
+func (h *queryHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	if h.verbose {
+		slog.Debug("query", "chainID", h.chainID, "duration", time.Since(event.StartTime), "query", event.Query, "err", event.Err)
+	}
+
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	defer span.End()
+
+	// the parameters are inlined, the sql is only recorded when enabled
+	if tracing.Statements() {
+		query := event.Query
+		if len(query) > maxStatementLength {
+			query = query[:maxStatementLength]
+		}
+		span.SetAttributes(attribute.String("db.statement", query))
+	}
+
+	if event.Result != nil {
+		if n, err := event.Result.RowsAffected(); err == nil {
+			span.SetAttributes(attribute.Int64("db.rows_affected", n))
+		}
+	}
+
+	if event.Err != nil && !errors.Is(event.Err, sql.ErrNoRows) {
+		span.RecordError(event.Err)
+		span.SetStatus(codes.Error, event.Err.Error())
+	}
+}
+
+// WithContext returns a copy of the store whose queries are traced under ctx.
+// Only the values of ctx are kept, the queries of the copy are not canceled
+// with it.
+func (p *PostgresStore) WithContext(ctx context.Context) Store {
+	c := *p
+	c.ctx = context.WithoutCancel(ctx)
+	return &c
+}
+
+// queryContext is the parent of the queries of the store.
+func (p *PostgresStore) queryContext() context.Context {
+	if p.ctx == nil {
+		return context.Background()
+	}
+	return p.ctx
+}
@@ -0,0 +1,66 @@
+package syncer
+
+import (
+	"context"
+
+	"github.com/autoapev1/indexer/types"
+)
+
+// blocksChunk is the most blocks fetched and stored at once.
+const blocksChunk = 10000
+
+// SyncBlocks stores the block timestamps from timestampsFrom and the block
+// headers from headersFrom, up to to (inclusive). The two tables keep their
+// own heights, the blocks both need are fetched once. The headers cursor is
+// advanced after every chunk.
+func (s *Syncer) SyncBlocks(ctx context.Context, timestampsFrom int64, headersFrom int64, to int64) error {
+	from := timestampsFrom
+	if headersFrom < from {
+		from = headersFrom
+	}
+
+	for start := from; start <= to; start += blocksChunk {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		end := start + blocksChunk - 1
+		if end > to {
+			end = to
+		}
+
+		blocks, err := s.network.GetBlocks(ctx, start, end)
+		if err != nil {
+			return err
+		}
+
+		headers := make([]*types.Block, 0, len(blocks))
+		bts := make([]*types.BlockTimestamp, 0, len(blocks))
+		for _, b := range blocks {
+			if b.Number >= headersFrom {
+				headers = append(headers, b)
+			}
+			if b.Number >= timestampsFrom {
+				bts = append(bts, b.BlockTimestamp())
+			}
+		}
+
+		if err := s.store.BulkInsertBlocks(headers); err != nil {
+			return err
+		}
+
+		if err := s.store.BulkInsertBlockTimestamp(bts); err != nil {
+			return err
+		}
+
+		if end >= headersFrom {
+			if err := s.store.SetSyncHeight(types.SyncHeightHeaders, end); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
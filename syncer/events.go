@@ -0,0 +1,154 @@
+package syncer
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/autoapev1/indexer/events"
+	"github.com/autoapev1/indexer/types"
+)
+
+// maxFilterAddresses is the most addresses passed to eth_getLogs, larger
+// contract sets are filtered by topic only and matched locally.
+const maxFilterAddresses = 500
+
+// initEvents loads the events indexers of the chain, creates their tables and
+// restores the contracts discovered by their factories.
+func (s *Syncer) initEvents() error {
+	if s.events != nil {
+		return nil
+	}
+
+	indexers := make([]*events.Indexer, 0)
+	for _, conf := range s.chainConfig().Events {
+		ix, err := events.Load(conf)
+		if err != nil {
+			return err
+		}
+
+		for _, table := range ix.Tables() {
+			if err := s.store.CreateEventTable(table); err != nil {
+				return err
+			}
+		}
+
+		contracts, err := s.store.GetEventContracts(ix.Name())
+		if err != nil {
+			return err
+		}
+		ix.AddAddresses(contracts)
+
+		indexers = append(indexers, ix)
+	}
+
+	s.events = indexers
+	return nil
+}
+
+func eventsSyncHeight(ix *events.Indexer) string {
+	return "events:" + ix.Name()
+}
+
+// SyncEvents indexes the events of ix between from and to (inclusive), in
+// chunks of sync.logs.blockRange blocks, advancing its sync height after
+// every chunk.
+func (s *Syncer) SyncEvents(ctx context.Context, ix *events.Indexer, from int64, to int64) error {
+	blockRange := int64(s.config.Sync.Logs.BlockRange)
+	if blockRange <= 0 || blockRange > 10000 {
+		blockRange = 1000
+	}
+
+	tables := make(map[string]*types.EventTable)
+	for _, t := range ix.Tables() {
+		tables[t.Name] = t
+	}
+
+	for start := from; start <= to; start += blockRange {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		end := start + blockRange - 1
+		if end > to {
+			end = to
+		}
+
+		if err := s.syncEventRange(ctx, ix, tables, start, end); err != nil {
+			return err
+		}
+
+		if err := s.store.SetSyncHeight(eventsSyncHeight(ix), end); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Syncer) syncEventRange(ctx context.Context, ix *events.Indexer, tables map[string]*types.EventTable, from int64, to int64) error {
+	// discover contracts first so events from a contract created in this
+	// range are not missed
+	if factory, topic, ok := ix.Factory(); ok {
+		logs, err := s.network.FilterLogs(ctx, from, to, []string{factory}, []string{topic})
+		if err != nil {
+			return err
+		}
+
+		contracts := make([]*types.EventContract, 0, len(logs))
+		addresses := make([]string, 0, len(logs))
+		for _, l := range logs {
+			c, ok := ix.DecodeFactory(l)
+			if !ok {
+				continue
+			}
+			contracts = append(contracts, c)
+			addresses = append(addresses, c.Address)
+		}
+
+		if err := s.store.BulkInsertEventContracts(contracts); err != nil {
+			return err
+		}
+		ix.AddAddresses(addresses)
+	}
+
+	addresses := ix.Addresses()
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	filter := addresses
+	if len(filter) > maxFilterAddresses {
+		filter = nil
+	}
+
+	logs, err := s.network.FilterLogs(ctx, from, to, filter, ix.Topics())
+	if err != nil {
+		return err
+	}
+
+	rows := make(map[string][]types.EventRow)
+	for _, l := range logs {
+		if filter == nil && !ix.Watches(l.Address.String()) {
+			continue
+		}
+
+		table, row, err := ix.Decode(l)
+		if err != nil {
+			slog.Warn("error decoding event", "indexer", ix.Name(), "tx", l.TxHash.String(), "error", err)
+			continue
+		}
+		rows[table] = append(rows[table], row)
+	}
+
+	for table, r := range rows {
+		if err := s.store.BulkInsertEventRows(tables[table], r); err != nil {
+			return err
+		}
+	}
+
+	slog.Debug("synced events", "indexer", ix.Name(), "from", from, "to", to, "logs", len(logs))
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package syncer
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/autoapev1/indexer/types"
+)
+
+// logsEnabled reports whether the raw logs store is configured for the chain.
+func (s *Syncer) logsEnabled() bool {
+	return s.chainConfig().Logs.Active()
+}
+
+// SyncLogs stores the allowlisted logs between from and to (inclusive), in
+// chunks of sync.logs.blockRange blocks, advancing the logs sync height after
+// every chunk.
+func (s *Syncer) SyncLogs(ctx context.Context, from int64, to int64) error {
+	c := s.chainConfig().Logs
+
+	blockRange := int64(s.config.Sync.Logs.BlockRange)
+	if blockRange <= 0 || blockRange > 10000 {
+		blockRange = 1000
+	}
+
+	for start := from; start <= to; start += blockRange {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		end := start + blockRange - 1
+		if end > to {
+			end = to
+		}
+
+		logs, err := s.network.GetLogs(ctx, start, end, c.Addresses, c.Topics)
+		if err != nil {
+			return err
+		}
+
+		if err := s.store.BulkInsertLogs(logs); err != nil {
+			return err
+		}
+
+		if err := s.store.SetSyncHeight(types.SyncHeightLogs, end); err != nil {
+			return err
+		}
+
+		slog.Debug("synced logs", "from", start, "to", end, "logs", len(logs))
+	}
+
+	return nil
+}
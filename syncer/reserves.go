@@ -0,0 +1,330 @@
+package syncer
+
+import (
+	"context"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/autoapev1/indexer/pricing"
+	"github.com/autoapev1/indexer/types"
+)
+
+// seedPageSize is the number of pairs seeded per page.
+const seedPageSize = 1000
+
+type reserveKey struct {
+	pool  string
+	block int64
+}
+
+type pairState struct {
+	pair     *types.Pair
+	reserve0 *big.Int
+	reserve1 *big.Int
+	block    int64
+}
+
+// newOracle builds the usd oracle for the syncer's chain, falling back to the
+// built in wrapped native and stablecoin addresses for ethereum and bsc.
+func (s *Syncer) newOracle() *pricing.Oracle {
+	var (
+		native  string
+		stables []string
+	)
+
+	switch s.network.Chain.ChainID {
+	case 1:
+		native = types.EthWrappedNativeAddress
+		stables = types.EthStablecoinAddresses
+	case 56:
+		native = types.BscWrappedNativeAddress
+		stables = types.BscStablecoinAddresses
+	}
+
+	c := s.chainConfig()
+	if c.WrappedNative != "" {
+		native = c.WrappedNative
+	}
+
+	if len(c.Stablecoins) > 0 {
+		stables = c.Stablecoins
+	}
+
+	return pricing.NewOracle(native, stables)
+}
+
+// SyncReserves applies the pool events between from and to (inclusive) to the
+// indexed pairs, in chunks of sync.reserves.blockRange blocks. The cursor is
+// advanced after every chunk, with or without events.
+func (s *Syncer) SyncReserves(ctx context.Context, from int64, to int64) error {
+	blockRange := int64(s.config.Sync.Reserves.BlockRange)
+	if blockRange <= 0 || blockRange > 2000 {
+		blockRange = 100
+	}
+
+	for start := from; start <= to; start += blockRange {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		end := start + blockRange - 1
+		if end > to {
+			end = to
+		}
+
+		if err := s.syncReserveRange(ctx, start, end); err != nil {
+			return err
+		}
+
+		if err := s.store.SetSyncHeight(types.SyncHeightReserves, end); err != nil {
+			return err
+		}
+	}
+
+	// volume_24h is recomputed once the reserves are up to date
+	bt, err := s.store.GetBlockAtTimestamp(time.Now().Add(-24 * time.Hour).Unix())
+	if err != nil {
+		slog.Warn("unable to find block 24h ago, skipping volume_24h", "error", err)
+		return nil
+	}
+
+	return s.store.UpdateVolume24h(bt.Block)
+}
+
+func (s *Syncer) syncReserveRange(ctx context.Context, from int64, to int64) error {
+	events, err := s.network.GetReserveEvents(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	pools := make(map[string]struct{})
+	for _, e := range events {
+		pools[e.PoolAddress] = struct{}{}
+	}
+
+	addresses := make([]string, 0, len(pools))
+	for a := range pools {
+		addresses = append(addresses, a)
+	}
+
+	pairs, err := s.store.GetPairsByAddress(addresses)
+	if err != nil {
+		return err
+	}
+
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	if err := s.refreshOracle(pairs); err != nil {
+		return err
+	}
+
+	// pools indexed before the range that were never seeded start from their
+	// chain state, pools created in the range start empty
+	unseeded := make([]*types.Pair, 0)
+	for _, p := range pairs {
+		if p.ReservesBlock == 0 && p.CreatedAt < from {
+			unseeded = append(unseeded, p)
+		}
+	}
+
+	if err := s.seedReserves(ctx, unseeded, from-1); err != nil {
+		return err
+	}
+
+	states := make(map[string]*pairState, len(pairs))
+	for _, p := range pairs {
+		states[p.PoolAddress] = &pairState{
+			pair:     p,
+			reserve0: pricing.ParseAmount(p.Reserve0),
+			reserve1: pricing.ParseAmount(p.Reserve1),
+		}
+	}
+
+	history := make(map[reserveKey]*types.PairReserve)
+	for _, e := range events {
+		st, ok := states[e.PoolAddress]
+		if !ok || e.Block <= st.pair.ReservesBlock {
+			continue
+		}
+
+		applyReserveEvent(st, e)
+		st.block = e.Block
+
+		k := reserveKey{pool: e.PoolAddress, block: e.Block}
+		h, ok := history[k]
+		if !ok {
+			h = &types.PairReserve{
+				PoolAddress: e.PoolAddress,
+				Block:       e.Block,
+			}
+			history[k] = h
+		}
+
+		h.Reserve0 = st.reserve0.String()
+		h.Reserve1 = st.reserve1.String()
+		h.Liquidity = s.oracle.Liquidity(st.pair, st.reserve0, st.reserve1)
+		if e.Kind == types.ReserveEventSwap {
+			h.VolumeUSD += s.oracle.Volume(st.pair, e.Volume0, e.Volume1)
+		}
+	}
+
+	updated := make([]*types.Pair, 0, len(states))
+	for _, st := range states {
+		if st.block == 0 {
+			continue
+		}
+
+		st.pair.Reserve0 = st.reserve0.String()
+		st.pair.Reserve1 = st.reserve1.String()
+		st.pair.ReservesBlock = st.block
+		st.pair.Liquidity = s.oracle.Liquidity(st.pair, st.reserve0, st.reserve1)
+		updated = append(updated, st.pair)
+	}
+
+	reserves := make([]*types.PairReserve, 0, len(history))
+	for _, h := range history {
+		reserves = append(reserves, h)
+	}
+
+	if err := s.store.BulkInsertPairReserves(reserves); err != nil {
+		return err
+	}
+
+	return s.store.UpdatePairReserves(updated)
+}
+
+// SeedReserves sets the reserves of every indexed pair from the pool state at
+// block, so the reserves stage starts at block rather than replaying the pool
+// events from the first pair.
+func (s *Syncer) SeedReserves(ctx context.Context, block int64) error {
+	var (
+		afterCreatedAt int64
+		afterHash      string
+	)
+
+	for {
+		pairs, err := s.store.GetPairsSince(-1, 0, afterCreatedAt, afterHash, seedPageSize)
+		if err != nil {
+			return err
+		}
+
+		if len(pairs) == 0 {
+			break
+		}
+
+		if err := s.refreshOracle(pairs); err != nil {
+			return err
+		}
+
+		if err := s.seedReserves(ctx, pairs, block); err != nil {
+			return err
+		}
+
+		last := pairs[len(pairs)-1]
+		afterCreatedAt, afterHash = last.CreatedAt, last.Hash
+
+		if len(pairs) < seedPageSize {
+			break
+		}
+	}
+
+	return s.store.SetSyncHeight(types.SyncHeightReserves, block)
+}
+
+// seedReserves reads the reserves of pairs at block and stores them. The
+// oracle must already know the tokens of the pairs.
+func (s *Syncer) seedReserves(ctx context.Context, pairs []*types.Pair, block int64) error {
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	reserves, err := s.network.GetPoolReserves(ctx, pairs, block)
+	if err != nil {
+		return err
+	}
+
+	for i, p := range pairs {
+		p.Reserve0 = reserves[i][0].String()
+		p.Reserve1 = reserves[i][1].String()
+		p.ReservesBlock = block
+		p.Liquidity = s.oracle.Liquidity(p, reserves[i][0], reserves[i][1])
+	}
+
+	return s.store.UpdatePairReserves(pairs)
+}
+
+// applyReserveEvent updates the reserves of a pair. v2 style pools report their
+// reserves in Sync, v3 pools are tracked from the Mint, Collect and Swap deltas.
+func applyReserveEvent(st *pairState, e *types.ReserveEvent) {
+	v3 := st.pair.PoolType == 3
+
+	switch e.Kind {
+	case types.ReserveEventSync:
+		if v3 {
+			return
+		}
+		st.reserve0 = new(big.Int).Set(e.Amount0)
+		st.reserve1 = new(big.Int).Set(e.Amount1)
+
+	case types.ReserveEventMint, types.ReserveEventCollect, types.ReserveEventSwap:
+		if !v3 || e.Amount0 == nil || e.Amount1 == nil {
+			return
+		}
+		st.reserve0 = clampZero(new(big.Int).Add(st.reserve0, e.Amount0))
+		st.reserve1 = clampZero(new(big.Int).Add(st.reserve1, e.Amount1))
+	}
+}
+
+// refreshOracle loads the decimals of every token in pairs and reprices the
+// native token from the stored reserves.
+func (s *Syncer) refreshOracle(pairs []*types.Pair) error {
+	stables := s.oracle.Stablecoins()
+
+	tokens := make(map[string]struct{})
+	tokens[s.oracle.Native()] = struct{}{}
+	for _, st := range stables {
+		tokens[st] = struct{}{}
+	}
+	for _, p := range pairs {
+		tokens[p.Token0Address] = struct{}{}
+		tokens[p.Token1Address] = struct{}{}
+	}
+
+	addresses := make([]string, 0, len(tokens))
+	for t := range tokens {
+		addresses = append(addresses, t)
+	}
+
+	infos, err := s.store.GetTokensByAddress(addresses)
+	if err != nil {
+		return err
+	}
+	s.oracle.SetDecimals(infos)
+
+	nativePairs, err := s.store.GetPairsBetween(s.oracle.Native(), stables)
+	if err != nil {
+		return err
+	}
+
+	if price := s.oracle.NativeUSDFromPairs(nativePairs); price > 0 {
+		s.oracle.SetNativeUSD(price)
+	}
+
+	return nil
+}
+
+func clampZero(v *big.Int) *big.Int {
+	if v.Sign() < 0 {
+		return v.SetInt64(0)
+	}
+	return v
+}
@@ -4,10 +4,16 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"math/big"
+	"time"
 
 	"github.com/autoapev1/indexer/config"
 	"github.com/autoapev1/indexer/eth"
+	"github.com/autoapev1/indexer/events"
+	"github.com/autoapev1/indexer/metrics"
+	"github.com/autoapev1/indexer/pricing"
 	"github.com/autoapev1/indexer/storage"
+	"github.com/autoapev1/indexer/types"
 )
 
 var (
@@ -19,7 +25,12 @@ type Syncer struct {
 	config  config.Config
 	network *eth.Network
 	store   storage.Store
+	oracle  *pricing.Oracle
+	events  []*events.Indexer
 	ctx     context.Context
+
+	// last known native balance of each watched wallet
+	walletBalances map[string]*big.Int
 }
 
 func NewSyncer(conf config.Config) *Syncer {
@@ -56,6 +67,10 @@ func (s *Syncer) Init() error {
 		s.ctx = context.Background()
 	}
 
+	if s.oracle == nil {
+		s.oracle = s.newOracle()
+	}
+
 	if !s.network.Ready() {
 		slog.Warn("network not ready, initializing")
 		err := s.network.Init()
@@ -72,9 +87,26 @@ func (s *Syncer) Init() error {
 		}
 	}
 
+	if err := s.initEvents(); err != nil {
+		slog.Error("failed to initialize event indexers", "error", err)
+		return err
+	}
+
 	return nil
 }
 
+// chainConfig returns the config of the syncer's chain, or an empty config if
+// the chain is not listed.
+func (s *Syncer) chainConfig() config.ChainConfig {
+	for _, c := range s.config.Chains {
+		if c.ChainID == s.network.Chain.ChainID {
+			return c
+		}
+	}
+
+	return config.ChainConfig{}
+}
+
 func (s *Syncer) Sync(ctx context.Context) error {
 	if err := s.Init(); err != nil {
 		return err
@@ -84,46 +116,92 @@ func (s *Syncer) Sync(ctx context.Context) error {
 		return err
 	}
 
-	select {
-	case <-ctx.Done():
-		return nil
-	default:
-	}
+	s.BlockOracle(ctx)
 
 	return nil
 }
 
+// ArchiveSync catches every stage up with the current chain height.
 func (s *Syncer) ArchiveSync(ctx context.Context) error {
-
-	chainHeight, err := s.network.Web3.Eth.GetBlockNumber()
+	chainHeight, err := s.network.GetBlockNumber(ctx)
 	if err != nil {
 		return err
 	}
 
+	return s.syncTo(ctx, chainHeight)
+}
+
+// LiveSync runs the sync stages up to block bn, new pairs and tokens become
+// visible to the api feed as they are inserted.
+func (s *Syncer) LiveSync(ctx context.Context, bn int64) {
+	if err := s.syncTo(ctx, bn); err != nil {
+		slog.Error("live sync failed", "block", bn, "error", err)
+	}
+}
+
+// BlockOracle polls the chain head every sync.pollInterval seconds and runs a
+// live sync for every new head until ctx is done.
+func (s *Syncer) BlockOracle(ctx context.Context) {
+	interval := time.Duration(s.config.Sync.PollInterval) * time.Second
+	if interval <= 0 {
+		interval = 3 * time.Second
+	}
+
+	var last int64
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		head, err := s.network.GetBlockNumber(ctx)
+		if err != nil {
+			slog.Error("failed to get block number", "error", err)
+			continue
+		}
+
+		if head <= last {
+			continue
+		}
+
+		s.LiveSync(ctx, head)
+		last = head
+	}
+}
+
+func (s *Syncer) syncTo(ctx context.Context, chainHeight int64) error {
+	defer s.recordHeights(chainHeight)
+
 	heights, err := s.store.GetHeights()
 	if err != nil {
 		slog.Error("failed to get db heights")
 		return err
 	}
 
-	if (int64(chainHeight) - heights.Blocks) > 0 {
-		slog.Info("chain height is higher than db blocktimestamp height, syncing block timestamps", "chainHeight", chainHeight, "dbHeight", heights.Blocks)
-		bts, err := s.network.GetBlockTimestamps(ctx, heights.Blocks, int64(chainHeight))
-		if err != nil {
-			slog.Error("failed to get block timestamps", "error", err)
-			return err
-		}
+	headersFrom := heights.Headers + 1
+	if heights.Headers == 0 {
+		headersFrom = 0
+	}
+	if start := s.chainConfig().StartBlock; headersFrom < start {
+		headersFrom = start
+	}
 
-		err = s.store.BulkInsertBlockTimestamp(bts)
+	if chainHeight-heights.Blocks > 0 || chainHeight >= headersFrom {
+		slog.Info("chain height is higher than db block heights, syncing blocks", "chainHeight", chainHeight, "timestampsHeight", heights.Blocks, "headersHeight", heights.Headers)
+		err = s.SyncBlocks(ctx, heights.Blocks, headersFrom, chainHeight)
 		if err != nil {
-			slog.Error("failed to ingest block timestamps", "error", err)
+			slog.Error("failed to sync blocks", "error", err)
 			return err
 		}
 	}
 
-	if (int64(chainHeight) - heights.Pairs) > 0 {
+	if (chainHeight - heights.Pairs) > 0 {
 		slog.Info("chain height is higher than db pair height, syncing pairs", "chainHeight", chainHeight, "dbHeight", heights.Pairs)
-		pairs, err := s.network.GetPairs(ctx, heights.Pairs, int64(chainHeight))
+		pairs, err := s.network.GetPairs(ctx, chainHeight, heights.Pairs)
 		if err != nil {
 			slog.Error("failed to get pairs", "error", err)
 			return err
@@ -153,7 +231,135 @@ func (s *Syncer) ArchiveSync(ctx context.Context) error {
 			return err
 		}
 	}
+
+	reservesHeight := heights.Reserves
+	if reservesHeight == 0 {
+		reservesHeight, err = s.store.GetReservesStartBlock()
+		if err != nil {
+			slog.Error("failed to get reserves start block", "error", err)
+			return err
+		}
+	}
+
+	// a fresh database reads the pools at head instead of replaying every
+	// pool event since the first pair
+	if reservesHeight == 0 {
+		slog.Info("no reserves height, seeding reserves from pool state", "chainHeight", chainHeight)
+		err = s.SeedReserves(ctx, chainHeight)
+		if err != nil {
+			slog.Error("failed to seed reserves", "error", err)
+			return err
+		}
+		reservesHeight = chainHeight
+	}
+
+	if (chainHeight - reservesHeight) > 0 {
+		slog.Info("chain height is higher than db reserves height, syncing reserves", "chainHeight", chainHeight, "dbHeight", reservesHeight)
+		err = s.SyncReserves(ctx, reservesHeight+1, chainHeight)
+		if err != nil {
+			slog.Error("failed to sync reserves", "error", err)
+			return err
+		}
+	}
+
+	if s.logsEnabled() {
+		from := heights.Logs + 1
+		if start := s.chainConfig().Logs.StartBlock; from < start {
+			from = start
+		}
+
+		if chainHeight >= from {
+			slog.Info("chain height is higher than db logs height, syncing logs", "chainHeight", chainHeight, "dbHeight", heights.Logs)
+			err = s.SyncLogs(ctx, from, chainHeight)
+			if err != nil {
+				slog.Error("failed to sync logs", "error", err)
+				return err
+			}
+		}
+	}
+
+	walletsHeight, err := s.store.GetSyncHeight(types.SyncHeightWallets)
+	if err != nil {
+		slog.Error("failed to get wallets height", "error", err)
+		return err
+	}
+
+	// the watchlist is tracked from the first sync onwards, not backfilled
+	if walletsHeight == 0 {
+		walletsHeight = chainHeight - 1
+	}
+
+	if (chainHeight - walletsHeight) > 0 {
+		slog.Info("chain height is higher than db wallets height, syncing watched wallets", "chainHeight", chainHeight, "dbHeight", walletsHeight)
+		err = s.SyncWallets(ctx, walletsHeight+1, chainHeight)
+		if err != nil {
+			slog.Error("failed to sync watched wallets", "error", err)
+			return err
+		}
+	}
+
+	for _, ix := range s.events {
+		height, err := s.store.GetSyncHeight(eventsSyncHeight(ix))
+		if err != nil {
+			slog.Error("failed to get events height", "indexer", ix.Name(), "error", err)
+			return err
+		}
+
+		from := height + 1
+		if from < ix.StartBlock() {
+			from = ix.StartBlock()
+		}
+
+		if chainHeight < from {
+			continue
+		}
+
+		slog.Info("chain height is higher than db events height, syncing events", "indexer", ix.Name(), "chainHeight", chainHeight, "dbHeight", height)
+		err = s.SyncEvents(ctx, ix, from, chainHeight)
+		if err != nil {
+			slog.Error("failed to sync events", "indexer", ix.Name(), "error", err)
+			return err
+		}
+	}
+
 	return nil
 }
-func (s *Syncer) LiveSync(ctx context.Context, bn int64) {}
-func (s *Syncer) BlockOracle(ctx context.Context)        {}
+
+// recordHeights publishes the height of every stage, and how far the blocks
+// are behind head.
+func (s *Syncer) recordHeights(head int64) {
+	heights, err := s.store.GetHeights()
+	if err != nil {
+		slog.Error("failed to get db heights", "error", err)
+		return
+	}
+
+	stages := map[string]int64{
+		"blocks":   heights.Blocks,
+		"headers":  heights.Headers,
+		"tokens":   heights.Tokens,
+		"pairs":    heights.Pairs,
+		"reserves": heights.Reserves,
+	}
+
+	if s.logsEnabled() {
+		stages["logs"] = heights.Logs
+	}
+
+	if h, err := s.store.GetSyncHeight(types.SyncHeightWallets); err == nil {
+		stages["wallets"] = h
+	}
+
+	for _, ix := range s.events {
+		if h, err := s.store.GetSyncHeight(eventsSyncHeight(ix)); err == nil {
+			stages[eventsSyncHeight(ix)] = h
+		}
+	}
+
+	chain := metrics.Chain(s.store.GetChainID())
+	for stage, h := range stages {
+		metrics.SyncHeight.WithLabelValues(chain, stage).Set(float64(h))
+	}
+
+	metrics.ChainHeadLag.WithLabelValues(chain).Set(float64(head - heights.Blocks))
+}
@@ -0,0 +1,306 @@
+package syncer
+
+import (
+	"context"
+	"log/slog"
+	"math/big"
+	"sort"
+
+	"github.com/autoapev1/indexer/eth"
+	"github.com/autoapev1/indexer/pricing"
+	"github.com/autoapev1/indexer/types"
+)
+
+// SyncWallets tracks the native balances and token transfers of the watched
+// wallets between from and to (inclusive), in chunks of sync.wallets.blockRange
+// blocks. The watchlist is reloaded for every chunk.
+func (s *Syncer) SyncWallets(ctx context.Context, from int64, to int64) error {
+	blockRange := int64(s.config.Sync.Wallets.BlockRange)
+	if blockRange <= 0 || blockRange > 1000 {
+		blockRange = 20
+	}
+
+	for start := from; start <= to; start += blockRange {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		end := start + blockRange - 1
+		if end > to {
+			end = to
+		}
+
+		wallets, err := s.store.GetWatchedWallets()
+		if err != nil {
+			return err
+		}
+
+		if len(wallets) > 0 {
+			if err := s.syncWalletRange(ctx, wallets, start, end); err != nil {
+				return err
+			}
+		}
+
+		if err := s.store.SetSyncHeight(types.SyncHeightWallets, end); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Syncer) syncWalletRange(ctx context.Context, wallets []*types.WatchedWallet, from int64, to int64) error {
+	watched := make(map[string]*types.WatchedWallet, len(wallets))
+	addresses := make([]string, 0, len(wallets))
+	for _, w := range wallets {
+		watched[w.Address] = w
+		addresses = append(addresses, w.Address)
+	}
+
+	balances, err := s.syncWalletBalances(ctx, watched, addresses, from, to)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.BulkInsertWalletBalances(balances); err != nil {
+		return err
+	}
+
+	transfers, err := s.syncWalletTransfers(ctx, watched, addresses, from, to)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.BulkInsertWalletTransfers(transfers); err != nil {
+		return err
+	}
+
+	slog.Debug("synced wallets", "from", from, "to", to, "wallets", len(wallets), "balances", len(balances), "transfers", len(transfers))
+
+	return nil
+}
+
+// syncWalletBalances reads the balance of the wallets that sent or received a
+// transaction in the range, after each block of such a transaction and at the
+// end of the range. Internal transfers have no transaction of the wallet, they
+// are folded into the next balance read, so they show up as a single delta at
+// the end of a range or at the wallet's next transaction. Only changed
+// balances are returned.
+func (s *Syncer) syncWalletBalances(ctx context.Context, watched map[string]*types.WatchedWallet, addresses []string, from int64, to int64) ([]*types.WalletBalance, error) {
+	if err := s.loadWalletBalances(addresses); err != nil {
+		return nil, err
+	}
+
+	txs, err := s.network.GetTransactions(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	touched := make(map[int64]map[string]struct{})
+	seen := make(map[string]struct{})
+	touch := func(block int64, address string) {
+		if _, ok := watched[address]; !ok {
+			return
+		}
+		if touched[block] == nil {
+			touched[block] = make(map[string]struct{})
+		}
+		touched[block][address] = struct{}{}
+		seen[address] = struct{}{}
+	}
+
+	for _, tx := range txs {
+		touch(tx.Block, tx.From)
+		touch(tx.Block, tx.To)
+	}
+
+	if len(seen) == 0 {
+		return nil, nil
+	}
+
+	for a := range seen {
+		touch(to, a)
+	}
+
+	blocks := make([]int64, 0, len(touched))
+	for b := range touched {
+		blocks = append(blocks, b)
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i] < blocks[j] })
+
+	queries := make([]eth.BalanceQuery, 0, len(seen))
+	for _, b := range blocks {
+		for a := range touched[b] {
+			queries = append(queries, eth.BalanceQuery{Address: a, Block: b})
+		}
+	}
+
+	results, err := s.network.GetBalances(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]*types.WalletBalance, 0)
+	for i, q := range queries {
+		balance := results[i]
+		prev, known := s.walletBalances[q.Address]
+		if known && prev.Cmp(balance) == 0 {
+			continue
+		}
+
+		delta := new(big.Int)
+		if known {
+			delta.Sub(balance, prev)
+		}
+
+		balances = append(balances, &types.WalletBalance{
+			Address: q.Address,
+			Block:   q.Block,
+			Balance: balance.String(),
+			Delta:   delta.String(),
+		})
+		s.walletBalances[q.Address] = balance
+	}
+
+	return balances, nil
+}
+
+// loadWalletBalances restores the last recorded balance of wallets not yet seen
+// by the syncer.
+func (s *Syncer) loadWalletBalances(addresses []string) error {
+	if s.walletBalances == nil {
+		s.walletBalances = make(map[string]*big.Int)
+	}
+
+	missing := make([]string, 0)
+	for _, a := range addresses {
+		if _, ok := s.walletBalances[a]; !ok {
+			missing = append(missing, a)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	latest, err := s.store.GetLatestWalletBalances(missing)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range latest {
+		s.walletBalances[b.Address] = pricing.ParseAmount(b.Balance)
+	}
+
+	return nil
+}
+
+// syncWalletTransfers returns the token transfers of watched wallets worth at
+// least their threshold. Transfers of tokens that can not be priced are only
+// kept when the threshold is 0.
+func (s *Syncer) syncWalletTransfers(ctx context.Context, watched map[string]*types.WatchedWallet, addresses []string, from int64, to int64) ([]*types.WalletTransfer, error) {
+	transfers, err := s.network.GetTransfers(ctx, from, to, addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(transfers) == 0 {
+		return nil, nil
+	}
+
+	tokens := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, t := range transfers {
+		if _, ok := seen[t.Token]; !ok {
+			seen[t.Token] = struct{}{}
+			tokens = append(tokens, t.Token)
+		}
+	}
+
+	prices, err := s.tokenPrices(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*types.WalletTransfer, 0)
+	add := func(t *types.TokenTransfer, address string, direction string, counterparty string) {
+		w, ok := watched[address]
+		if !ok {
+			return
+		}
+
+		threshold := w.MinTransferUSD
+		if threshold == 0 {
+			threshold = s.config.Sync.Wallets.MinTransferUSD
+		}
+
+		var value float64
+		price, priced := prices[t.Token]
+		if priced {
+			amount, _ := s.oracle.Amount(t.Token, t.Amount)
+			value = amount * price
+		}
+
+		if (priced && value < threshold) || (!priced && threshold > 0) {
+			return
+		}
+
+		result = append(result, &types.WalletTransfer{
+			Address:      address,
+			Block:        t.Block,
+			LogIndex:     t.LogIndex,
+			Direction:    direction,
+			Token:        t.Token,
+			Counterparty: counterparty,
+			Amount:       t.Amount.String(),
+			ValueUSD:     value,
+			TxHash:       t.TxHash,
+		})
+	}
+
+	for _, t := range transfers {
+		add(t, t.From, types.TransferOut, t.To)
+		add(t, t.To, types.TransferIn, t.From)
+	}
+
+	return result, nil
+}
+
+// tokenPrices returns the usd price of the tokens that can be priced, either
+// directly by the oracle or from their deepest pair.
+func (s *Syncer) tokenPrices(tokens []string) (map[string]float64, error) {
+	if err := s.refreshOracle(nil); err != nil {
+		return nil, err
+	}
+
+	infos, err := s.store.GetTokensByAddress(tokens)
+	if err != nil {
+		return nil, err
+	}
+	s.oracle.SetDecimals(infos)
+
+	prices := make(map[string]float64, len(tokens))
+	for _, token := range tokens {
+		if price, ok := s.oracle.PriceUSD(token); ok {
+			prices[token] = price
+			continue
+		}
+
+		pairs, err := s.store.GetPairsForToken(token, 0, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(pairs) == 0 {
+			continue
+		}
+
+		if price, ok := s.oracle.PriceFromPair(token, pairs[0]); ok {
+			prices[token] = price
+		}
+	}
+
+	return prices, nil
+}
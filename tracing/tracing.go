@@ -0,0 +1,130 @@
+// Package tracing sets up the opentelemetry tracer provider of the api, spans
+// are exported over OTLP or written to stdout or a file for local use.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+
+	"github.com/autoapev1/indexer/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+
+	defaultServiceName = "indexer"
+	instrumentation    = "github.com/autoapev1/indexer"
+)
+
+// statements is set when queries record their sql on spans.
+var statements atomic.Bool
+
+// Init installs the global tracer provider and the W3C trace context
+// propagator. Without an exporter spans are not recorded, but incoming trace
+// context is still propagated. The returned func flushes pending spans.
+func Init(ctx context.Context, conf config.TracingConfig) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	statements.Store(conf.Statements)
+
+	var closer io.Closer
+	var exporter sdktrace.SpanExporter
+	switch conf.Exporter {
+	case "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{}
+		if conf.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(conf.Endpoint))
+		}
+		if conf.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+
+		exp, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		exporter = exp
+	case ExporterStdout:
+		var w io.Writer = os.Stdout
+		if conf.File != "" {
+			f, err := os.OpenFile(conf.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				return nil, err
+			}
+			w = f
+			closer = f
+		}
+
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(w), stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, err
+		}
+		exporter = exp
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", conf.Exporter)
+	}
+
+	name := conf.ServiceName
+	if name == "" {
+		name = defaultServiceName
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(name),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	// a ratio of 0 is treated as unset, every trace is sampled
+	ratio := conf.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closer != nil {
+			closer.Close()
+		}
+		return err
+	}, nil
+}
+
+// Tracer returns the tracer of the indexer from the global provider, it is
+// looked up on each call so spans started before Init are not recorded.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
+
+// Statements reports whether query spans record their sql. Queries are sent
+// with their parameters inlined, so the sql holds key hashes, webhook secrets
+// and the values of every call.
+func Statements() bool {
+	return statements.Load()
+}
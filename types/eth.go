@@ -3,7 +3,7 @@ package types
 import (
 	"log"
 	"os"
-	"strings"
+	"path/filepath"
 )
 
 type Chain struct {
@@ -19,6 +19,12 @@ type Chain struct {
 	Http          string `json:"-"`
 }
 
+// dex names stored on pairs
+const (
+	DexUniswap     string = "uniswap"
+	DexPancakeSwap string = "pancakeswap"
+)
+
 const (
 	// eth
 	EthV2RouterAddress     string = "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D"
@@ -35,6 +41,24 @@ const (
 	BscV3FactoryAddress    string = "0x1F98431c8aD98523631AE4a59f267346ea31F984"
 	BscV2TokenCheckAddress string = "0xd439e0e20f22a4a482ccd93e45af35b0e46faaf2"
 	BscV3TokenCheckAddress string = "0xff81b9848845ee11672bb476e3dfab4c379a771e"
+
+	// wrapped native tokens
+	EthWrappedNativeAddress string = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+	BscWrappedNativeAddress string = "0xbb4cdb9cbd36b01bd1cbaebf2de08d9173bc095c"
+)
+
+var (
+	// usd stablecoins used to price liquidity
+	EthStablecoinAddresses = []string{
+		"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", // usdc
+		"0xdac17f958d2ee523a2206206994597c13d831ec7", // usdt
+		"0x6b175474e89094c44da98b954eedeac495271d0f", // dai
+	}
+	BscStablecoinAddresses = []string{
+		"0xe9e7cea3dedca5984780bafc599bd69add087d56", // busd
+		"0x55d398326f99059ff775485246999027b3197955", // usdt
+		"0x8ac76a51cc950d9822d68b83fe1ad97b32cd580d", // usdc
+	}
 )
 
 var (
@@ -53,6 +77,9 @@ var (
 	BscV3PoolABI    string = readFileToString("./eth/abi/BSC_V3_Pool_ABI.json")
 	Erc20ABI        string = readFileToString("./eth/abi/ERC20_ABI.json")
 	TokenCheckV2ABI string = readFileToString("./eth/abi/TokenCheck_V2_ABI.json")
+
+	// solidly / velodrome style factories
+	SolidlyFactoryABI string = readFileToString("./eth/abi/Solidly_Factory_ABI.json")
 )
 
 func readFileToString(path string) string {
@@ -64,15 +91,17 @@ func readFileToString(path string) string {
 
 	}
 
-	if strings.Contains(cwd, "eth") {
-		path = "../" + path
-	}
+	// walk up from the working directory so packages below the module root
+	// (tests, tools) can load the abis too
+	for dir := cwd; ; dir = filepath.Dir(dir) {
+		data, err := os.ReadFile(filepath.Join(dir, path))
+		if err == nil {
+			return string(data)
+		}
 
-	data, err := os.ReadFile(path)
-	if err != nil {
-		log.Fatalf("Error reading file: %v", err)
-		return ""
+		if filepath.Dir(dir) == dir {
+			log.Fatalf("Error reading file: %v", err)
+			return ""
+		}
 	}
-
-	return string(data)
 }
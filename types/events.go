@@ -0,0 +1,34 @@
+package types
+
+import (
+	"strings"
+
+	"github.com/uptrace/bun"
+)
+
+// EventTable describes a table created for a user defined event indexer.
+type EventTable struct {
+	Name    string
+	Event   string
+	Columns []EventColumn // event inputs, after the fixed log columns
+}
+
+type EventColumn struct {
+	Name string
+	Type string // postgres type
+}
+
+// EventRow is one decoded event, keyed by column name.
+type EventRow map[string]interface{}
+
+// EventContract is a contract discovered by the factory of an events indexer.
+type EventContract struct {
+	bun.BaseModel `bun:"table:event_contracts,alias:event_contracts" json:"-"`
+	Indexer       string `json:"indexer" bun:",pk,type:varchar(63)"`
+	Address       string `json:"address" bun:",pk,type:varchar(42)"`
+	CreatedAt     int64  `json:"created_at" bun:",notnull,default:0"`
+}
+
+func (c *EventContract) Lower() {
+	c.Address = strings.ToLower(c.Address)
+}
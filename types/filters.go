@@ -1,5 +1,7 @@
 package types
 
+import "strings"
+
 type TokenFilter struct {
 	Address   *string `json:"address,omitempty"`
 	Creator   *string `json:"creator,omitempty"`
@@ -12,14 +14,132 @@ type TokenFilter struct {
 }
 
 type PairFilter struct {
-	Token0Address *string `json:"token0_address,omitempty"`
-	Token1Address *string `json:"token1_address,omitempty"`
-	PoolAddress   *string `json:"pool_address,omitempty"`
-	FromBlock     *int64  `json:"from_block,omitempty"`
-	ToBlock       *int64  `json:"to_block,omitempty"`
-	Fee           *int64  `json:"fee,omitempty"`
-	TickSpacing   *int64  `json:"tick_spacing,omitempty"`
-	Hash          *string `json:"hash,omitempty"`
-	PoolType      *uint8  `json:"pool_type,omitempty"`
-	Fuzzy         bool    `json:"fuzzy"`
+	TokenAddress  *string  `json:"token_address,omitempty"` // token0 or token1
+	Token0Address *string  `json:"token0_address,omitempty"`
+	Token1Address *string  `json:"token1_address,omitempty"`
+	PoolAddress   *string  `json:"pool_address,omitempty"`
+	FromBlock     *int64   `json:"from_block,omitempty"`
+	ToBlock       *int64   `json:"to_block,omitempty"`
+	Fee           *int64   `json:"fee,omitempty"`
+	TickSpacing   *int64   `json:"tick_spacing,omitempty"`
+	Hash          *string  `json:"hash,omitempty"`
+	PoolType      *uint8   `json:"pool_type,omitempty"`
+	Stable        *bool    `json:"stable,omitempty"`
+	MinLiquidity  *float64 `json:"min_liquidity_usd,omitempty"`
+	Fuzzy         bool     `json:"fuzzy"`
+}
+
+// Lower lowercases the address filters to match the stored rows.
+func (f *TokenFilter) Lower() {
+	lowerPtr(f.Address)
+	lowerPtr(f.Creator)
+}
+
+// Match reports whether t passes the filter, with the same semantics as
+// idx_findTokens. A nil filter matches every token.
+func (f *TokenFilter) Match(t *Token) bool {
+	if f == nil {
+		return true
+	}
+
+	if !matchString(f.Address, t.Address, f.Fuzzy) ||
+		!matchString(f.Creator, t.Creator, f.Fuzzy) ||
+		!matchString(f.Name, t.Name, f.Fuzzy) ||
+		!matchString(f.Symbol, t.Symbol, f.Fuzzy) {
+		return false
+	}
+
+	if f.Decimals != nil && *f.Decimals != t.Decimals {
+		return false
+	}
+
+	return matchBlock(f.FromBlock, f.ToBlock, t.CreatedAt)
+}
+
+// Lower lowercases the address and hash filters to match the stored rows.
+func (f *PairFilter) Lower() {
+	lowerPtr(f.TokenAddress)
+	lowerPtr(f.Token0Address)
+	lowerPtr(f.Token1Address)
+	lowerPtr(f.PoolAddress)
+	lowerPtr(f.Hash)
+}
+
+// Match reports whether p passes the filter, with the same semantics as
+// idx_findPairs. A nil filter matches every pair.
+func (f *PairFilter) Match(p *Pair) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.TokenAddress != nil && !matchString(f.TokenAddress, p.Token0Address, f.Fuzzy) &&
+		!matchString(f.TokenAddress, p.Token1Address, f.Fuzzy) {
+		return false
+	}
+
+	if !matchString(f.Token0Address, p.Token0Address, f.Fuzzy) ||
+		!matchString(f.Token1Address, p.Token1Address, f.Fuzzy) ||
+		!matchString(f.PoolAddress, p.PoolAddress, f.Fuzzy) ||
+		!matchString(f.Hash, p.Hash, f.Fuzzy) {
+		return false
+	}
+
+	if f.Fee != nil && *f.Fee != p.Fee {
+		return false
+	}
+
+	if f.TickSpacing != nil && *f.TickSpacing != p.TickSpacing {
+		return false
+	}
+
+	if f.PoolType != nil && *f.PoolType != p.PoolType {
+		return false
+	}
+
+	if f.Stable != nil && *f.Stable != p.Stable {
+		return false
+	}
+
+	if f.MinLiquidity != nil && p.Liquidity < *f.MinLiquidity {
+		return false
+	}
+
+	return matchBlock(f.FromBlock, f.ToBlock, p.CreatedAt)
+}
+
+// Match reports whether p is part of the requested stream.
+func (r *PairEventsRequest) Match(p *Pair) bool {
+	if r.Token != nil && p.Token0Address != *r.Token && p.Token1Address != *r.Token {
+		return false
+	}
+
+	return r.PoolType == nil || p.PoolType == *r.PoolType
+}
+
+// matchString is an exact match, or a case insensitive substring match when
+// fuzzy. Empty fuzzy filters match anything, like the sql filters.
+func matchString(want *string, got string, fuzzy bool) bool {
+	if want == nil {
+		return true
+	}
+
+	if fuzzy {
+		return *want == "" || strings.Contains(strings.ToLower(got), strings.ToLower(*want))
+	}
+
+	return got == *want
+}
+
+func matchBlock(from *int64, to *int64, block int64) bool {
+	if from != nil && block < *from {
+		return false
+	}
+
+	return to == nil || block <= *to
+}
+
+func lowerPtr(s *string) {
+	if s != nil {
+		*s = strings.ToLower(*s)
+	}
 }
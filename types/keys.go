@@ -0,0 +1,161 @@
+package types
+
+import (
+	"errors"
+	"net"
+	"path"
+	"strings"
+)
+
+// KeyStats is an api key and its usage.
+type KeyStats struct {
+	Key         string           `json:"key"`
+	Iat         int64            `json:"iat"`
+	Exp         int64            `json:"exp"` // 0 never expires
+	Revoked     bool             `json:"revoked"`
+	Owner       string           `json:"owner"`
+	Notes       string           `json:"notes"`
+	Scopes      *KeyScopes       `json:"scopes,omitempty"` // nil is unrestricted
+	Tier        string           `json:"tier"`             // empty for the default tier
+	LastIP      string           `json:"last_ip"`
+	LastAccess  int64            `json:"last_access"`
+	CallCount   int64            `json:"call_count"`
+	RowCount    int64            `json:"row_count"` // results returned
+	MethodUsage map[string]int64 `json:"method_usage"`
+	Daily       []*KeyDailyUsage `json:"daily,omitempty"`
+}
+
+// KeyDailyUsage is the usage of a key on a day, in UTC.
+type KeyDailyUsage struct {
+	Day         string           `json:"day"` // 2006-01-02
+	Requests    int64            `json:"requests"`
+	Rows        int64            `json:"rows"`
+	MethodUsage map[string]int64 `json:"method_usage"`
+}
+
+// KeyScopes restrict what a key can call and from where, an empty list does
+// not restrict. Methods and origins may use path.Match wildcards like idx_*.
+type KeyScopes struct {
+	Methods []string `json:"methods,omitempty"`
+	Chains  []int64  `json:"chains,omitempty"`
+	Origins []string `json:"origins,omitempty"` // exact Origin headers like https://app.example.com
+	CIDRs   []string `json:"cidrs,omitempty"`   // ip ranges, or single ips
+}
+
+func (s *KeyScopes) Validate() error {
+	if s == nil {
+		return nil
+	}
+
+	for _, m := range s.Methods {
+		if _, err := path.Match(m, ""); err != nil || m == "" {
+			return errors.New("invalid parameter: scopes.methods - " + m)
+		}
+	}
+
+	for _, o := range s.Origins {
+		if _, err := path.Match(o, ""); err != nil || o == "" {
+			return errors.New("invalid parameter: scopes.origins - " + o)
+		}
+	}
+
+	for _, c := range s.CIDRs {
+		if parseCIDR(c) == nil {
+			return errors.New("invalid parameter: scopes.cidrs - " + c)
+		}
+	}
+
+	return nil
+}
+
+// Restricts reports whether a scope limits the methods or chains of a key,
+// the access checks of endpoints that are not methods.
+func (s *KeyScopes) Restricts() bool {
+	return s != nil && (len(s.Methods) > 0 || len(s.Chains) > 0)
+}
+
+func (s *KeyScopes) AllowsMethod(method string) bool {
+	if s == nil || len(s.Methods) == 0 {
+		return true
+	}
+
+	for _, m := range s.Methods {
+		if ok, _ := path.Match(m, method); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *KeyScopes) AllowsChain(chainID int64) bool {
+	if s == nil || len(s.Chains) == 0 {
+		return true
+	}
+
+	for _, id := range s.Chains {
+		if id == chainID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowsOrigin requires an Origin header once origins are set. It is
+// advisory: it keeps browsers on other sites from using a key, but any other
+// client can send the Origin header, and gRPC callers the origin metadata, of
+// an allowed origin. Use cidrs to restrict where a key is used from.
+func (s *KeyScopes) AllowsOrigin(origin string) bool {
+	if s == nil || len(s.Origins) == 0 {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	for _, o := range s.Origins {
+		if ok, _ := path.Match(strings.ToLower(o), origin); ok && origin != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *KeyScopes) AllowsIP(ip string) bool {
+	if s == nil || len(s.CIDRs) == 0 {
+		return true
+	}
+
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+
+	for _, c := range s.CIDRs {
+		if n := parseCIDR(c); n != nil && n.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseCIDR also takes a single ip, nil if s is neither.
+func parseCIDR(s string) *net.IPNet {
+	if _, n, err := net.ParseCIDR(s); err == nil {
+		return n
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil
+	}
+
+	bits := 128
+	if ip.To4() != nil {
+		ip = ip.To4()
+		bits = 32
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+}
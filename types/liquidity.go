@@ -0,0 +1,47 @@
+package types
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/uptrace/bun"
+)
+
+// PairReserve is a snapshot of a pair's reserves at the end of a block.
+type PairReserve struct {
+	bun.BaseModel `bun:"table:pair_reserves,alias:pair_reserves" json:"-"`
+	PoolAddress   string  `json:"pool_address" bun:",pk,type:varchar(42)"`
+	Block         int64   `json:"block" bun:",pk"`
+	Reserve0      string  `json:"reserve0" bun:",type:numeric,nullzero,notnull,default:0"`
+	Reserve1      string  `json:"reserve1" bun:",type:numeric,nullzero,notnull,default:0"`
+	Liquidity     float64 `json:"liquidity" bun:",notnull,default:0"`
+	VolumeUSD     float64 `json:"volume_usd" bun:"volume_usd,notnull,default:0"`
+}
+
+func (p *PairReserve) Lower() {
+	p.PoolAddress = strings.ToLower(p.PoolAddress)
+}
+
+type ReserveEventKind uint8
+
+const (
+	ReserveEventSync    ReserveEventKind = iota // v2 / solidly, absolute reserves
+	ReserveEventMint                            // v3, reserves delta
+	ReserveEventCollect                         // v3, reserves delta, burned liquidity and fees leaving the pool
+	ReserveEventSwap                            // v2 / v3, volume (and reserves delta for v3)
+)
+
+// SyncHeightReserves is the name of the reserves cursor in sync_heights.
+const SyncHeightReserves = "reserves"
+
+// ReserveEvent is a decoded pool event that changes a pair's reserves or volume.
+type ReserveEvent struct {
+	PoolAddress string
+	Block       int64
+	LogIndex    uint
+	Kind        ReserveEventKind
+	Amount0     *big.Int // absolute reserve for sync, signed delta otherwise
+	Amount1     *big.Int
+	Volume0     *big.Int // swap only, amount of token0 traded
+	Volume1     *big.Int
+}
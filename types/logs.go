@@ -0,0 +1,113 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/uptrace/bun"
+)
+
+// SyncHeightLogs is the name of the raw logs cursor in sync_heights.
+const SyncHeightLogs = "logs"
+
+// Log is a raw event log kept by the opt-in logs store. Topics are stored in
+// their own columns so they can be indexed, and returned as a list.
+type Log struct {
+	bun.BaseModel `bun:"table:logs,alias:logs" json:"-"`
+	BlockNumber   int64    `json:"block_number" bun:",pk,notnull"`
+	LogIndex      int64    `json:"log_index" bun:",pk,notnull"`
+	BlockHash     string   `json:"block_hash" bun:",type:varchar(66),notnull"`
+	TxHash        string   `json:"transaction_hash" bun:",type:varchar(66),notnull"`
+	TxIndex       int64    `json:"transaction_index" bun:",notnull,default:0"`
+	Address       string   `json:"address" bun:",type:varchar(42),notnull"`
+	Topic0        string   `json:"-" bun:",type:varchar(66),nullzero"`
+	Topic1        string   `json:"-" bun:",type:varchar(66),nullzero"`
+	Topic2        string   `json:"-" bun:",type:varchar(66),nullzero"`
+	Topic3        string   `json:"-" bun:",type:varchar(66),nullzero"`
+	Topics        []string `json:"topics" bun:"-"`
+	Data          string   `json:"data" bun:",type:text,notnull,default:'0x'"` // hex
+}
+
+func (l *Log) Lower() {
+	l.BlockHash = strings.ToLower(l.BlockHash)
+	l.TxHash = strings.ToLower(l.TxHash)
+	l.Address = strings.ToLower(l.Address)
+	l.Topic0 = strings.ToLower(l.Topic0)
+	l.Topic1 = strings.ToLower(l.Topic1)
+	l.Topic2 = strings.ToLower(l.Topic2)
+	l.Topic3 = strings.ToLower(l.Topic3)
+	l.Data = strings.ToLower(l.Data)
+}
+
+// SetTopics copies the topic columns into Topics, dropping the unused ones.
+func (l *Log) SetTopics() {
+	l.Topics = make([]string, 0, 4)
+	for _, t := range []string{l.Topic0, l.Topic1, l.Topic2, l.Topic3} {
+		if t == "" {
+			break
+		}
+		l.Topics = append(l.Topics, t)
+	}
+}
+
+// SyncHeight is the last block processed by a sync stage whose height can not
+// be derived from the data it stores.
+type SyncHeight struct {
+	bun.BaseModel `bun:"table:sync_heights,alias:sync_heights" json:"-"`
+	Name          string `json:"name" bun:",pk,type:varchar(32)"`
+	Height        int64  `json:"height" bun:",notnull,default:0"`
+}
+
+// LogAddresses is the address field of an eth_getLogs filter, either a single
+// address or a list of addresses.
+type LogAddresses []string
+
+func (a *LogAddresses) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = LogAddresses{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		return errors.New("address must be a string or a list of strings")
+	}
+
+	*a = list
+	return nil
+}
+
+// LogTopics is the topics field of an eth_getLogs filter. Each position is
+// null to match any topic, a single topic, or a list of alternatives.
+type LogTopics [][]string
+
+func (t *LogTopics) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return errors.New("topics must be a list")
+	}
+
+	topics := make(LogTopics, len(raw))
+	for i, r := range raw {
+		if string(r) == "null" {
+			continue
+		}
+
+		var single string
+		if err := json.Unmarshal(r, &single); err == nil {
+			topics[i] = []string{single}
+			continue
+		}
+
+		var list []string
+		if err := json.Unmarshal(r, &list); err != nil {
+			return errors.New("each topic must be null, a string or a list of strings")
+		}
+		topics[i] = list
+	}
+
+	*t = topics
+	return nil
+}
@@ -0,0 +1,133 @@
+package types
+
+import (
+	"sort"
+	"strings"
+)
+
+// TokenMarket is a pair normalized so the queried token is always the base.
+type TokenMarket struct {
+	ChainID      int16   `json:"chain_id"`
+	Dex          string  `json:"dex"`
+	PoolAddress  string  `json:"pool_address"`
+	PoolType     uint8   `json:"pool_type"`
+	Fee          int64   `json:"fee"`
+	TickSpacing  int64   `json:"tick_spacing"`
+	Stable       bool    `json:"stable"`
+	BaseToken    string  `json:"base_token"`
+	QuoteToken   string  `json:"quote_token"`
+	BaseReserve  string  `json:"base_reserve"`
+	QuoteReserve string  `json:"quote_reserve"`
+	Liquidity    float64 `json:"liquidity"`
+	Volume24h    float64 `json:"volume_24h"`
+	CreatedAt    int64   `json:"created_at"`
+}
+
+// TokenMarketGroup holds every market of a token against one quote token.
+type TokenMarketGroup struct {
+	ChainID    int16          `json:"chain_id"`
+	QuoteToken *Token         `json:"quote_token"`
+	Liquidity  float64        `json:"liquidity"`
+	Volume24h  float64        `json:"volume_24h"`
+	Markets    []*TokenMarket `json:"markets"`
+}
+
+// Market returns the pair as a market of token, or nil if token is not in the pair.
+func (p *Pair) Market(token string) *TokenMarket {
+	token = strings.ToLower(token)
+
+	m := &TokenMarket{
+		ChainID:     p.ChainID,
+		Dex:         p.Dex,
+		PoolAddress: p.PoolAddress,
+		PoolType:    p.PoolType,
+		Fee:         p.Fee,
+		TickSpacing: p.TickSpacing,
+		Stable:      p.Stable,
+		Liquidity:   p.Liquidity,
+		Volume24h:   p.Volume24h,
+		CreatedAt:   p.CreatedAt,
+	}
+
+	switch token {
+	case p.Token0Address:
+		m.BaseToken, m.QuoteToken = p.Token0Address, p.Token1Address
+		m.BaseReserve, m.QuoteReserve = p.Reserve0, p.Reserve1
+	case p.Token1Address:
+		m.BaseToken, m.QuoteToken = p.Token1Address, p.Token0Address
+		m.BaseReserve, m.QuoteReserve = p.Reserve1, p.Reserve0
+	default:
+		return nil
+	}
+
+	return m
+}
+
+// GroupMarkets groups the markets of token by quote token. Groups and the markets
+// within them are ordered by liquidity, deepest first. quotes holds the metadata
+// of the quote tokens, unknown quote tokens only carry their address.
+func GroupMarkets(token string, pairs []*Pair, quotes []*Token) []*TokenMarketGroup {
+	tokens := make(map[string]*Token, len(quotes))
+	for _, t := range quotes {
+		tokens[strings.ToLower(t.Address)] = t
+	}
+
+	type groupKey struct {
+		chainID int16
+		quote   string
+	}
+
+	groups := make(map[groupKey]*TokenMarketGroup)
+	for _, p := range pairs {
+		m := p.Market(token)
+		if m == nil {
+			continue
+		}
+
+		k := groupKey{chainID: m.ChainID, quote: m.QuoteToken}
+		g, ok := groups[k]
+		if !ok {
+			quote, ok := tokens[m.QuoteToken]
+			if !ok {
+				quote = &Token{Address: m.QuoteToken, ChainID: m.ChainID}
+			}
+
+			g = &TokenMarketGroup{
+				ChainID:    m.ChainID,
+				QuoteToken: quote,
+				Markets:    make([]*TokenMarket, 0, 1),
+			}
+			groups[k] = g
+		}
+
+		g.Liquidity += m.Liquidity
+		g.Volume24h += m.Volume24h
+		g.Markets = append(g.Markets, m)
+	}
+
+	result := make([]*TokenMarketGroup, 0, len(groups))
+	for _, g := range groups {
+		sort.SliceStable(g.Markets, func(i, j int) bool {
+			return g.Markets[i].Liquidity > g.Markets[j].Liquidity
+		})
+		result = append(result, g)
+	}
+
+	SortMarketGroups(result)
+
+	return result
+}
+
+// SortMarketGroups orders groups by liquidity, deepest first, so groups merged
+// from several chains keep the order of GroupMarkets.
+func SortMarketGroups(groups []*TokenMarketGroup) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Liquidity != groups[j].Liquidity {
+			return groups[i].Liquidity > groups[j].Liquidity
+		}
+		if groups[i].ChainID != groups[j].ChainID {
+			return groups[i].ChainID < groups[j].ChainID
+		}
+		return groups[i].QuoteToken.Address < groups[j].QuoteToken.Address
+	})
+}
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Models are the tables of a chain database, in creation order. Event tables
+// are created from their config and are not listed.
+var Models = []interface{}{
+	(*BlockTimestamp)(nil),
+	(*Block)(nil),
+	(*Token)(nil),
+	(*Pair)(nil),
+	(*PairReserve)(nil),
+	(*Log)(nil),
+	(*SyncHeight)(nil),
+	(*EventContract)(nil),
+	(*WatchedWallet)(nil),
+	(*WalletBalance)(nil),
+	(*WalletTransfer)(nil),
+	(*Webhook)(nil),
+	(*WebhookDelivery)(nil),
+	(*WebhookDeadLetter)(nil),
+}
+
+// TableName returns the table of a model, the table option of the bun tag on
+// its bun.BaseModel field.
+func TableName(model interface{}) string {
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	f, ok := t.FieldByName("BaseModel")
+	if !ok {
+		return ""
+	}
+
+	for _, opt := range strings.Split(f.Tag.Get("bun"), ",") {
+		if name, ok := strings.CutPrefix(opt, "table:"); ok {
+			return name
+		}
+	}
+
+	return ""
+}
@@ -40,11 +40,19 @@ func ValidateTokenSortBy(sortBy TokenSortBy) bool {
 	}
 }
 
+// Keyset is the last row of a page, the next page starts after its sort value
+// and row id instead of at an offset.
+type Keyset struct {
+	Value interface{} `json:"value"`
+	ID    string      `json:"id"`
+}
+
 type TokenOptions struct {
 	Offset    int64       `json:"offset"`
 	Limit     int64       `json:"limit"`
 	SortBy    TokenSortBy `json:"sort_by"`
 	SortOrder SortOrder   `json:"sort_order"`
+	After     *Keyset     `json:"after,omitempty"` // the row id is the address
 }
 
 type PairSortBy string
@@ -58,11 +66,13 @@ const (
 	PairSortByHash          PairSortBy = "hash"
 	PairSortByPoolType      PairSortBy = "pool_type"
 	PairSortByCreatedAt     PairSortBy = "created_at"
+	PairSortByLiquidity     PairSortBy = "liquidity"
+	PairSortByVolume24h     PairSortBy = "volume_24h"
 )
 
 func ValidatePairSortBy(sortBy PairSortBy) bool {
 	switch sortBy {
-	case PairSortByToken0Address, PairSortByToken1Address, PairSortByPoolAddress, PairSortByFee, PairSortByTickSpacing, PairSortByHash, PairSortByPoolType, PairSortByCreatedAt:
+	case PairSortByToken0Address, PairSortByToken1Address, PairSortByPoolAddress, PairSortByFee, PairSortByTickSpacing, PairSortByHash, PairSortByPoolType, PairSortByCreatedAt, PairSortByLiquidity, PairSortByVolume24h:
 		return true
 	default:
 		return false
@@ -74,4 +84,5 @@ type PairOptions struct {
 	Limit     int64      `json:"limit"`
 	SortBy    PairSortBy `json:"sort_by"`
 	SortOrder SortOrder  `json:"sort_order"`
+	After     *Keyset    `json:"after,omitempty"` // the row id is the hash
 }
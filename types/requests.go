@@ -1,5 +1,7 @@
 package types
 
+import "encoding/json"
+
 type GetBlockNumberRequest struct{}
 
 type GetChainsRequest struct{}
@@ -38,3 +40,153 @@ type FindPairsRequest struct {
 type GetPairCountRequest struct {
 	ChainID *int64 `json:"chain_id"`
 }
+
+type GetPairReservesRequest struct {
+	ChainID     *int64  `json:"chain_id"`
+	PoolAddress *string `json:"pool_address"`
+	FromBlock   *int64  `json:"from_block"`
+	ToBlock     *int64  `json:"to_block"`
+}
+
+type GetTokenMarketsRequest struct {
+	ChainID      *int64   `json:"chain_id,omitempty"` // all chains if omitted
+	Address      *string  `json:"address"`
+	MinLiquidity *float64 `json:"min_liquidity_usd,omitempty"`
+	Limit        int64    `json:"limit"` // max pairs per chain
+}
+
+type FindRoutesRequest struct {
+	ChainID  *int64  `json:"chain_id"`
+	TokenIn  *string `json:"token_in"`
+	TokenOut *string `json:"token_out"`
+	AmountIn *string `json:"amount_in,omitempty"` // raw amount of token_in, ranks routes by output
+	MaxHops  int     `json:"max_hops"`
+	Limit    int     `json:"limit"`
+}
+
+type GetBlocksRequest struct {
+	ChainID   *int64 `json:"chain_id"`
+	FromBlock *int64 `json:"from_block"`
+	ToBlock   *int64 `json:"to_block"`
+}
+
+// GetLogsRequest follows the eth_getLogs filter, either a block range or a
+// block hash, with optional address and positional topic filters.
+type GetLogsRequest struct {
+	ChainID   *int64       `json:"chain_id"`
+	FromBlock *int64       `json:"from_block,omitempty"`
+	ToBlock   *int64       `json:"to_block,omitempty"`
+	BlockHash *string      `json:"block_hash,omitempty"`
+	Address   LogAddresses `json:"address,omitempty"`
+	Topics    LogTopics    `json:"topics,omitempty"`
+}
+
+type WatchWalletsRequest struct {
+	ChainID *int64           `json:"chain_id"`
+	Wallets []*WatchedWallet `json:"wallets"`
+}
+
+type UnwatchWalletsRequest struct {
+	ChainID   *int64   `json:"chain_id"`
+	Addresses []string `json:"addresses"`
+}
+
+type GetWatchedWalletsRequest struct {
+	ChainID *int64 `json:"chain_id"`
+}
+
+type GetWalletHistoryRequest struct {
+	ChainID   *int64  `json:"chain_id"`
+	Address   *string `json:"address"`
+	FromBlock *int64  `json:"from_block"`
+	ToBlock   *int64  `json:"to_block"`
+}
+
+// CreateWebhookRequest registers a url notified of the new pairs or tokens
+// matching a filter, only the filter of the webhook type is used.
+type CreateWebhookRequest struct {
+	ChainID     *int64       `json:"chain_id"`
+	URL         *string      `json:"url"`
+	Type        *string      `json:"type"` // pairs | tokens
+	PairFilter  *PairFilter  `json:"pair_filter,omitempty"`
+	TokenFilter *TokenFilter `json:"token_filter,omitempty"`
+}
+
+type DeleteWebhookRequest struct {
+	ChainID *int64 `json:"chain_id"`
+	ID      *int64 `json:"id"`
+}
+
+type ListWebhooksRequest struct {
+	ChainID *int64 `json:"chain_id"`
+}
+
+// GetWebhookLogRequest reads the latest deliveries or dead letters of a webhook.
+type GetWebhookLogRequest struct {
+	ChainID *int64 `json:"chain_id"`
+	ID      *int64 `json:"id"`
+	Limit   int    `json:"limit"`
+}
+
+// SubscribeRequest opens a stream over a websocket. Filter is a PairFilter
+// for newPairs and a TokenFilter for newTokens, the other channels take none.
+type SubscribeRequest struct {
+	ChainID     *int64          `json:"chain_id"`
+	Channel     *string         `json:"channel"`
+	Filter      json.RawMessage `json:"filter,omitempty"`
+	PairFilter  *PairFilter     `json:"-"`
+	TokenFilter *TokenFilter    `json:"-"`
+}
+
+type UnsubscribeRequest struct {
+	Subscription *string `json:"subscription"`
+}
+
+// APIKeyRequest names the api key of an auth_ method.
+type APIKeyRequest struct {
+	Key *string `json:"key"`
+}
+
+type GetKeyStatsRequest struct {
+	Key  *string `json:"key"`
+	Days int     `json:"days"` // daily usage of the last days, including today, 30 if 0
+}
+
+// GenerateKeyRequest is optional, keys expire after api.authDefaultExpirary
+// unless exp is set.
+type GenerateKeyRequest struct {
+	Exp    *int64     `json:"exp,omitempty"` // unix, 0 never expires
+	Owner  string     `json:"owner,omitempty"`
+	Notes  string     `json:"notes,omitempty"`
+	Scopes *KeyScopes `json:"scopes,omitempty"` // unrestricted if nil
+	Tier   string     `json:"tier,omitempty"`   // api.defaultTier if empty
+}
+
+type ExtendKeyRequest struct {
+	Key *string `json:"key"`
+	Exp *int64  `json:"exp"` // unix, 0 never expires
+}
+
+// SetKeyScopesRequest replaces the scopes of a key, null scopes remove them.
+type SetKeyScopesRequest struct {
+	Key    *string    `json:"key"`
+	Scopes *KeyScopes `json:"scopes"`
+}
+
+// SetKeyTierRequest assigns a key to a tier, an empty tier is the default.
+type SetKeyTierRequest struct {
+	Key  *string `json:"key"`
+	Tier string  `json:"tier"`
+}
+
+type GetExpiringKeysRequest struct {
+	Within int64 `json:"within"` // seconds from now, 7 days if 0
+}
+
+// PairEventsRequest is read from the query of GET /events/pairs.
+type PairEventsRequest struct {
+	ChainID     *int64
+	Token       *string // token0 or token1
+	PoolType    *uint8
+	LastEventID int64 // seq of the last pair received, 0 for live pairs only
+}
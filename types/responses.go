@@ -67,3 +67,206 @@ type GetPairCountResponse struct {
 	Result int64      `json:"result,omitempty"`
 	Error  *JRPCError `json:"error,omitempty"`
 }
+
+type GetPairReservesResponse struct {
+	ID     string         `json:"id"`
+	Method string         `json:"method"`
+	Result []*PairReserve `json:"result,omitempty"`
+	Error  *JRPCError     `json:"error,omitempty"`
+}
+
+type GetTokenMarketsResponse struct {
+	ID     string              `json:"id"`
+	Method string              `json:"method"`
+	Result []*TokenMarketGroup `json:"result,omitempty"`
+	Error  *JRPCError          `json:"error,omitempty"`
+}
+
+type FindRoutesResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result []*Route   `json:"result,omitempty"`
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type GetBlocksResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result []*Block   `json:"result,omitempty"`
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type GetLogsResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result []*Log     `json:"result,omitempty"`
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type WatchWalletsResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result int64      `json:"result"` // wallets added or updated
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type UnwatchWalletsResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result int64      `json:"result"` // wallets removed
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type GetWatchedWalletsResponse struct {
+	ID     string           `json:"id"`
+	Method string           `json:"method"`
+	Result []*WatchedWallet `json:"result,omitempty"`
+	Error  *JRPCError       `json:"error,omitempty"`
+}
+
+type GetWalletBalanceHistoryResponse struct {
+	ID     string           `json:"id"`
+	Method string           `json:"method"`
+	Result []*WalletBalance `json:"result,omitempty"`
+	Error  *JRPCError       `json:"error,omitempty"`
+}
+
+type GetWalletTransfersResponse struct {
+	ID     string            `json:"id"`
+	Method string            `json:"method"`
+	Result []*WalletTransfer `json:"result,omitempty"`
+	Error  *JRPCError        `json:"error,omitempty"`
+}
+
+type CreateWebhookResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result *Webhook   `json:"result,omitempty"`
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type DeleteWebhookResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result bool       `json:"result"`
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type ListWebhooksResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result []*Webhook `json:"result,omitempty"`
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type GetWebhookDeliveriesResponse struct {
+	ID     string             `json:"id"`
+	Method string             `json:"method"`
+	Result []*WebhookDelivery `json:"result,omitempty"`
+	Error  *JRPCError         `json:"error,omitempty"`
+}
+
+type GetWebhookDeadLettersResponse struct {
+	ID     string               `json:"id"`
+	Method string               `json:"method"`
+	Result []*WebhookDeadLetter `json:"result,omitempty"`
+	Error  *JRPCError           `json:"error,omitempty"`
+}
+
+type SubscribeResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result string     `json:"result,omitempty"` // subscription id
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type UnsubscribeResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result bool       `json:"result"`
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type GenerateKeyResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result string     `json:"result,omitempty"`
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type DeleteKeyResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result bool       `json:"result"`
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type RevokeKeyResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result bool       `json:"result"`
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type GetKeyStatsResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result *KeyStats  `json:"result,omitempty"`
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type ListKeysResponse struct {
+	ID     string      `json:"id"`
+	Method string      `json:"method"`
+	Result []*KeyStats `json:"result,omitempty"`
+	Error  *JRPCError  `json:"error,omitempty"`
+}
+
+type GetAuthMethodResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result string     `json:"result,omitempty"`
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type GetKeyTypeResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result string     `json:"result,omitempty"`
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type ExtendKeyResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result bool       `json:"result"`
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type SetKeyScopesResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result bool       `json:"result"`
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type SetKeyTierResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result bool       `json:"result"`
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type RotateKeyResponse struct {
+	ID     string     `json:"id"`
+	Method string     `json:"method"`
+	Result string     `json:"result,omitempty"` // the new key
+	Error  *JRPCError `json:"error,omitempty"`
+}
+
+type GetExpiringKeysResponse struct {
+	ID     string      `json:"id"`
+	Method string      `json:"method"`
+	Result []*KeyStats `json:"result,omitempty"`
+	Error  *JRPCError  `json:"error,omitempty"`
+}
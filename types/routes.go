@@ -0,0 +1,21 @@
+package types
+
+// Route is a path of pools between two tokens.
+type Route struct {
+	Hops      []*RouteHop `json:"hops"`
+	AmountIn  string      `json:"amount_in,omitempty"`
+	AmountOut string      `json:"amount_out,omitempty"`
+	Liquidity float64     `json:"liquidity"` // liquidity of the shallowest pool
+}
+
+type RouteHop struct {
+	PoolAddress string  `json:"pool_address"`
+	Dex         string  `json:"dex"`
+	PoolType    uint8   `json:"pool_type"`
+	Fee         int64   `json:"fee"`
+	Stable      bool    `json:"stable"`
+	TokenIn     string  `json:"token_in"`
+	TokenOut    string  `json:"token_out"`
+	AmountOut   string  `json:"amount_out,omitempty"`
+	Liquidity   float64 `json:"liquidity"`
+}
@@ -0,0 +1,28 @@
+package types
+
+// subscription channels of idx_subscribe
+const (
+	ChannelNewPairs   = "newPairs"
+	ChannelNewTokens  = "newTokens"
+	ChannelNewBlocks  = "newBlocks"
+	ChannelSyncStatus = "syncStatus"
+)
+
+// SubscriptionNotification is pushed to a websocket for every event of a
+// subscription.
+type SubscriptionNotification struct {
+	JSONRPC string             `json:"jsonrpc"`
+	Method  string             `json:"method"`
+	Params  SubscriptionResult `json:"params"`
+}
+
+type SubscriptionResult struct {
+	Subscription string      `json:"subscription"`
+	Result       interface{} `json:"result"`
+}
+
+// SyncStatus is the result of syncStatus notifications.
+type SyncStatus struct {
+	ChainID int64    `json:"chain_id"`
+	Heights *Heights `json:"heights"`
+}
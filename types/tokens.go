@@ -16,6 +16,7 @@ type Token struct {
 	CreatedAt     int64  `json:"created_at"`
 	CreationHash  string `json:"creation_hash" bun:",type:varchar(66),default:'0x0000000000000000000000000000000000000000000000000000000000000000'"`
 	ChainID       int16  `json:"chain_id"`
+	Seq           int64  `json:"seq" bun:",autoincrement"` // ingestion order
 }
 
 func (p *Token) Lower() {
@@ -37,6 +38,36 @@ type BlockTimestamp struct {
 	Timestamp     int64 `json:"timestamp" bun:",notnull,default:0"`
 }
 
+// SyncHeightHeaders is the sync_heights row of the block headers stage.
+const SyncHeightHeaders = "headers"
+
+// Block is a block header enriched with its transaction count.
+type Block struct {
+	bun.BaseModel `bun:"table:blocks,alias:blocks" json:"-"`
+	Number        int64  `json:"number" bun:",pk,notnull"`
+	Hash          string `json:"hash" bun:",type:varchar(66),notnull"`
+	ParentHash    string `json:"parent_hash" bun:",type:varchar(66),notnull"`
+	Timestamp     int64  `json:"timestamp" bun:",notnull,default:0"`
+	BaseFee       string `json:"base_fee" bun:",type:numeric,nullzero,notnull,default:0"` // wei, 0 before london
+	GasUsed       int64  `json:"gas_used" bun:",notnull,default:0"`
+	GasLimit      int64  `json:"gas_limit" bun:",notnull,default:0"`
+	Miner         string `json:"miner" bun:",type:varchar(42),notnull"` // validator on bsc
+	TxCount       int64  `json:"tx_count" bun:",notnull,default:0"`
+}
+
+func (b *Block) Lower() {
+	b.Hash = strings.ToLower(b.Hash)
+	b.ParentHash = strings.ToLower(b.ParentHash)
+	b.Miner = strings.ToLower(b.Miner)
+}
+
+func (b *Block) BlockTimestamp() *BlockTimestamp {
+	return &BlockTimestamp{
+		Block:     b.Number,
+		Timestamp: b.Timestamp,
+	}
+}
+
 type Creator struct {
 	Hash    string `json:"hash"`
 	Creator string `json:"creator"`
@@ -48,15 +79,23 @@ type BlockNumber struct {
 
 type Pair struct {
 	bun.BaseModel `bun:"table:pairs,alias:pairs" json:"-"`
-	Token0Address string `json:"token0_address" bun:",type:varchar(42),notnull"`
-	Token1Address string `json:"token1_address" bun:",type:varchar(42),notnull"`
-	Fee           int64  `json:"fee" bun:",notnull,default:0"`
-	TickSpacing   int64  `json:"tick_spacing" bun:",notnull,default:0"`
-	PoolAddress   string `json:"pool_address" bun:",notnull,type:varchar(42),unique"`
-	PoolType      uint8  `json:"pool_type" bun:",notnull,default:0"`
-	CreatedAt     int64  `json:"created_at"`
-	Hash          string `json:"hash" bun:",pk,type:varchar(66)"`
-	ChainID       int16  `json:"chain_id"`
+	Token0Address string  `json:"token0_address" bun:",type:varchar(42),notnull"`
+	Token1Address string  `json:"token1_address" bun:",type:varchar(42),notnull"`
+	Fee           int64   `json:"fee" bun:",notnull,default:0"`
+	TickSpacing   int64   `json:"tick_spacing" bun:",notnull,default:0"`
+	PoolAddress   string  `json:"pool_address" bun:",notnull,type:varchar(42),unique"`
+	PoolType      uint8   `json:"pool_type" bun:",notnull,default:0"`
+	Stable        bool    `json:"stable" bun:",notnull,default:false"`
+	Dex           string  `json:"dex" bun:",type:varchar(32),notnull,default:''"`
+	CreatedAt     int64   `json:"created_at"`
+	Hash          string  `json:"hash" bun:",pk,type:varchar(66)"`
+	ChainID       int16   `json:"chain_id"`
+	Reserve0      string  `json:"reserve0" bun:",type:numeric,nullzero,notnull,default:0"`
+	Reserve1      string  `json:"reserve1" bun:",type:numeric,nullzero,notnull,default:0"`
+	ReservesBlock int64   `json:"reserves_block" bun:",notnull,default:0"`
+	Liquidity     float64 `json:"liquidity" bun:",notnull,default:0"` // usd tvl
+	Volume24h     float64 `json:"volume_24h" bun:"volume_24h,notnull,default:0"`
+	Seq           int64   `json:"seq" bun:",autoincrement"` // ingestion order
 }
 
 func (p *Pair) Lower() {
@@ -80,7 +119,10 @@ type OHLC struct {
 }
 
 type Heights struct {
-	Blocks int64
-	Tokens int64
-	Pairs  int64
+	Blocks   int64
+	Headers  int64
+	Tokens   int64
+	Pairs    int64
+	Reserves int64
+	Logs     int64
 }
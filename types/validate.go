@@ -1,7 +1,12 @@
 package types
 
 import (
+	"encoding/json"
 	"errors"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
 )
 
 var (
@@ -12,7 +17,12 @@ var (
 	errMissingToBlock     = errors.New("missing required parameter: to_block")
 	errMissingTimestamp   = errors.New("missing required parameter: timestamp")
 	errMissingFilter      = errors.New("missing required parameter: filter")
-	errInvalidPairSortBy  = errors.New("invalid parameter: sort_by - must be either 'token0_address', 'token1_address', 'pool_address', 'fee', 'tick_spacing', 'hash', 'pool_type', 'created_at'")
+	errMissingPoolAddress = errors.New("missing required parameter: pool_address")
+	errMissingAddress     = errors.New("missing required parameter: address")
+	errMissingWebhookID   = errors.New("missing required parameter: id")
+	errMissingKey         = errors.New("missing required parameter: key")
+	errInvalidExp         = errors.New("invalid parameter: exp - must be 0 or a unix time in the future")
+	errInvalidPairSortBy  = errors.New("invalid parameter: sort_by - must be either 'token0_address', 'token1_address', 'pool_address', 'fee', 'tick_spacing', 'hash', 'pool_type', 'created_at', 'liquidity', 'volume_24h'")
 	errInvalidTokenSortBy = errors.New("invalid parameter: sort_by - must be either 'address', 'name', 'symbol', 'decimals', 'creator', 'created_at', 'creation_hash'")
 	errInvalidSortOrder   = errors.New("invalid parameter: sort_order - must be either 'asc' or 'desc'")
 )
@@ -191,6 +201,30 @@ func (r *FindTokensRequest) Validate() error {
 		return errInvalidTokenSortBy
 	}
 
+	return validateKeyset(r.Options.After, r.Options.Offset)
+}
+
+// validateKeyset checks the after option of a find request, it replaces the
+// offset and its value is compared with the sort column.
+func validateKeyset(after *Keyset, offset int64) error {
+	if after == nil {
+		return nil
+	}
+
+	if offset != 0 {
+		return errors.New("offset and after can not be combined")
+	}
+
+	if after.ID == "" {
+		return errors.New("invalid parameter: after - id is required")
+	}
+
+	switch after.Value.(type) {
+	case string, bool, float64, int64:
+	default:
+		return errors.New("invalid parameter: after - value must be a string, number or bool")
+	}
+
 	return nil
 }
 
@@ -268,6 +302,14 @@ func (r *FindPairsRequest) Validate() error {
 		return errInvalidPairSortBy
 	}
 
+	if err := validateKeyset(r.Options.After, r.Options.Offset); err != nil {
+		return err
+	}
+
+	if r.Filter.MinLiquidity != nil && *r.Filter.MinLiquidity < 0 {
+		return errors.New("min_liquidity_usd must be greater than or equal to 0")
+	}
+
 	return nil
 }
 
@@ -286,3 +328,686 @@ func (r *GetHeightsRequest) Validate() error {
 
 	return nil
 }
+
+func (r *GetPairReservesRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.ChainID == nil {
+		return errMissingChainID
+	}
+
+	if *r.ChainID == 0 {
+		return errInvalidChainID
+	}
+
+	if r.PoolAddress == nil || *r.PoolAddress == "" {
+		return errMissingPoolAddress
+	}
+
+	if r.FromBlock == nil {
+		return errMissingFromBlock
+	}
+
+	if r.ToBlock == nil {
+		return errMissingToBlock
+	}
+
+	if *r.FromBlock > *r.ToBlock {
+		return errors.New("from_block must be less than or equal to to_block")
+	}
+
+	if *r.FromBlock < 0 {
+		return errors.New("from_block must be greater than or equal to 0")
+	}
+
+	if *r.ToBlock-*r.FromBlock > 100000 {
+		return errors.New("from_block and to_block must be within 100000 blocks of each other")
+	}
+
+	return nil
+}
+
+func (r *GetTokenMarketsRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.ChainID != nil && *r.ChainID == 0 {
+		return errInvalidChainID
+	}
+
+	if r.Address == nil || *r.Address == "" {
+		return errMissingAddress
+	}
+
+	if r.MinLiquidity != nil && *r.MinLiquidity < 0 {
+		return errors.New("min_liquidity_usd must be greater than or equal to 0")
+	}
+
+	if r.Limit < 0 {
+		return errors.New("limit must be greater than or equal to 0")
+	}
+
+	if r.Limit == 0 {
+		r.Limit = 1000
+	}
+
+	if r.Limit > 10000 {
+		return errors.New("limit must be less than or equal to 10000")
+	}
+
+	return nil
+}
+
+func (r *FindRoutesRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.ChainID == nil {
+		return errMissingChainID
+	}
+
+	if *r.ChainID == 0 {
+		return errInvalidChainID
+	}
+
+	if r.TokenIn == nil || *r.TokenIn == "" {
+		return errors.New("missing required parameter: token_in")
+	}
+
+	if r.TokenOut == nil || *r.TokenOut == "" {
+		return errors.New("missing required parameter: token_out")
+	}
+
+	if strings.EqualFold(*r.TokenIn, *r.TokenOut) {
+		return errors.New("token_in and token_out must be different")
+	}
+
+	if r.AmountIn != nil {
+		amount, ok := new(big.Int).SetString(*r.AmountIn, 10)
+		if !ok || amount.Sign() <= 0 {
+			return errors.New("amount_in must be a positive base 10 integer")
+		}
+	}
+
+	if r.MaxHops < 0 {
+		return errors.New("max_hops must be greater than or equal to 0")
+	}
+
+	if r.MaxHops == 0 {
+		r.MaxHops = 3
+	}
+
+	if r.MaxHops > 4 {
+		return errors.New("max_hops must be less than or equal to 4")
+	}
+
+	if r.Limit < 0 {
+		return errors.New("limit must be greater than or equal to 0")
+	}
+
+	if r.Limit == 0 {
+		r.Limit = 5
+	}
+
+	if r.Limit > 50 {
+		return errors.New("limit must be less than or equal to 50")
+	}
+
+	return nil
+}
+
+func (r *GetBlocksRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.ChainID == nil {
+		return errMissingChainID
+	}
+
+	if *r.ChainID == 0 {
+		return errInvalidChainID
+	}
+
+	if r.FromBlock == nil {
+		return errMissingFromBlock
+	}
+
+	if r.ToBlock == nil {
+		return errMissingToBlock
+	}
+
+	if *r.FromBlock > *r.ToBlock {
+		return errors.New("from_block must be less than or equal to to_block")
+	}
+
+	if *r.FromBlock < 0 {
+		return errors.New("from_block must be greater than or equal to 0")
+	}
+
+	if *r.ToBlock-*r.FromBlock > 10000 {
+		return errors.New("from_block and to_block must be within 10000 blocks of each other")
+	}
+
+	return nil
+}
+
+func (r *GetLogsRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.ChainID == nil {
+		return errMissingChainID
+	}
+
+	if *r.ChainID == 0 {
+		return errInvalidChainID
+	}
+
+	if r.BlockHash != nil {
+		if r.FromBlock != nil || r.ToBlock != nil {
+			return errors.New("block_hash can not be combined with from_block or to_block")
+		}
+
+		if len(*r.BlockHash) != 66 || !strings.HasPrefix(*r.BlockHash, "0x") {
+			return errors.New("block_hash must be a 32 byte hex string")
+		}
+
+		*r.BlockHash = strings.ToLower(*r.BlockHash)
+	} else {
+		if r.FromBlock == nil {
+			return errMissingFromBlock
+		}
+
+		if r.ToBlock == nil {
+			return errMissingToBlock
+		}
+
+		if *r.FromBlock > *r.ToBlock {
+			return errors.New("from_block must be less than or equal to to_block")
+		}
+
+		if *r.FromBlock < 0 {
+			return errors.New("from_block must be greater than or equal to 0")
+		}
+
+		if *r.ToBlock-*r.FromBlock > 10000 {
+			return errors.New("from_block and to_block must be within 10000 blocks of each other")
+		}
+	}
+
+	if len(r.Address) > 1000 {
+		return errors.New("address must have at most 1000 entries")
+	}
+
+	for i, a := range r.Address {
+		if !isHexAddress(a) {
+			return errors.New("address must be a 20 byte hex string")
+		}
+		r.Address[i] = strings.ToLower(a)
+	}
+
+	if len(r.Topics) > 4 {
+		return errors.New("topics must have at most 4 positions")
+	}
+
+	for _, position := range r.Topics {
+		for i, t := range position {
+			if len(t) != 66 || !strings.HasPrefix(t, "0x") {
+				return errors.New("topics must be 32 byte hex strings")
+			}
+			position[i] = strings.ToLower(t)
+		}
+	}
+
+	return nil
+}
+
+func (r *WatchWalletsRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.ChainID == nil {
+		return errMissingChainID
+	}
+
+	if *r.ChainID == 0 {
+		return errInvalidChainID
+	}
+
+	if len(r.Wallets) == 0 {
+		return errors.New("missing required parameter: wallets")
+	}
+
+	if len(r.Wallets) > 10000 {
+		return errors.New("wallets must have at most 10000 entries")
+	}
+
+	for _, w := range r.Wallets {
+		if w == nil || !isHexAddress(w.Address) {
+			return errors.New("wallet address must be a 20 byte hex string")
+		}
+
+		if len(w.Label) > 64 {
+			return errors.New("wallet label must be at most 64 characters")
+		}
+
+		if w.MinTransferUSD < 0 {
+			return errors.New("min_transfer_usd must be greater than or equal to 0")
+		}
+
+		w.Lower()
+	}
+
+	return nil
+}
+
+func (r *UnwatchWalletsRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.ChainID == nil {
+		return errMissingChainID
+	}
+
+	if *r.ChainID == 0 {
+		return errInvalidChainID
+	}
+
+	if len(r.Addresses) == 0 {
+		return errors.New("missing required parameter: addresses")
+	}
+
+	if len(r.Addresses) > 10000 {
+		return errors.New("addresses must have at most 10000 entries")
+	}
+
+	for i, a := range r.Addresses {
+		if !isHexAddress(a) {
+			return errors.New("address must be a 20 byte hex string")
+		}
+		r.Addresses[i] = strings.ToLower(a)
+	}
+
+	return nil
+}
+
+func (r *GetWatchedWalletsRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.ChainID == nil {
+		return errMissingChainID
+	}
+
+	if *r.ChainID == 0 {
+		return errInvalidChainID
+	}
+
+	return nil
+}
+
+func (r *GetWalletHistoryRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.ChainID == nil {
+		return errMissingChainID
+	}
+
+	if *r.ChainID == 0 {
+		return errInvalidChainID
+	}
+
+	if r.Address == nil || *r.Address == "" {
+		return errMissingAddress
+	}
+
+	if !isHexAddress(*r.Address) {
+		return errors.New("address must be a 20 byte hex string")
+	}
+	*r.Address = strings.ToLower(*r.Address)
+
+	if r.FromBlock == nil {
+		return errMissingFromBlock
+	}
+
+	if r.ToBlock == nil {
+		return errMissingToBlock
+	}
+
+	if *r.FromBlock > *r.ToBlock {
+		return errors.New("from_block must be less than or equal to to_block")
+	}
+
+	if *r.FromBlock < 0 {
+		return errors.New("from_block must be greater than or equal to 0")
+	}
+
+	if *r.ToBlock-*r.FromBlock > 100000 {
+		return errors.New("from_block and to_block must be within 100000 blocks of each other")
+	}
+
+	return nil
+}
+
+func isHexAddress(s string) bool {
+	return len(s) == 42 && strings.HasPrefix(s, "0x")
+}
+
+func (r *CreateWebhookRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.ChainID == nil {
+		return errMissingChainID
+	}
+
+	if *r.ChainID == 0 {
+		return errInvalidChainID
+	}
+
+	if r.URL == nil || *r.URL == "" {
+		return errors.New("missing required parameter: url")
+	}
+
+	u, err := url.Parse(*r.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https url")
+	}
+
+	if len(*r.URL) > 2048 {
+		return errors.New("url must be at most 2048 characters")
+	}
+
+	if r.Type == nil {
+		return errors.New("missing required parameter: type")
+	}
+
+	switch *r.Type {
+	case WebhookPairs:
+		if r.PairFilter == nil {
+			r.PairFilter = &PairFilter{}
+		}
+		r.PairFilter.Lower()
+		r.TokenFilter = nil
+
+	case WebhookTokens:
+		if r.TokenFilter == nil {
+			r.TokenFilter = &TokenFilter{}
+		}
+		r.TokenFilter.Lower()
+		r.PairFilter = nil
+
+	default:
+		return errors.New("invalid parameter: type - must be either 'pairs' or 'tokens'")
+	}
+
+	return nil
+}
+
+func (r *DeleteWebhookRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.ChainID == nil {
+		return errMissingChainID
+	}
+
+	if *r.ChainID == 0 {
+		return errInvalidChainID
+	}
+
+	if r.ID == nil {
+		return errMissingWebhookID
+	}
+
+	return nil
+}
+
+func (r *ListWebhooksRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.ChainID == nil {
+		return errMissingChainID
+	}
+
+	if *r.ChainID == 0 {
+		return errInvalidChainID
+	}
+
+	return nil
+}
+
+func (r *GetWebhookLogRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.ChainID == nil {
+		return errMissingChainID
+	}
+
+	if *r.ChainID == 0 {
+		return errInvalidChainID
+	}
+
+	if r.ID == nil {
+		return errMissingWebhookID
+	}
+
+	if r.Limit < 0 {
+		return errors.New("limit must be greater than or equal to 0")
+	}
+
+	if r.Limit == 0 || r.Limit > 1000 {
+		r.Limit = 1000
+	}
+
+	return nil
+}
+
+func (r *SubscribeRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.ChainID == nil {
+		return errMissingChainID
+	}
+
+	if *r.ChainID == 0 {
+		return errInvalidChainID
+	}
+
+	if r.Channel == nil {
+		return errors.New("missing required parameter: channel")
+	}
+
+	switch *r.Channel {
+	case ChannelNewPairs:
+		r.PairFilter = &PairFilter{}
+		if len(r.Filter) > 0 && json.Unmarshal(r.Filter, r.PairFilter) != nil {
+			return errors.New("invalid parameter: filter - must be a pair filter")
+		}
+		r.PairFilter.Lower()
+
+	case ChannelNewTokens:
+		r.TokenFilter = &TokenFilter{}
+		if len(r.Filter) > 0 && json.Unmarshal(r.Filter, r.TokenFilter) != nil {
+			return errors.New("invalid parameter: filter - must be a token filter")
+		}
+		r.TokenFilter.Lower()
+
+	case ChannelNewBlocks, ChannelSyncStatus:
+
+	default:
+		return errors.New("invalid parameter: channel - must be either 'newPairs', 'newTokens', 'newBlocks' or 'syncStatus'")
+	}
+
+	return nil
+}
+
+func (r *UnsubscribeRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.Subscription == nil || *r.Subscription == "" {
+		return errors.New("missing required parameter: subscription")
+	}
+
+	return nil
+}
+
+func (r *PairEventsRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.ChainID == nil {
+		return errMissingChainID
+	}
+
+	if *r.ChainID == 0 {
+		return errInvalidChainID
+	}
+
+	if r.Token != nil {
+		if !isHexAddress(*r.Token) {
+			return errors.New("token must be a 20 byte hex string")
+		}
+		*r.Token = strings.ToLower(*r.Token)
+	}
+
+	if r.LastEventID < 0 {
+		return errors.New("Last-Event-ID must be greater than or equal to 0")
+	}
+
+	return nil
+}
+
+func (r *APIKeyRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.Key == nil || *r.Key == "" {
+		return errMissingKey
+	}
+
+	return nil
+}
+
+func (r *GetKeyStatsRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.Key == nil || *r.Key == "" {
+		return errMissingKey
+	}
+
+	if r.Days < 0 {
+		return errors.New("days must be greater than or equal to 0")
+	}
+
+	if r.Days == 0 {
+		r.Days = 30
+	}
+
+	if r.Days > 366 {
+		r.Days = 366
+	}
+
+	return nil
+}
+
+func (r *GenerateKeyRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.Exp != nil && !validExp(*r.Exp) {
+		return errInvalidExp
+	}
+
+	return r.Scopes.Validate()
+}
+
+func (r *ExtendKeyRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.Key == nil || *r.Key == "" {
+		return errMissingKey
+	}
+
+	if r.Exp == nil {
+		return errors.New("missing required parameter: exp")
+	}
+
+	if !validExp(*r.Exp) {
+		return errInvalidExp
+	}
+
+	return nil
+}
+
+func (r *SetKeyScopesRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.Key == nil || *r.Key == "" {
+		return errMissingKey
+	}
+
+	return r.Scopes.Validate()
+}
+
+func (r *SetKeyTierRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.Key == nil || *r.Key == "" {
+		return errMissingKey
+	}
+
+	return nil
+}
+
+func (r *GetExpiringKeysRequest) Validate() error {
+	if r == nil {
+		return errEmptyRequest
+	}
+
+	if r.Within < 0 {
+		return errors.New("within must be greater than or equal to 0")
+	}
+
+	if r.Within == 0 {
+		r.Within = 7 * 24 * 60 * 60
+	}
+
+	return nil
+}
+
+func validExp(exp int64) bool {
+	return exp == 0 || exp > time.Now().Unix()
+}
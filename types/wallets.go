@@ -0,0 +1,84 @@
+package types
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/uptrace/bun"
+)
+
+// SyncHeightWallets is the name of the watchlist cursor in sync_heights.
+const SyncHeightWallets = "wallets"
+
+const (
+	TransferIn  = "in"
+	TransferOut = "out"
+)
+
+// WatchedWallet is a wallet whose native balance and large token transfers are tracked.
+type WatchedWallet struct {
+	bun.BaseModel  `bun:"table:watched_wallets,alias:watched_wallets" json:"-"`
+	Address        string  `json:"address" bun:",pk,type:varchar(42)"`
+	Label          string  `json:"label" bun:",type:varchar(64),notnull,default:''"`
+	MinTransferUSD float64 `json:"min_transfer_usd" bun:"min_transfer_usd,notnull,default:0"` // 0 uses sync.wallets.minTransferUSD
+	AddedAt        int64   `json:"added_at" bun:",notnull,default:0"`                         // unix
+}
+
+func (w *WatchedWallet) Lower() {
+	w.Address = strings.ToLower(w.Address)
+}
+
+// WalletBalance is the native balance of a watched wallet after a block where
+// it changed. The first row of a wallet is its baseline and has no delta.
+type WalletBalance struct {
+	bun.BaseModel `bun:"table:wallet_balances,alias:wallet_balances" json:"-"`
+	Address       string `json:"address" bun:",pk,type:varchar(42)"`
+	Block         int64  `json:"block" bun:",pk"`
+	Balance       string `json:"balance" bun:",type:numeric,nullzero,notnull,default:0"`
+	Delta         string `json:"delta" bun:",type:numeric,nullzero,notnull,default:0"` // signed
+}
+
+func (b *WalletBalance) Lower() {
+	b.Address = strings.ToLower(b.Address)
+}
+
+// WalletTransfer is an erc20 transfer of a watched wallet above its threshold.
+type WalletTransfer struct {
+	bun.BaseModel `bun:"table:wallet_transfers,alias:wallet_transfers" json:"-"`
+	Address       string  `json:"address" bun:",pk,type:varchar(42)"`
+	Block         int64   `json:"block" bun:",pk"`
+	LogIndex      int64   `json:"log_index" bun:",pk"`
+	Direction     string  `json:"direction" bun:",type:varchar(3),notnull"` // in | out
+	Token         string  `json:"token" bun:",type:varchar(42),notnull"`
+	Counterparty  string  `json:"counterparty" bun:",type:varchar(42),notnull"`
+	Amount        string  `json:"amount" bun:",type:numeric,nullzero,notnull,default:0"`
+	ValueUSD      float64 `json:"value_usd" bun:"value_usd,notnull,default:0"` // 0 if the token can not be priced
+	TxHash        string  `json:"tx_hash" bun:",type:varchar(66),notnull"`
+}
+
+func (t *WalletTransfer) Lower() {
+	t.Address = strings.ToLower(t.Address)
+	t.Token = strings.ToLower(t.Token)
+	t.Counterparty = strings.ToLower(t.Counterparty)
+	t.TxHash = strings.ToLower(t.TxHash)
+}
+
+// Transaction is the part of a transaction used to find the wallets it touches.
+type Transaction struct {
+	Block int64
+	Hash  string
+	From  string
+	To    string // empty for contract creations
+	Value *big.Int
+}
+
+// TokenTransfer is a decoded erc20 Transfer event.
+type TokenTransfer struct {
+	Block    int64
+	LogIndex int64
+	TxHash   string
+	Token    string
+	From     string
+	To       string
+	Amount   *big.Int
+}
@@ -0,0 +1,60 @@
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/uptrace/bun"
+)
+
+const (
+	WebhookPairs  = "pairs"
+	WebhookTokens = "tokens"
+)
+
+// Webhook posts the new pairs or tokens matching its filter to a url. Hooks
+// belong to the api key that created them.
+type Webhook struct {
+	bun.BaseModel `bun:"table:webhooks,alias:webhooks" json:"-"`
+	ID            int64        `json:"id" bun:",pk,autoincrement"`
+	Owner         string       `json:"-" bun:",notnull"` // api key
+	URL           string       `json:"url" bun:",notnull"`
+	Type          string       `json:"type" bun:",type:varchar(8),notnull"` // pairs | tokens
+	PairFilter    *PairFilter  `json:"pair_filter,omitempty" bun:",type:jsonb"`
+	TokenFilter   *TokenFilter `json:"token_filter,omitempty" bun:",type:jsonb"`
+	Secret        string       `json:"secret,omitempty" bun:",notnull"` // hmac key, only returned on create
+	CreatedAt     int64        `json:"created_at" bun:",notnull,default:0"`
+}
+
+// WebhookDelivery logs one delivery attempt of a webhook.
+type WebhookDelivery struct {
+	bun.BaseModel `bun:"table:webhook_deliveries,alias:webhook_deliveries" json:"-"`
+	ID            int64  `json:"id" bun:",pk,autoincrement"`
+	WebhookID     int64  `json:"webhook_id" bun:",notnull"`
+	Attempt       int    `json:"attempt" bun:",notnull"`
+	StatusCode    int    `json:"status_code" bun:",notnull,default:0"` // 0 if no response
+	Error         string `json:"error,omitempty" bun:",notnull,default:''"`
+	DurationMs    int64  `json:"duration_ms" bun:",notnull,default:0"`
+	Rows          int    `json:"rows" bun:",notnull,default:0"`
+	CreatedAt     int64  `json:"created_at" bun:",notnull,default:0"`
+}
+
+// WebhookDeadLetter keeps a payload that failed every delivery attempt.
+type WebhookDeadLetter struct {
+	bun.BaseModel `bun:"table:webhook_dead_letters,alias:webhook_dead_letters" json:"-"`
+	ID            int64           `json:"id" bun:",pk,autoincrement"`
+	WebhookID     int64           `json:"webhook_id" bun:",notnull"`
+	Payload       json.RawMessage `json:"payload" bun:",type:jsonb,notnull"`
+	Attempts      int             `json:"attempts" bun:",notnull"`
+	Error         string          `json:"error" bun:",notnull,default:''"`
+	CreatedAt     int64           `json:"created_at" bun:",notnull,default:0"`
+}
+
+// WebhookPayload is the signed body posted to a webhook.
+type WebhookPayload struct {
+	WebhookID int64    `json:"webhook_id"`
+	ChainID   int64    `json:"chain_id"`
+	Type      string   `json:"type"`
+	Timestamp int64    `json:"timestamp"` // unix
+	Pairs     []*Pair  `json:"pairs,omitempty"`
+	Tokens    []*Token `json:"tokens,omitempty"`
+}
@@ -23,6 +23,15 @@ func TestPoolCreated(t *testing.T) {
 	}
 }
 
+func TestSolidlyPairCreated(t *testing.T) {
+	topic := "PairCreated(address,address,bool,address,uint256)"
+	hash := TopicToHash(topic)
+	fmt.Println(hash.String())
+	if hash.String() != "0xc4805696c66d7cf352fc1d6bb633ad5ee82f6cb577c453024b6e0eb8306c6fc9" {
+		t.Errorf("hash is not correct: %s", hash.String())
+	}
+}
+
 func TestExtract(t *testing.T) {
 	a := "0x00000000000000000000000007da4c5260c678a3acb554bd295b98d313f5502d"
 	address := ExtractAddress(a)
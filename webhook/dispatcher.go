@@ -0,0 +1,339 @@
+// Package webhook posts the feed events matching registered webhooks to their
+// urls, signed with the webhook secret.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/autoapev1/indexer/feed"
+	"github.com/autoapev1/indexer/types"
+)
+
+const (
+	HeaderSignature = "X-Indexer-Signature"
+	HeaderWebhookID = "X-Indexer-Webhook-Id"
+	HeaderAttempt   = "X-Indexer-Attempt"
+)
+
+// Store is the part of a chain store used to load webhooks and log deliveries.
+type Store interface {
+	GetAllWebhooks() ([]*types.Webhook, error)
+	InsertWebhookDelivery(*types.WebhookDelivery) error
+	InsertWebhookDeadLetter(*types.WebhookDeadLetter) error
+}
+
+// Dispatcher delivers feed events to the webhooks of each chain. The
+// webhooks are reloaded from the stores every refresh interval.
+type Dispatcher struct {
+	stores       map[int64]Store
+	client       *http.Client
+	maxAttempts  int
+	backoff      time.Duration
+	refresh      time.Duration
+	allowPrivate bool
+
+	lock     sync.Mutex
+	hooks    map[int64][]*types.Webhook
+	loadedAt time.Time
+	wg       sync.WaitGroup
+}
+
+func NewDispatcher(stores map[int64]Store) *Dispatcher {
+	d := &Dispatcher{
+		stores:      stores,
+		maxAttempts: 5,
+		backoff:     time.Second,
+		refresh:     30 * time.Second,
+		hooks:       make(map[int64][]*types.Webhook),
+	}
+
+	// no proxy from the environment, the address check must see the target
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   d.control,
+	}
+	d.client = &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}
+
+	return d
+}
+
+func (d *Dispatcher) WithMaxAttempts(n int) *Dispatcher {
+	if n > 0 {
+		d.maxAttempts = n
+	}
+	return d
+}
+
+func (d *Dispatcher) WithTimeout(timeout time.Duration) *Dispatcher {
+	if timeout > 0 {
+		d.client.Timeout = timeout
+	}
+	return d
+}
+
+// WithAllowPrivate lets deliveries connect to loopback, private and
+// link-local addresses, for local development.
+func (d *Dispatcher) WithAllowPrivate(allow bool) *Dispatcher {
+	d.allowPrivate = allow
+	return d
+}
+
+// WithBackoff sets the delay before the first retry, it doubles on every retry.
+func (d *Dispatcher) WithBackoff(backoff time.Duration) *Dispatcher {
+	if backoff > 0 {
+		d.backoff = backoff
+	}
+	return d
+}
+
+// Run dispatches the batches received on sub until it is closed or ctx is
+// done, then waits for pending deliveries.
+func (d *Dispatcher) Run(ctx context.Context, sub *feed.Subscription) {
+	defer d.wg.Wait()
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case events, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			d.Dispatch(ctx, events)
+		}
+	}
+}
+
+// Dispatch starts one delivery per webhook matching at least one of events,
+// with every matching row in a single payload.
+func (d *Dispatcher) Dispatch(ctx context.Context, events []*feed.Event) {
+	hooks := d.webhooks()
+
+	byChain := make(map[int64][]*feed.Event)
+	for _, e := range events {
+		byChain[e.ChainID] = append(byChain[e.ChainID], e)
+	}
+
+	for chainID, chainEvents := range byChain {
+		store, ok := d.stores[chainID]
+		if !ok {
+			continue
+		}
+
+		for _, hook := range hooks[chainID] {
+			payload := match(hook, chainID, chainEvents)
+			if payload == nil {
+				continue
+			}
+
+			d.wg.Add(1)
+			go func(hook *types.Webhook) {
+				defer d.wg.Done()
+				d.deliver(ctx, store, hook, payload)
+			}(hook)
+		}
+	}
+}
+
+// Wait blocks until the started deliveries are done.
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) webhooks() map[int64][]*types.Webhook {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if time.Since(d.loadedAt) < d.refresh {
+		return d.hooks
+	}
+
+	for chainID, store := range d.stores {
+		hooks, err := store.GetAllWebhooks()
+		if err != nil {
+			// keep the previous webhooks of the chain
+			slog.Error("failed to load webhooks", "chain_id", chainID, "err", err)
+			continue
+		}
+		d.hooks[chainID] = hooks
+	}
+	d.loadedAt = time.Now()
+
+	return d.hooks
+}
+
+// match returns the payload of the events passing the webhook filter, or nil.
+func match(hook *types.Webhook, chainID int64, events []*feed.Event) *types.WebhookPayload {
+	payload := &types.WebhookPayload{
+		WebhookID: hook.ID,
+		ChainID:   chainID,
+		Type:      hook.Type,
+	}
+
+	for _, e := range events {
+		switch {
+		case hook.Type == types.WebhookPairs && e.Kind == feed.KindPair && hook.PairFilter.Match(e.Pair):
+			payload.Pairs = append(payload.Pairs, e.Pair)
+		case hook.Type == types.WebhookTokens && e.Kind == feed.KindToken && hook.TokenFilter.Match(e.Token):
+			payload.Tokens = append(payload.Tokens, e.Token)
+		}
+	}
+
+	if len(payload.Pairs) == 0 && len(payload.Tokens) == 0 {
+		return nil
+	}
+
+	return payload
+}
+
+// deliver posts the payload until it is accepted, logging every attempt and
+// keeping the payload as a dead letter once the attempts run out.
+func (d *Dispatcher) deliver(ctx context.Context, store Store, hook *types.Webhook, payload *types.WebhookPayload) {
+	payload.Timestamp = time.Now().Unix()
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("failed to encode webhook payload", "webhook_id", hook.ID, "err", err)
+		return
+	}
+
+	rows := len(payload.Pairs) + len(payload.Tokens)
+	backoff := d.backoff
+
+	var (
+		lastErr  error
+		attempts int
+	)
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		attempts = attempt
+		st := time.Now()
+		status, err := d.post(ctx, hook, body, attempt)
+
+		delivery := &types.WebhookDelivery{
+			WebhookID:  hook.ID,
+			Attempt:    attempt,
+			StatusCode: status,
+			DurationMs: time.Since(st).Milliseconds(),
+			Rows:       rows,
+			CreatedAt:  st.Unix(),
+		}
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+
+		if err := store.InsertWebhookDelivery(delivery); err != nil {
+			slog.Error("failed to log webhook delivery", "webhook_id", hook.ID, "err", err)
+		}
+
+		if err == nil {
+			return
+		}
+		lastErr = err
+
+		if attempt == d.maxAttempts {
+			break
+		}
+
+		if !sleep(ctx, backoff) {
+			break
+		}
+		backoff *= 2
+	}
+
+	slog.Warn("webhook delivery failed", "webhook_id", hook.ID, "attempts", attempts, "err", lastErr)
+
+	letter := &types.WebhookDeadLetter{
+		WebhookID: hook.ID,
+		Payload:   body,
+		Attempts:  attempts,
+		Error:     lastErr.Error(),
+		CreatedAt: time.Now().Unix(),
+	}
+
+	if err := store.InsertWebhookDeadLetter(letter); err != nil {
+		slog.Error("failed to store webhook dead letter", "webhook_id", hook.ID, "err", err)
+	}
+}
+
+// post sends one attempt, any status outside 2xx is an error.
+func (d *Dispatcher) post(ctx context.Context, hook *types.Webhook, body []byte, attempt int) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, body))
+	req.Header.Set(HeaderWebhookID, strconv.FormatInt(hook.ID, 10))
+	req.Header.Set(HeaderAttempt, strconv.Itoa(attempt))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature header of body, "sha256=" followed by the hex
+// encoded HMAC-SHA256 of body keyed with secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of body, receivers can
+// use it to authenticate deliveries.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+func (d *Dispatcher) control(network string, address string, c syscall.RawConn) error {
+	if d.allowPrivate {
+		return nil
+	}
+	return dialControl(network, address, c)
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,179 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/autoapev1/indexer/feed"
+	"github.com/autoapev1/indexer/types"
+)
+
+type memStore struct {
+	lock       sync.Mutex
+	hooks      []*types.Webhook
+	deliveries []*types.WebhookDelivery
+	letters    []*types.WebhookDeadLetter
+}
+
+func (m *memStore) GetAllWebhooks() ([]*types.Webhook, error) {
+	return m.hooks, nil
+}
+
+func (m *memStore) InsertWebhookDelivery(d *types.WebhookDelivery) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.deliveries = append(m.deliveries, d)
+	return nil
+}
+
+func (m *memStore) InsertWebhookDeadLetter(l *types.WebhookDeadLetter) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.letters = append(m.letters, l)
+	return nil
+}
+
+func pairEvents() []*feed.Event {
+	return []*feed.Event{
+		{ChainID: 1, Kind: feed.KindPair, Seq: 1, Pair: &types.Pair{PoolAddress: "0x01", Token0Address: "0xaa", Token1Address: "0xbb"}},
+		{ChainID: 1, Kind: feed.KindPair, Seq: 2, Pair: &types.Pair{PoolAddress: "0x02", Token0Address: "0xcc", Token1Address: "0xdd"}},
+		{ChainID: 1, Kind: feed.KindToken, Seq: 1, Token: &types.Token{Address: "0xaa"}},
+	}
+}
+
+func TestDispatchSigned(t *testing.T) {
+	var (
+		got       types.WebhookPayload
+		signature string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature = r.Header.Get(HeaderSignature)
+		if !Verify("secret", body, signature) {
+			t.Errorf("invalid signature %s", signature)
+		}
+		_ = json.Unmarshal(body, &got)
+	}))
+	defer srv.Close()
+
+	token0 := "0xaa"
+	store := &memStore{hooks: []*types.Webhook{{
+		ID:         7,
+		URL:        srv.URL,
+		Type:       types.WebhookPairs,
+		PairFilter: &types.PairFilter{Token0Address: &token0},
+		Secret:     "secret",
+	}}}
+
+	d := NewDispatcher(map[int64]Store{1: store}).WithAllowPrivate(true)
+	d.Dispatch(context.Background(), pairEvents())
+	d.Wait()
+
+	if got.WebhookID != 7 || len(got.Pairs) != 1 || got.Pairs[0].PoolAddress != "0x01" || len(got.Tokens) != 0 {
+		t.Fatalf("unexpected payload %+v", got)
+	}
+
+	if len(store.deliveries) != 1 || store.deliveries[0].StatusCode != http.StatusOK || store.deliveries[0].Rows != 1 {
+		t.Fatalf("unexpected deliveries %+v", store.deliveries)
+	}
+}
+
+func TestDispatchRetry(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	store := &memStore{hooks: []*types.Webhook{{ID: 1, URL: srv.URL, Type: types.WebhookPairs}}}
+
+	d := NewDispatcher(map[int64]Store{1: store}).WithAllowPrivate(true).WithBackoff(time.Millisecond).WithMaxAttempts(3)
+	d.Dispatch(context.Background(), pairEvents())
+	d.Wait()
+
+	if len(store.deliveries) != 3 || store.deliveries[2].Error != "" {
+		t.Fatalf("unexpected deliveries %+v", store.deliveries)
+	}
+
+	if len(store.letters) != 0 {
+		t.Fatalf("unexpected dead letters %+v", store.letters)
+	}
+}
+
+func TestDispatchDeadLetter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	store := &memStore{hooks: []*types.Webhook{{ID: 1, URL: srv.URL, Type: types.WebhookTokens}}}
+
+	d := NewDispatcher(map[int64]Store{1: store}).WithAllowPrivate(true).WithBackoff(time.Millisecond).WithMaxAttempts(2)
+	d.Dispatch(context.Background(), pairEvents())
+	d.Wait()
+
+	if len(store.deliveries) != 2 || store.deliveries[1].StatusCode != http.StatusInternalServerError {
+		t.Fatalf("unexpected deliveries %+v", store.deliveries)
+	}
+
+	if len(store.letters) != 1 || store.letters[0].Attempts != 2 {
+		t.Fatalf("unexpected dead letters %+v", store.letters)
+	}
+
+	var payload types.WebhookPayload
+	if err := json.Unmarshal(store.letters[0].Payload, &payload); err != nil || len(payload.Tokens) != 1 {
+		t.Fatalf("unexpected dead letter payload %s", store.letters[0].Payload)
+	}
+}
+
+func TestDispatchRefusesPrivate(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer srv.Close()
+
+	store := &memStore{hooks: []*types.Webhook{{ID: 1, URL: srv.URL, Type: types.WebhookPairs}}}
+
+	d := NewDispatcher(map[int64]Store{1: store}).WithMaxAttempts(1)
+	d.Dispatch(context.Background(), pairEvents())
+	d.Wait()
+
+	if calls != 0 {
+		t.Fatalf("delivered to a loopback address")
+	}
+
+	if len(store.deliveries) != 1 || !strings.Contains(store.deliveries[0].Error, ErrForbiddenTarget.Error()) {
+		t.Fatalf("unexpected deliveries %+v", store.deliveries)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	for _, u := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://10.1.2.3/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		if err := CheckURL(context.Background(), u); err != ErrForbiddenTarget {
+			t.Errorf("%s: expected ErrForbiddenTarget, got %v", u, err)
+		}
+	}
+
+	if err := CheckURL(context.Background(), "http://8.8.8.8/hook"); err != nil {
+		t.Errorf("public address refused: %v", err)
+	}
+}
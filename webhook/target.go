@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"syscall"
+)
+
+var ErrForbiddenTarget = errors.New("webhook url must not resolve to a loopback, private or link-local address")
+
+// blockedNets are the ranges refused on top of the loopback, private,
+// link-local, multicast and unspecified addresses.
+var blockedNets = mustParseCIDRs(
+	"0.0.0.0/8",     // this network
+	"100.64.0.0/10", // carrier grade nat, also used by cloud providers
+	"192.0.0.0/24",  // ietf protocol assignments
+	"198.18.0.0/15", // benchmarking
+	"64:ff9b::/96",  // nat64, can map to any ipv4 address
+)
+
+// CheckURL resolves the host of a webhook url and returns ErrForbiddenTarget
+// if any of its addresses is not a public address. Deliveries check the
+// address again when they dial, so a host rebound after the check is refused.
+func CheckURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return err
+	}
+
+	for _, ip := range ips {
+		if !publicIP(ip.IP) {
+			return ErrForbiddenTarget
+		}
+	}
+
+	return nil
+}
+
+// publicIP reports whether deliveries may connect to ip.
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+
+	for _, n := range blockedNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dialControl refuses connections to addresses that are not public, it runs
+// on the resolved address of every dial, redirects included.
+func dialControl(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !publicIP(ip) {
+		return ErrForbiddenTarget
+	}
+
+	return nil
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}